@@ -27,9 +27,11 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const basefeeWiggleMultiplier = 2
@@ -37,6 +39,7 @@ const basefeeWiggleMultiplier = 2
 var (
 	errNoEventSignature       = errors.New("no event signature")
 	errEventSignatureMismatch = errors.New("event signature mismatch")
+	errNoErrorSelector        = errors.New("no error selector")
 )
 
 // SignerFn is a signer function callback when a contract requires a method to
@@ -554,6 +557,51 @@ func (c *BoundContract) UnpackLogIntoMap(out map[string]interface{}, event strin
 	return abi.ParseTopicsIntoMap(out, indexed, log.Topics[1:])
 }
 
+// UnpackError unpacks the revert data of a failed call into the custom error of
+// the contract it matches, returning the error definition and its arguments.
+func (c *BoundContract) UnpackError(data []byte) (*abi.Error, []interface{}, error) {
+	if len(data) < 4 {
+		return nil, nil, errNoErrorSelector
+	}
+	abiErr, err := c.abi.ErrorByID([4]byte(data[:4]))
+	if err != nil {
+		return nil, nil, err
+	}
+	args, err := abiErr.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return abiErr, args, nil
+}
+
+// RevertData extracts the raw revert data carried by an error returned from a
+// contract call or gas estimation, if any.
+func RevertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		blob, err := hexutil.Decode(data)
+		return blob, err == nil
+	case []byte:
+		return data, true
+	default:
+		return nil, false
+	}
+}
+
+// FormatError formats a custom error raised by a contract as its name followed by
+// its arguments, e.g. InsufficientBalance(10, 20).
+func FormatError(name string, args ...interface{}) string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = fmt.Sprintf("%v", arg)
+	}
+	return name + "(" + strings.Join(formatted, ", ") + ")"
+}
+
 // ensureContext is a helper method to ensure a context is not nil, even if the
 // user specified it as such.
 func ensureContext(ctx context.Context) context.Context {
@@ -19,6 +19,7 @@ package bind_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"reflect"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -587,3 +589,109 @@ func TestCrashers(t *testing.T) {
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"----"}]}]}]`))
 	abi.JSON(strings.NewReader(`[{"inputs":[{"type":"tuple[]","components":[{"type":"bool","name":"foo.Bar"}]}]}]`))
 }
+
+// revertErr is a mock of the JSON-RPC error returned for reverted calls.
+type revertErr struct{ data string }
+
+func (e *revertErr) Error() string          { return "execution reverted" }
+func (e *revertErr) ErrorData() interface{} { return e.data }
+
+func TestUnpackError(t *testing.T) {
+	t.Parallel()
+	const abiJSON = `[{"inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"}]`
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := parsed.Errors["InsufficientBalance"].Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(parsed.Errors["InsufficientBalance"].ID.Bytes()[:4], data...)
+
+	blob, ok := bind.RevertData(fmt.Errorf("call failed: %w", &revertErr{data: hexutil.Encode(data)}))
+	if !ok {
+		t.Fatal("failed to extract revert data from wrapped error")
+	}
+	if _, ok := bind.RevertData(errors.New("plain error")); ok {
+		t.Fatal("extracted revert data from plain error")
+	}
+	bc := bind.NewBoundContract(common.Address{}, parsed, nil, nil, nil)
+	abiErr, args, err := bc.UnpackError(blob)
+	if err != nil {
+		t.Fatalf("failed to unpack error: %v", err)
+	}
+	if abiErr.Name != "InsufficientBalance" {
+		t.Errorf("error name mismatch: have %s, want InsufficientBalance", abiErr.Name)
+	}
+	if args[0].(*big.Int).Uint64() != 1 || args[1].(*big.Int).Uint64() != 2 {
+		t.Errorf("error arguments mismatch: have %v", args)
+	}
+	if _, _, err := bc.UnpackError([]byte{0xde, 0xad}); err == nil {
+		t.Error("unpacked error from short data")
+	}
+}
+
+// mockFilterer records the log queries issued by a bound contract.
+type mockFilterer struct {
+	queries []ethereum.FilterQuery
+}
+
+func (mf *mockFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	mf.queries = append(mf.queries, query)
+	return nil, nil
+}
+
+func (mf *mockFilterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	mf.queries = append(mf.queries, query)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	}), nil
+}
+
+// Tests that filtering and watching for events accepts multiple values for each
+// indexed argument, matching any of them, as used by the generated FilterX and
+// WatchX methods.
+func TestFilterLogsMultipleValues(t *testing.T) {
+	t.Parallel()
+	const abiJSON = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"id","type":"uint256"}],"name":"Transfer","type":"event"}]`
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		filterer = new(mockFilterer)
+		bc       = bind.NewBoundContract(common.Address{0xaa}, parsed, nil, nil, filterer)
+
+		from = []interface{}{common.Address{1}, common.Address{2}}
+		id   = []interface{}{big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	)
+	if _, sub, err := bc.FilterLogs(nil, "Transfer", from, nil, id); err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	} else {
+		sub.Unsubscribe()
+	}
+	if _, sub, err := bc.WatchLogs(nil, "Transfer", from, nil, id); err != nil {
+		t.Fatalf("failed to watch logs: %v", err)
+	} else {
+		sub.Unsubscribe()
+	}
+	want := [][]common.Hash{
+		{parsed.Events["Transfer"].ID},
+		{common.BytesToHash(common.Address{1}.Bytes()), common.BytesToHash(common.Address{2}.Bytes())},
+		nil,
+		{common.BigToHash(big.NewInt(3)), common.BigToHash(big.NewInt(4)), common.BigToHash(big.NewInt(5))},
+	}
+	if len(filterer.queries) != 2 {
+		t.Fatalf("query count mismatch: have %d, want 2", len(filterer.queries))
+	}
+	for i, query := range filterer.queries {
+		if !reflect.DeepEqual(query.Topics, want) {
+			t.Errorf("query %d: topics mismatch:\nhave %v\nwant %v", i, query.Topics, want)
+		}
+		if len(query.Addresses) != 1 || query.Addresses[0] != (common.Address{0xaa}) {
+			t.Errorf("query %d: addresses mismatch: have %v", i, query.Addresses)
+		}
+	}
+}
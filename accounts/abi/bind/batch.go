@@ -0,0 +1,304 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3Address is the address the Multicall3 aggregator contract is deployed
+// at on most EVM chains, including all OP-stack chains as a predeploy.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicall3ABI is the subset of the Multicall3 ABI used for batching calls.
+var multicall3ABI, _ = abi.JSON(strings.NewReader(`[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`))
+
+// errBatchNotExecuted is returned for the results of calls whose batch has not
+// been executed yet.
+var errBatchNotExecuted = errors.New("batch not executed")
+
+// multicall3Call is a single call of a Multicall3 aggregate3 invocation.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the outcome of a single call of a Multicall3 aggregate3
+// invocation.
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// revertError is the error reported for a call of a Multicall3 batch that was
+// reverted. It carries the revert data like the JSON-RPC errors of eth_call do,
+// so RevertData and the generated UnpackError helpers work on it.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string {
+	if reason, err := abi.UnpackRevert(e.data); err == nil {
+		return "execution reverted: " + reason
+	}
+	return "execution reverted"
+}
+
+func (e *revertError) ErrorData() interface{} {
+	return hexutil.Encode(e.data)
+}
+
+// BatchCall is a contract call queued in a Batch. Its results are available once
+// the batch has been executed.
+type BatchCall struct {
+	contract *BoundContract
+	method   string
+	input    []byte
+
+	output []interface{}
+	err    error
+}
+
+// Results returns the unpacked outputs of the call, or the error it failed with.
+func (c *BatchCall) Results() ([]interface{}, error) {
+	return c.output, c.err
+}
+
+// BatchResult is a typed handle on the results of a call queued in a Batch, used
+// by the generated bindings to convert the outputs into their Go types.
+type BatchResult[T any] struct {
+	call   *BatchCall
+	unpack func([]interface{}) T
+}
+
+// NewBatchResult creates a typed handle on the results of a queued call, which
+// are converted with unpack once the batch has been executed successfully.
+func NewBatchResult[T any](call *BatchCall, unpack func([]interface{}) T) *BatchResult[T] {
+	return &BatchResult[T]{call: call, unpack: unpack}
+}
+
+// Result returns the converted outputs of the call, or the error it failed with.
+func (r *BatchResult[T]) Result() (T, error) {
+	out, err := r.call.Results()
+	if err != nil {
+		return *new(T), err
+	}
+	return r.unpack(out), nil
+}
+
+// Batch collects read-only calls across any number of bound contracts and
+// executes them together, either as a single JSON-RPC batch or as one on-chain
+// Multicall3 aggregate call. All calls of a batch are executed against the same
+// block.
+type Batch struct {
+	calls []*BatchCall
+}
+
+// NewBatch creates an empty call batch.
+func NewBatch() *Batch {
+	return new(Batch)
+}
+
+// Add queues a call of a contract method into the batch. Errors packing the
+// input are reported through the results of the returned call.
+func (b *Batch) Add(contract *BoundContract, method string, params ...interface{}) *BatchCall {
+	call := &BatchCall{contract: contract, method: method}
+	if input, err := contract.abi.Pack(method, params...); err != nil {
+		call.err = err
+	} else {
+		call.input, call.err = input, errBatchNotExecuted
+	}
+	b.calls = append(b.calls, call)
+	return call
+}
+
+// Len returns the number of calls queued in the batch.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// pending returns the calls of the batch which are ready to be executed.
+func (b *Batch) pending() []*BatchCall {
+	var calls []*BatchCall
+	for _, call := range b.calls {
+		if call.err == errBatchNotExecuted {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// finish unpacks the output of an executed call.
+func (c *BatchCall) finish(output []byte) {
+	if len(output) == 0 && len(c.contract.abi.Methods[c.method].Outputs) > 0 {
+		c.output, c.err = nil, ErrNoCode
+		return
+	}
+	c.output, c.err = c.contract.abi.Unpack(c.method, output)
+}
+
+// CallRPC executes the queued calls as a single JSON-RPC batch of eth_call
+// requests. Unless the call options select the pending state or a specific
+// block, the calls are pinned to the latest block number at the time of the
+// invocation. Failures of individual calls are reported through their results,
+// the returned error is only set if the batch could not be executed at all.
+func (b *Batch) CallRPC(opts *CallOpts, client *rpc.Client) error {
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	calls := b.pending()
+	if len(calls) == 0 {
+		return nil
+	}
+	ctx := ensureContext(opts.Context)
+
+	var block interface{}
+	switch {
+	case opts.Pending:
+		block = "pending"
+	case opts.BlockHash != (common.Hash{}):
+		block = rpc.BlockNumberOrHashWithHash(opts.BlockHash, false)
+	case opts.BlockNumber != nil:
+		block = toBlockNumArg(opts.BlockNumber)
+	default:
+		var head hexutil.Uint64
+		if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+			return err
+		}
+		block = head
+	}
+	var (
+		elems   = make([]rpc.BatchElem, len(calls))
+		outputs = make([]hexutil.Bytes, len(calls))
+	)
+	for i, call := range calls {
+		arg := map[string]interface{}{
+			"to":    call.contract.address,
+			"input": hexutil.Bytes(call.input),
+		}
+		if opts.From != (common.Address{}) {
+			arg["from"] = opts.From
+		}
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{arg, block},
+			Result: &outputs[i],
+		}
+	}
+	if err := client.BatchCallContext(ctx, elems); err != nil {
+		return err
+	}
+	for i, call := range calls {
+		if elems[i].Error != nil {
+			call.err = elems[i].Error
+			continue
+		}
+		call.finish(outputs[i])
+	}
+	return nil
+}
+
+// CallMulticall executes the queued calls as a single aggregate3 call of the
+// Multicall3 contract deployed at the given address, against the state selected
+// by the call options. Since all calls are part of a single eth_call, they are
+// guaranteed to observe the same state. Failures of individual calls are
+// reported through their results, the returned error is only set if the batch
+// could not be executed at all.
+func (b *Batch) CallMulticall(opts *CallOpts, caller ContractCaller, multicall common.Address) error {
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	var (
+		calls   = b.pending()
+		batched = make([]multicall3Call, len(calls))
+	)
+	if len(calls) == 0 {
+		return nil
+	}
+	for i, call := range calls {
+		batched[i] = multicall3Call{Target: call.contract.address, AllowFailure: true, CallData: call.input}
+	}
+	input, err := multicall3ABI.Pack("aggregate3", batched)
+	if err != nil {
+		return err
+	}
+	var (
+		msg    = ethereum.CallMsg{From: opts.From, To: &multicall, Data: input}
+		ctx    = ensureContext(opts.Context)
+		output []byte
+	)
+	switch {
+	case opts.Pending:
+		pb, ok := caller.(PendingContractCaller)
+		if !ok {
+			return ErrNoPendingState
+		}
+		output, err = pb.PendingCallContract(ctx, msg)
+	case opts.BlockHash != (common.Hash{}):
+		bh, ok := caller.(BlockHashContractCaller)
+		if !ok {
+			return ErrNoBlockHashState
+		}
+		output, err = bh.CallContractAtHash(ctx, msg, opts.BlockHash)
+	default:
+		output, err = caller.CallContract(ctx, msg, opts.BlockNumber)
+	}
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		return ErrNoCode
+	}
+	unpacked, err := multicall3ABI.Unpack("aggregate3", output)
+	if err != nil {
+		return err
+	}
+	results := *abi.ConvertType(unpacked[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(results) != len(calls) {
+		return errors.New("multicall result count mismatch")
+	}
+	for i, call := range calls {
+		if !results[i].Success {
+			call.err = &revertError{data: results[i].ReturnData}
+			continue
+		}
+		call.finish(results[i].ReturnData)
+	}
+	return nil
+}
+
+// toBlockNumArg converts a block number into its JSON-RPC representation.
+func toBlockNumArg(number *big.Int) string {
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	// It's negative.
+	if number.IsInt64() {
+		return rpc.BlockNumber(number.Int64()).String()
+	}
+	// It's negative and large, which is invalid.
+	return "<invalid " + number.String() + ">"
+}
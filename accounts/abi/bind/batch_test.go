@@ -0,0 +1,205 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	doublerABI    = `[{"inputs":[{"name":"x","type":"uint256"}],"name":"double","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	multicall3ABI = `[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+)
+
+// doubler emulates a contract doubling its input, reverting on zero.
+func doubler(t *testing.T, input []byte) ([]byte, []byte) {
+	parsed, _ := abi.JSON(strings.NewReader(doublerABI))
+	args, err := parsed.Methods["double"].Inputs.Unpack(input[4:])
+	if err != nil {
+		t.Fatalf("invalid call input: %v", err)
+	}
+	x := args[0].(*big.Int)
+	if x.Sign() == 0 {
+		// Error("zero")
+		reason, _ := abi.Arguments{{Type: abi.Type{T: abi.StringTy}}}.Pack("zero")
+		return nil, append(common.FromHex("0x08c379a0"), reason...)
+	}
+	out, _ := parsed.Methods["double"].Outputs.Pack(new(big.Int).Lsh(x, 1))
+	return out, nil
+}
+
+// batchRevertError is the JSON-RPC error of a reverted eth_call.
+type batchRevertError struct{ data []byte }
+
+func (e *batchRevertError) Error() string          { return "execution reverted" }
+func (e *batchRevertError) ErrorCode() int         { return 3 }
+func (e *batchRevertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// batchTestService is a mock eth namespace serving calls to a doubler contract.
+type batchTestService struct {
+	t      *testing.T
+	blocks []string
+}
+
+func (s *batchTestService) BlockNumber() hexutil.Uint64 { return 42 }
+
+func (s *batchTestService) Call(args struct{ Input hexutil.Bytes }, block string) (hexutil.Bytes, error) {
+	s.blocks = append(s.blocks, block)
+	out, revert := doubler(s.t, args.Input)
+	if revert != nil {
+		return nil, &batchRevertError{data: revert}
+	}
+	return out, nil
+}
+
+// multicallCaller is a mock contract caller emulating Multicall3 aggregating
+// calls to doubler contracts.
+type multicallCaller struct {
+	t     *testing.T
+	calls int
+}
+
+func (c *multicallCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *multicallCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls++
+	if *call.To != bind.Multicall3Address {
+		c.t.Fatalf("unexpected call target %v", call.To)
+	}
+	parsed, _ := abi.JSON(strings.NewReader(multicall3ABI))
+	args, err := parsed.Methods["aggregate3"].Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		c.t.Fatalf("invalid aggregate3 input: %v", err)
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	type subcall struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	var results []result
+	for _, sub := range *abi.ConvertType(args[0], new([]subcall)).(*[]subcall) {
+		out, revert := doubler(c.t, sub.CallData)
+		if revert != nil {
+			results = append(results, result{false, revert})
+		} else {
+			results = append(results, result{true, out})
+		}
+	}
+	return parsed.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+// Tests that batched calls are executed both as JSON-RPC batches pinned to a
+// single block and as Multicall3 aggregates, with per-call failures reported
+// through the individual results.
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	parsed, _ := abi.JSON(strings.NewReader(doublerABI))
+	var (
+		first  = bind.NewBoundContract(common.Address{1}, parsed, nil, nil, nil)
+		second = bind.NewBoundContract(common.Address{2}, parsed, nil, nil, nil)
+	)
+	check := func(t *testing.T, batch *bind.Batch, calls []*bind.BatchCall) {
+		for i, want := range []int64{2, 10} {
+			out, err := calls[i].Results()
+			if err != nil {
+				t.Fatalf("call %d failed: %v", i, err)
+			}
+			if out[0].(*big.Int).Int64() != want {
+				t.Errorf("call %d: result mismatch: have %v, want %d", i, out[0], want)
+			}
+		}
+		_, err := calls[2].Results()
+		if data, ok := bind.RevertData(err); !ok {
+			t.Errorf("reverted call: missing revert data: %v", err)
+		} else if reason, _ := abi.UnpackRevert(data); reason != "zero" {
+			t.Errorf("reverted call: reason mismatch: have %q, want %q", reason, "zero")
+		}
+		if _, err := calls[3].Results(); err == nil {
+			t.Errorf("invalid call: expected packing failure")
+		}
+		if n := batch.Len(); n != 4 {
+			t.Errorf("batch length mismatch: have %d, want 4", n)
+		}
+	}
+	fill := func() (*bind.Batch, []*bind.BatchCall) {
+		batch := bind.NewBatch()
+		return batch, []*bind.BatchCall{
+			batch.Add(first, "double", big.NewInt(1)),
+			batch.Add(second, "double", big.NewInt(5)),
+			batch.Add(first, "double", big.NewInt(0)),
+			batch.Add(second, "double", "not a number"),
+		}
+	}
+	t.Run("rpc", func(t *testing.T) {
+		service := &batchTestService{t: t}
+		server := rpc.NewServer()
+		defer server.Stop()
+		if err := server.RegisterName("eth", service); err != nil {
+			t.Fatal(err)
+		}
+		client := rpc.DialInProc(server)
+		defer client.Close()
+
+		batch, calls := fill()
+		if err := batch.CallRPC(nil, client); err != nil {
+			t.Fatalf("failed to execute batch: %v", err)
+		}
+		check(t, batch, calls)
+		for i, block := range service.blocks {
+			if block != "0x2a" {
+				t.Errorf("call %d: not pinned to the head block: have %s", i, block)
+			}
+		}
+	})
+	t.Run("multicall", func(t *testing.T) {
+		caller := &multicallCaller{t: t}
+
+		batch, calls := fill()
+		if err := batch.CallMulticall(nil, caller, bind.Multicall3Address); err != nil {
+			t.Fatalf("failed to execute batch: %v", err)
+		}
+		check(t, batch, calls)
+		if caller.calls != 1 {
+			t.Errorf("eth_call count mismatch: have %d, want 1", caller.calls)
+		}
+		// Typed results convert the outputs once available
+		result := bind.NewBatchResult(calls[1], func(out []interface{}) *big.Int { return out[0].(*big.Int) })
+		if x, err := result.Result(); err != nil || x.Int64() != 10 {
+			t.Errorf("typed result mismatch: have %v, %v", x, err)
+		}
+		if _, err := bind.NewBatchResult(calls[2], func(out []interface{}) *big.Int { return nil }).Result(); err == nil {
+			t.Errorf("typed result of reverted call succeeded")
+		}
+	})
+}
@@ -14,7 +14,8 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package bind generates Ethereum contract Go bindings.
+// Package bind generates Ethereum contract Go bindings, along with TypeScript
+// (ethers v6) and Python (web3.py) wrappers of the same contracts.
 //
 // Detailed usage document and tutorial available on the go-ethereum Wiki page:
 // https://github.com/ethereum/go-ethereum/wiki/Native-DApps:-Go-bindings-to-Ethereum-contracts
@@ -38,9 +39,40 @@ type Lang int
 
 const (
 	LangGo Lang = iota
+	LangTS
+	LangPy
 )
 
-func isKeyWord(arg string) bool {
+// reservedWords contains the identifiers of the non-Go target languages which
+// cannot be used as parameter names.
+var reservedWords = map[Lang]map[string]bool{
+	LangTS: {
+		"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+		"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+		"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+		"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+		"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+		"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+		"let": true, "static": true, "yield": true, "await": true, "overrides": true,
+	},
+	LangPy: {
+		"and": true, "as": true, "assert": true,
+		"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+		"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+		"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+		"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+		"return": true, "try": true, "while": true, "with": true, "yield": true, "self": true,
+		"transaction": true, "block_identifier": true, "from_block": true, "to_block": true,
+	},
+}
+
+func isKeyWord(lang Lang, arg string) bool {
+	if lang == LangPy {
+		arg = toSnakeCase(arg) // Python parameters are snake cased
+	}
+	if lang != LangGo {
+		return reservedWords[lang][arg]
+	}
 	switch arg {
 	case "break":
 	case "case":
@@ -77,6 +109,20 @@ func isKeyWord(arg string) bool {
 	return true
 }
 
+// argName returns the name of the index'th argument in the bindings of the given
+// language. Unnamed arguments are named after their position. Reserved words are
+// replaced the same way in Go, and suffixed with an underscore in the others.
+func argName(lang Lang, name string, index int) string {
+	switch {
+	case name == "" || (lang == LangGo && isKeyWord(lang, name)):
+		return fmt.Sprintf("arg%d", index)
+	case isKeyWord(lang, name):
+		return name + "_"
+	default:
+		return name
+	}
+}
+
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
 // to be used as is in client code, but rather as an intermediate struct which
 // enforces compile time type safety and naming convention as opposed to having to
@@ -121,9 +167,22 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			callIdentifiers     = make(map[string]bool)
 			transactIdentifiers = make(map[string]bool)
 			eventIdentifiers    = make(map[string]bool)
+			errorIdentifiers    = make(map[string]bool)
+
+			errors = make(map[string]*tmplError)
 		)
 
-		for _, input := range evmABI.Constructor.Inputs {
+		// The Go bindings use the constructor inputs as they are, the others need
+		// them to be valid parameter names
+		constructor := evmABI.Constructor
+		if lang != LangGo {
+			constructor.Inputs = make([]abi.Argument, len(evmABI.Constructor.Inputs))
+			copy(constructor.Inputs, evmABI.Constructor.Inputs)
+			for j, input := range constructor.Inputs {
+				constructor.Inputs[j].Name = argName(lang, input.Name, j)
+			}
+		}
+		for _, input := range constructor.Inputs {
 			if hasStruct(input.Type) {
 				bindStructType[lang](input.Type, structs)
 			}
@@ -155,9 +214,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
-					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
-				}
+				normalized.Inputs[j].Name = argName(lang, input.Name, j)
 				if hasStruct(input.Type) {
 					bindStructType[lang](input.Type, structs)
 				}
@@ -207,9 +264,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
 			copy(normalized.Inputs, original.Inputs)
 			for j, input := range normalized.Inputs {
-				if input.Name == "" || isKeyWord(input.Name) {
-					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
-				}
+				normalized.Inputs[j].Name = argName(lang, input.Name, j)
 				// Event is a bit special, we need to define event struct in binding,
 				// ensure there is no camel-case-style name conflict.
 				for index := 0; ; index++ {
@@ -226,6 +281,30 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		for _, original := range evmABI.Errors {
+			// Normalize the error for capital cases and non-anonymous inputs
+			normalized := original
+
+			normalizedName := abi.ToCamelCase(alias(aliases, original.Name))
+			if len(normalizedName) > 0 && unicode.IsDigit(rune(normalizedName[0])) {
+				normalizedName = fmt.Sprintf("E%s", normalizedName)
+			}
+			if errorIdentifiers[normalizedName] {
+				return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+			}
+			errorIdentifiers[normalizedName] = true
+			normalized.Name = normalizedName
+
+			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
+			copy(normalized.Inputs, original.Inputs)
+			for j, input := range normalized.Inputs {
+				normalized.Inputs[j].Name = argName(lang, input.Name, j)
+				if hasStruct(input.Type) {
+					bindStructType[lang](input.Type, structs)
+				}
+			}
+			errors[original.Name] = &tmplError{Original: original, Normalized: normalized}
+		}
 		// Add two special fallback functions if they exist
 		if evmABI.HasFallback() {
 			fallback = &tmplMethod{Original: evmABI.Fallback}
@@ -237,12 +316,13 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			Type:        capitalise(types[i]),
 			InputABI:    strings.ReplaceAll(strippedABI, "\"", "\\\""),
 			InputBin:    strings.TrimPrefix(strings.TrimSpace(bytecodes[i]), "0x"),
-			Constructor: evmABI.Constructor,
+			Constructor: constructor,
 			Calls:       calls,
 			Transacts:   transacts,
 			Fallback:    fallback,
 			Receive:     receive,
 			Events:      events,
+			Errors:      errors,
 			Libraries:   make(map[string]string),
 		}
 		// Function 4-byte signatures are stored in the same sequence
@@ -285,6 +365,11 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
+		"camelcase":     abi.ToCamelCase,
+		"snakecase":     toSnakeCase,
+		"hashedtopic":   hashedTopic,
+		"inc":           func(i int) int { return i + 1 },
+		"pydecode":      func(kind abi.Type, expr string) string { return decodePy(kind, expr, structs, 0) },
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
@@ -306,6 +391,8 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo: bindTypeGo,
+	LangTS: bindTypeTS,
+	LangPy: bindTypePy,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -352,6 +439,8 @@ func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo: bindTopicTypeGo,
+	LangTS: bindTopicTypeTS,
+	LangPy: bindTopicTypePy,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -375,6 +464,8 @@ func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo: bindStructTypeGo,
+	LangTS: bindStructTypeTS,
+	LangPy: bindStructTypePy,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -383,37 +474,7 @@ var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct
 func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 	switch kind.T {
 	case abi.TupleTy:
-		// We compose a raw struct name and a canonical parameter expression
-		// together here. The reason is before solidity v0.5.11, kind.TupleRawName
-		// is empty, so we use canonical parameter expression to distinguish
-		// different struct definition. From the consideration of backward
-		// compatibility, we concat these two together so that if kind.TupleRawName
-		// is not empty, it can have unique id.
-		id := kind.TupleRawName + kind.String()
-		if s, exist := structs[id]; exist {
-			return s.Name
-		}
-		var (
-			names  = make(map[string]bool)
-			fields []*tmplField
-		)
-		for i, elem := range kind.TupleElems {
-			name := capitalise(kind.TupleRawNames[i])
-			name = abi.ResolveNameConflict(name, func(s string) bool { return names[s] })
-			names[name] = true
-			fields = append(fields, &tmplField{Type: bindStructTypeGo(*elem, structs), Name: name, SolKind: *elem})
-		}
-		name := kind.TupleRawName
-		if name == "" {
-			name = fmt.Sprintf("Struct%d", len(structs))
-		}
-		name = capitalise(name)
-
-		structs[id] = &tmplStruct{
-			Name:   name,
-			Fields: fields,
-		}
-		return name
+		return recordStruct(kind, structs, bindStructTypeGo)
 	case abi.ArrayTy:
 		return fmt.Sprintf("[%d]", kind.Size) + bindStructTypeGo(*kind.Elem, structs)
 	case abi.SliceTy:
@@ -423,10 +484,179 @@ func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// recordStruct records the struct definition of a Solidity tuple type in the given
+// map, binding the field types with the language specific struct binder. Nested
+// structs are resolved and recorded recursively.
+func recordStruct(kind abi.Type, structs map[string]*tmplStruct, bind func(abi.Type, map[string]*tmplStruct) string) string {
+	// We compose a raw struct name and a canonical parameter expression
+	// together here. The reason is before solidity v0.5.11, kind.TupleRawName
+	// is empty, so we use canonical parameter expression to distinguish
+	// different struct definition. From the consideration of backward
+	// compatibility, we concat these two together so that if kind.TupleRawName
+	// is not empty, it can have unique id.
+	id := kind.TupleRawName + kind.String()
+	if s, exist := structs[id]; exist {
+		return s.Name
+	}
+	var (
+		names  = make(map[string]bool)
+		fields []*tmplField
+	)
+	for i, elem := range kind.TupleElems {
+		name := capitalise(kind.TupleRawNames[i])
+		name = abi.ResolveNameConflict(name, func(s string) bool { return names[s] })
+		names[name] = true
+
+		raw := kind.TupleRawNames[i]
+		if raw == "" {
+			raw = decapitalise(name)
+		}
+		fields = append(fields, &tmplField{Type: bind(*elem, structs), Name: name, RawName: raw, SolKind: *elem})
+	}
+	name := kind.TupleRawName
+	if name == "" {
+		name = fmt.Sprintf("Struct%d", len(structs))
+	}
+	name = capitalise(name)
+
+	structs[id] = &tmplStruct{
+		Name:   name,
+		Fields: fields,
+	}
+	return name
+}
+
+// hashedTopic returns whether an indexed event parameter of the given type is
+// stored in the log topics as its hash rather than its value.
+func hashedTopic(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
+
+// bindBasicTypeTS converts basic solidity types(except array, slice and tuple) to
+// the TypeScript types used by ethers v6.
+func bindBasicTypeTS(kind abi.Type) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		return "bigint"
+	case abi.BoolTy:
+		return "boolean"
+	default:
+		// address, string, bytes and function types are all hex or text strings
+		return "string"
+	}
+}
+
+// bindTypeTS converts solidity types to TypeScript ones.
+func bindTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy, abi.SliceTy:
+		return bindTypeTS(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTS(kind)
+	}
+}
+
+// bindTopicTypeTS converts a Solidity topic type to a TypeScript one. Indexed
+// parameters of dynamic types are only available as their hash.
+func bindTopicTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	if hashedTopic(kind) {
+		return "Indexed"
+	}
+	return bindTypeTS(kind, structs)
+}
+
+// bindStructTypeTS converts a Solidity tuple type to a TypeScript interface and
+// records the mapping in the given map.
+func bindStructTypeTS(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return recordStruct(kind, structs, bindStructTypeTS)
+	case abi.ArrayTy, abi.SliceTy:
+		return bindStructTypeTS(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTS(kind)
+	}
+}
+
+// bindBasicTypePy converts basic solidity types(except array, slice and tuple) to
+// the Python types used by web3.py.
+func bindBasicTypePy(kind abi.Type) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		return "int"
+	case abi.BoolTy:
+		return "bool"
+	case abi.FixedBytesTy, abi.BytesTy, abi.FunctionTy:
+		return "bytes"
+	default:
+		// address and string types
+		return "str"
+	}
+}
+
+// bindTypePy converts solidity types to Python ones.
+func bindTypePy(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy, abi.SliceTy:
+		return "list[" + bindTypePy(*kind.Elem, structs) + "]"
+	default:
+		return bindBasicTypePy(kind)
+	}
+}
+
+// bindTopicTypePy converts a Solidity topic type to a Python one. Indexed
+// parameters of dynamic types are only available as their hash.
+func bindTopicTypePy(kind abi.Type, structs map[string]*tmplStruct) string {
+	if hashedTopic(kind) {
+		return "bytes"
+	}
+	return bindTypePy(kind, structs)
+}
+
+// bindStructTypePy converts a Solidity tuple type to a Python named tuple and
+// records the mapping in the given map.
+func bindStructTypePy(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return recordStruct(kind, structs, bindStructTypePy)
+	case abi.ArrayTy, abi.SliceTy:
+		return "list[" + bindStructTypePy(*kind.Elem, structs) + "]"
+	default:
+		return bindBasicTypePy(kind)
+	}
+}
+
+// decodePy returns a Python expression converting the raw web3.py value held
+// in expr into the bound type, turning plain tuples into the generated named
+// tuples, recursively.
+func decodePy(kind abi.Type, expr string, structs map[string]*tmplStruct, depth int) string {
+	if !hasStruct(kind) {
+		return expr
+	}
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name + ".from_abi(" + expr + ")"
+	default:
+		item := fmt.Sprintf("x%d", depth)
+		return "[" + decodePy(*kind.Elem, item, structs, depth+1) + " for " + item + " in " + expr + "]"
+	}
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
 	LangGo: func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangTS: func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangPy: func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // alias returns an alias of the given string based on the aliasing rules
@@ -442,6 +672,8 @@ func alias(aliases map[string]string, n string) string {
 // conform to target language naming conventions.
 var methodNormalizer = map[Lang]func(string) string{
 	LangGo: abi.ToCamelCase,
+	LangTS: decapitalise,
+	LangPy: toSnakeCase,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
@@ -457,6 +689,25 @@ func decapitalise(input string) string {
 	return strings.ToLower(goForm[:1]) + goForm[1:]
 }
 
+// toSnakeCase converts a camel-case or underscore separated identifier into the
+// lower case, underscore separated form used by Python.
+func toSnakeCase(input string) string {
+	var (
+		out   strings.Builder
+		runes = []rune(input)
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				out.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
 // structured checks whether a list of ABI data types has enough information to
 // operate through a proper Go struct or if flat returns are needed.
 func structured(args abi.Arguments) bool {
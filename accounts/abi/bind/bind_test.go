@@ -17,6 +17,7 @@
 package bind
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -1877,6 +1878,7 @@ var bindTests = []struct {
 		[]string{`[{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError","type":"error"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError1","type":"error"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"uint256","name":"","type":"uint256"}],"name":"MyError2","type":"error"},{"inputs":[{"internalType":"uint256","name":"a","type":"uint256"},{"internalType":"uint256","name":"b","type":"uint256"},{"internalType":"uint256","name":"c","type":"uint256"}],"name":"MyError3","type":"error"},{"inputs":[],"name":"Error","outputs":[],"stateMutability":"pure","type":"function"}]`},
 		`
 			"context"
+			"errors"
 			"math/big"
 	
 			"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -1902,11 +1904,40 @@ var bindTests = []struct {
 			if err != nil {
 				t.Error(err)
 			}
-			if err := contract.Error(new(bind.CallOpts)); err == nil {
+			err = contract.Error(new(bind.CallOpts))
+			if err == nil {
 				t.Fatalf("expected contract to throw error")
 			}
-			// TODO (MariusVanDerWijden unpack error using abigen
-			// once that is implemented
+			unpacked, ok := contract.UnpackError(err)
+			if !ok {
+				t.Fatalf("failed to unpack error: %v", err)
+			}
+			myErr, ok := unpacked.(*NewErrorsMyError3Error)
+			if !ok {
+				t.Fatalf("unexpected error type: %T", unpacked)
+			}
+			if myErr.A.Uint64() != 1 || myErr.B.Uint64() != 2 || myErr.C.Uint64() != 3 {
+				t.Fatalf("error arguments mismatch: have %v %v %v, want 1 2 3", myErr.A, myErr.B, myErr.C)
+			}
+			if myErr.ErrorID() != [4]byte{0x92, 0x1d, 0xb3, 0x40} {
+				t.Fatalf("error selector mismatch: have %x", myErr.ErrorID())
+			}
+			var target *NewErrorsMyError3Error
+			if !errors.As(err, &target) {
+				t.Fatalf("reverted call error is not a custom error: %T", err)
+			}
+			if target.A.Uint64() != 1 || target.B.Uint64() != 2 || target.C.Uint64() != 3 {
+				t.Fatalf("wrapped error arguments mismatch: have %v %v %v, want 1 2 3", target.A, target.B, target.C)
+			}
+			if have, want := target.Error(), "MyError3(1, 2, 3)"; have != want {
+				t.Fatalf("error message mismatch: have %q, want %q", have, want)
+			}
+			if _, ok := bind.RevertData(err); !ok {
+				t.Fatalf("revert data lost by the custom error")
+			}
+			if _, ok := contract.UnpackError(errors.New("not a revert")); ok {
+				t.Fatalf("unpacked error without revert data")
+			}
 	   `,
 		nil,
 		nil,
@@ -2145,3 +2176,43 @@ func TestGolangBindings(t *testing.T) {
 		t.Fatalf("failed to run binding test: %v\n%s", err, out)
 	}
 }
+
+var update = flag.Bool("update", false, "update the golden files of the generated bindings")
+
+// Tests that the TypeScript and Python bindings generated for a selection of the
+// binding test contracts match the golden files in testdata.
+func TestForeignBindings(t *testing.T) {
+	t.Parallel()
+
+	golden := map[string]bool{"Token": true, "Structs": true, "Eventer": true, "UseLibrary": true, "NewErrors": true}
+	for _, tt := range bindTests {
+		if !golden[tt.name] {
+			continue
+		}
+		for lang, ext := range map[Lang]string{LangTS: "ts", LangPy: "py"} {
+			t.Run(tt.name+"/"+ext, func(t *testing.T) {
+				types := tt.types
+				if types == nil {
+					types = []string{tt.name}
+				}
+				code, err := Bind(types, tt.abi, tt.bytecode, tt.fsigs, "", lang, tt.libs, tt.aliases)
+				if err != nil {
+					t.Fatalf("failed to generate binding: %v", err)
+				}
+				path := filepath.Join("testdata", strings.ToLower(tt.name)+"."+ext+".golden")
+				if *update {
+					if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+						t.Fatalf("failed to update golden file: %v", err)
+					}
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read golden file: %v", err)
+				}
+				if code != string(want) {
+					t.Errorf("binding mismatch against %s, rerun with -update to regenerate:\n%s", path, code)
+				}
+			})
+		}
+	}
+}
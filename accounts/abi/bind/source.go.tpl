@@ -237,6 +237,11 @@ var (
 		func (_{{$contract.Type}} *{{$contract.Type}}Caller) {{.Normalized.Name}}(opts *bind.CallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
 			var out []interface{}
 			err := _{{$contract.Type}}.contract.Call(opts, &out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			{{- if $contract.Errors}}
+			if err != nil {
+				err = unpack{{$contract.Type}}Error(_{{$contract.Type}}.contract, err)
+			}
+			{{- end}}
 			{{if .Structured}}
 			outstruct := new(struct{ {{range .Normalized.Outputs}} {{.Name}} {{bindtype .Type $structs}}; {{end}} })
 			if err != nil {
@@ -257,6 +262,30 @@ var (
 			{{end}}
 		}
 
+		// Batch{{.Normalized.Name}} queues a call of the contract method 0x{{printf "%x" .Original.ID}} into batch.
+		// The result is available once the batch has been executed.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Caller) Batch{{.Normalized.Name}}(batch *bind.Batch {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) *bind.BatchResult[{{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} }{{else if eq (len .Normalized.Outputs) 1}}{{bindtype (index .Normalized.Outputs 0).Type $structs}}{{else}}[]interface{}{{end}}] {
+			call := batch.Add(_{{$contract.Type}}.contract, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			{{if .Structured}}
+			return bind.NewBatchResult(call, func(out []interface{}) struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} } {
+				outstruct := new(struct{ {{range .Normalized.Outputs}} {{.Name}} {{bindtype .Type $structs}}; {{end}} })
+				{{range $i, $t := .Normalized.Outputs}}
+				outstruct.{{.Name}} = *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+				return *outstruct
+			})
+			{{else if eq (len .Normalized.Outputs) 1}}
+			return bind.NewBatchResult(call, func(out []interface{}) {{bindtype (index .Normalized.Outputs 0).Type $structs}} {
+				return *abi.ConvertType(out[0], new({{bindtype (index .Normalized.Outputs 0).Type $structs}})).(*{{bindtype (index .Normalized.Outputs 0).Type $structs}})
+			})
+			{{else}}
+			return bind.NewBatchResult(call, func(out []interface{}) []interface{} {
+				return out
+			})
+			{{end}}
+		}
+
 		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
 		//
 		// Solidity: {{.Original.String}}
@@ -277,7 +306,15 @@ var (
 		//
 		// Solidity: {{.Original.String}}
 		func (_{{$contract.Type}} *{{$contract.Type}}Transactor) {{.Normalized.Name}}(opts *bind.TransactOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) (*types.Transaction, error) {
+			{{- if $contract.Errors}}
+			tx, err := _{{$contract.Type}}.contract.Transact(opts, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			if err != nil {
+				return nil, unpack{{$contract.Type}}Error(_{{$contract.Type}}.contract, err)
+			}
+			return tx, nil
+			{{- else}}
 			return _{{$contract.Type}}.contract.Transact(opts, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			{{- end}}
 		}
 
 		// {{.Normalized.Name}} is a paid mutator transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
@@ -484,4 +521,75 @@ var (
 		}
 
  	{{end}}
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}}Error represents a {{.Normalized.Name}} custom error raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}}Error struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{bindtype .Type $structs}}; {{end}}
+			cause error // Error returned by the backend, carrying the revert data
+		}
+
+		// ErrorID returns the 4-byte selector of the {{.Normalized.Name}} error.
+		//
+		// Solidity: {{.Original.String}}
+		func (e *{{$contract.Type}}{{.Normalized.Name}}Error) ErrorID() [4]byte {
+			return [4]byte{ {{range $i, $b := slice .Original.ID.Bytes 0 4}}{{if $i}}, {{end}}{{printf "0x%02x" $b}}{{end}} }
+		}
+
+		// Error implements the error interface, formatting the error with its arguments.
+		func (e *{{$contract.Type}}{{.Normalized.Name}}Error) Error() string {
+			return bind.FormatError("{{.Original.Name}}"{{range .Normalized.Inputs}}, e.{{capitalise .Name}}{{end}})
+		}
+
+		// Unwrap returns the error returned by the backend for the reverted call.
+		func (e *{{$contract.Type}}{{.Normalized.Name}}Error) Unwrap() error {
+			return e.cause
+		}
+	{{end}}
+	{{- if .Errors}}
+
+	// unpack{{$contract.Type}}Error converts an error carrying the revert data of
+	// one of the custom errors of the {{.Type}} contract into its typed struct,
+	// wrapping the original error. Other errors are returned as they are.
+	func unpack{{$contract.Type}}Error(contract *bind.BoundContract, err error) error {
+		data, ok := bind.RevertData(err)
+		if !ok {
+			return err
+		}
+		abiErr, args, uerr := contract.UnpackError(data)
+		if uerr != nil {
+			return err
+		}
+		switch abiErr.Name {
+		{{- range .Errors}}
+		case "{{.Original.Name}}":
+			return &{{$contract.Type}}{{.Normalized.Name}}Error{ {{range $i, $in := .Normalized.Inputs}}
+				{{capitalise .Name}}: *abi.ConvertType(args[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}),{{end}}
+				cause: err,
+			}
+		{{- end}}
+		}
+		return err
+	}
+	{{- end}}
+
+	// UnpackError decodes the revert data carried by an error returned from a call
+	// or gas estimation against the {{.Type}} contract. Custom errors are returned
+	// as their typed {{.Type}}*Error structs, standard reverts and panics as their
+	// reason string. Calls and transactions of the contract already return the
+	// typed custom errors, which can be retrieved with errors.As.
+	func (_{{$contract.Type}} *{{$contract.Type}}) UnpackError(err error) (any, bool) {
+		data, ok := bind.RevertData(err)
+		if !ok {
+			return nil, false
+		}
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			return reason, true
+		}
+		{{- if .Errors}}
+		if unpacked := unpack{{$contract.Type}}Error(_{{$contract.Type}}.{{$contract.Type}}Caller.contract, err); unpacked != err {
+			return unpacked, true
+		}
+		{{- end}}
+		return nil, false
+	}
 {{end}}
\ No newline at end of file
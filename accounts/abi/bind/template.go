@@ -42,6 +42,7 @@ type tmplContract struct {
 	Fallback    *tmplMethod            // Additional special fallback function
 	Receive     *tmplMethod            // Additional special receive function
 	Events      map[string]*tmplEvent  // Contract events accessors
+	Errors      map[string]*tmplError  // Contract custom errors
 	Libraries   map[string]string      // Same as tmplData, but filtered to only keep what the contract needs
 	Library     bool                   // Indicator whether the contract is a library
 }
@@ -61,11 +62,19 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplError is a wrapper around an abi.Error that contains a few preprocessed
+// and cached data fields.
+type tmplError struct {
+	Original   abi.Error // Original error as parsed by the abi package
+	Normalized abi.Error // Normalized version of the parsed fields
+}
+
 // tmplField is a wrapper around a struct field with binding language
 // struct type definition and relative filed name.
 type tmplField struct {
 	Type    string   // Field type representation depends on target binding language
 	Name    string   // Field name converted from the raw user-defined field name
+	RawName string   // Field name as declared in the ABI
 	SolKind abi.Type // Raw abi type information
 }
 
@@ -80,6 +89,8 @@ type tmplStruct struct {
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
 	LangGo: tmplSourceGo,
+	LangTS: tmplSourceTS,
+	LangPy: tmplSourcePy,
 }
 
 // tmplSourceGo is the Go source template that the generated Go contract binding
@@ -87,3 +98,15 @@ var tmplSource = map[Lang]string{
 //
 //go:embed source.go.tpl
 var tmplSourceGo string
+
+// tmplSourceTS is the TypeScript source template that the generated ethers v6
+// contract wrapper is based on.
+//
+//go:embed source.ts.tpl
+var tmplSourceTS string
+
+// tmplSourcePy is the Python source template that the generated web3.py
+// contract wrapper is based on.
+//
+//go:embed source.py.tpl
+var tmplSourcePy string
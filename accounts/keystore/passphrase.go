@@ -203,6 +203,10 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	if err := json.Unmarshal(keyjson, &m); err != nil {
 		return nil, err
 	}
+	// Refuse keys of other curves, whose scalars would silently be misread
+	if curve, ok := m["curve"].(string); ok && curve != "secp256k1" {
+		return nil, fmt.Errorf("unsupported key curve %q", curve)
+	}
 	// Depending on the version try to parse one way or another
 	var (
 		keyBytes, keyId []byte
@@ -0,0 +1,160 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package p256 implements an account backend for secp256r1 (P-256) keys, the
+// keys used by passkeys and verified on-chain by the RIP-7212 P256VERIFY
+// precompile.
+//
+// P-256 keys can not sign Ethereum transactions. They are meant to control
+// smart accounts, which verify the signatures of arbitrary hashes produced by
+// this backend.
+package p256
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
+	"github.com/google/uuid"
+)
+
+// Curve is the curve identifier stored in the key files of this backend.
+const Curve = "secp256r1"
+
+// version is the version of the encrypted key format, which is the version 3
+// keystore format extended with the curve and public key.
+const version = 3
+
+var errNotP256Key = errors.New("not a secp256r1 key file")
+
+// Key is a secp256r1 private key along with the identifiers it is stored under.
+// It parallels keystore.Key.
+type Key struct {
+	Id uuid.UUID // Version 4 "random" for unique id not derived from key data
+	// Address identifies the key, it is derived from the public key the same
+	// way Ethereum addresses are, but does not control any funds
+	Address common.Address
+	// PrivateKey is the secp256r1 key, always in plaintext
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// encryptedKeyJSON is the on-disk format of an encrypted secp256r1 key.
+type encryptedKeyJSON struct {
+	Address   string              `json:"address"`
+	Curve     string              `json:"curve"`
+	PublicKey string              `json:"publicKey"`
+	Crypto    keystore.CryptoJSON `json:"crypto"`
+	Id        string              `json:"id"`
+	Version   int                 `json:"version"`
+}
+
+// PubkeyToAddress derives the identifying address of a secp256r1 public key,
+// the last 20 bytes of the keccak256 hash of its x || y encoding.
+func PubkeyToAddress(pub *ecdsa.PublicKey) common.Address {
+	return common.BytesToAddress(crypto.Keccak256(secp256r1.MarshalPubkey(pub))[12:])
+}
+
+// NewKey creates a key from a secp256r1 private key, with a random id.
+func NewKey(privateKey *ecdsa.PrivateKey) (*Key, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("could not create random uuid: %w", err)
+	}
+	return &Key{
+		Id:         id,
+		Address:    PubkeyToAddress(&privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// EncryptKey encrypts a key using the specified scrypt parameters into a json
+// blob that can be decrypted later on.
+func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	cryptoStruct, err := keystore.EncryptDataV3(secp256r1.FromECDSA(key.PrivateKey), []byte(auth), scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedKeyJSON{
+		Address:   hex.EncodeToString(key.Address[:]),
+		Curve:     Curve,
+		PublicKey: hex.EncodeToString(secp256r1.MarshalPubkey(&key.PrivateKey.PublicKey)),
+		Crypto:    cryptoStruct,
+		Id:        key.Id.String(),
+		Version:   version,
+	})
+}
+
+// DecryptKey decrypts a key from a json blob, returning the private key itself.
+func DecryptKey(keyjson []byte, auth string) (*Key, error) {
+	k := new(encryptedKeyJSON)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return nil, err
+	}
+	if k.Curve != Curve {
+		return nil, errNotP256Key
+	}
+	if k.Version != version {
+		return nil, fmt.Errorf("version not supported: %v", k.Version)
+	}
+	keyBytes, err := keystore.DecryptDataV3(k.Crypto, auth)
+	if err != nil {
+		return nil, err
+	}
+	key, err := secp256r1.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	id, err := uuid.Parse(k.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID: %w", err)
+	}
+	return &Key{
+		Id:         id,
+		Address:    PubkeyToAddress(&key.PublicKey),
+		PrivateKey: key,
+	}, nil
+}
+
+// readPublicKey parses the unencrypted public key of a json key blob and checks
+// that it matches the stored address.
+func readPublicKey(keyjson []byte) (common.Address, *ecdsa.PublicKey, error) {
+	k := new(encryptedKeyJSON)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return common.Address{}, nil, err
+	}
+	if k.Curve != Curve {
+		return common.Address{}, nil, errNotP256Key
+	}
+	blob, err := hex.DecodeString(k.PublicKey)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	pub, err := secp256r1.UnmarshalPubkey(blob)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	addr := PubkeyToAddress(pub)
+	if !common.IsHexAddress(k.Address) || common.HexToAddress(k.Address) != addr {
+		return common.Address{}, nil, fmt.Errorf("address mismatch: have %s, want %x", k.Address, addr)
+	}
+	return addr, pub, nil
+}
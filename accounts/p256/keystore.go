@@ -0,0 +1,347 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p256
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// KeyStoreScheme is the protocol scheme prefixing account and wallet URLs.
+const KeyStoreScheme = "p256keystore"
+
+// KeyStore manages secp256r1 keys stored as encrypted files in a directory. It
+// implements accounts.Backend, with every key exposed as a separate wallet.
+//
+// Unlike the secp256k1 keystore, the directory is only scanned when the key
+// store is created; keys added or removed through other means afterwards are
+// not picked up.
+type KeyStore struct {
+	dir              string
+	scryptN, scryptP int
+
+	accounts []accounts.Account                  // Accounts in the directory, sorted by URL
+	pubkeys  map[common.Address]*ecdsa.PublicKey // Public keys of the accounts
+	unlocked map[common.Address]*Key             // Currently unlocked keys
+	mu       sync.RWMutex
+
+	feed  event.Feed              // Wallet arrival and departure notifications
+	scope event.SubscriptionScope // Subscription scope tracking current live listeners
+}
+
+// NewKeyStore creates a key store for the given directory, loading the accounts
+// of all secp256r1 key files found within.
+func NewKeyStore(dir string, scryptN, scryptP int) *KeyStore {
+	ks := &KeyStore{
+		dir:      dir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		pubkeys:  make(map[common.Address]*ecdsa.PublicKey),
+		unlocked: make(map[common.Address]*Key),
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("Failed to read P-256 keystore", "dir", dir, "err", err)
+		}
+		return ks
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		blob, err := os.ReadFile(path)
+		if err != nil {
+			log.Debug("Failed to read P-256 key file", "path", path, "err", err)
+			continue
+		}
+		addr, pub, err := readPublicKey(blob)
+		if err != nil {
+			log.Debug("Skipping invalid P-256 key file", "path", path, "err", err)
+			continue
+		}
+		ks.add(accounts.Account{Address: addr, URL: accounts.URL{Scheme: KeyStoreScheme, Path: path}}, pub)
+	}
+	return ks
+}
+
+// add inserts an account into the sorted account list. The caller must hold
+// the lock, or have exclusive access to the key store.
+func (ks *KeyStore) add(account accounts.Account, pub *ecdsa.PublicKey) {
+	i, _ := slices.BinarySearchFunc(ks.accounts, account, func(a, b accounts.Account) int {
+		return a.URL.Cmp(b.URL)
+	})
+	ks.accounts = slices.Insert(ks.accounts, i, account)
+	ks.pubkeys[account.Address] = pub
+}
+
+// Wallets implements accounts.Backend, returning a wallet for every key in the
+// key store directory.
+func (ks *KeyStore) Wallets() []accounts.Wallet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	wallets := make([]accounts.Wallet, len(ks.accounts))
+	for i, account := range ks.accounts {
+		wallets[i] = &wallet{account: account, keystore: ks}
+	}
+	return wallets
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition or removal of keys.
+func (ks *KeyStore) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return ks.scope.Track(ks.feed.Subscribe(sink))
+}
+
+// Close terminates all subscriptions of the key store.
+func (ks *KeyStore) Close() {
+	ks.scope.Close()
+}
+
+// Accounts returns all key files present in the directory.
+func (ks *KeyStore) Accounts() []accounts.Account {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return slices.Clone(ks.accounts)
+}
+
+// HasAddress reports whether a key with the given address is present.
+func (ks *KeyStore) HasAddress(addr common.Address) bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	_, ok := ks.pubkeys[addr]
+	return ok
+}
+
+// find resolves the account within the key store, matching by address and by
+// URL if one is given. The caller must hold the lock.
+func (ks *KeyStore) find(a accounts.Account) (accounts.Account, error) {
+	for _, account := range ks.accounts {
+		if account.Address == a.Address && (a.URL == (accounts.URL{}) || account.URL == a.URL) {
+			return account, nil
+		}
+	}
+	return accounts.Account{}, keystore.ErrNoMatch
+}
+
+// PublicKey returns the public key of an account, which is stored unencrypted.
+func (ks *KeyStore) PublicKey(a accounts.Account) (*ecdsa.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if _, err := ks.find(a); err != nil {
+		return nil, err
+	}
+	return ks.pubkeys[a.Address], nil
+}
+
+// NewAccount generates a new key and stores it into the key directory,
+// encrypting it with the passphrase.
+func (ks *KeyStore) NewAccount(passphrase string) (accounts.Account, error) {
+	privateKey, err := secp256r1.GenerateKey()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return ks.ImportECDSA(privateKey, passphrase)
+}
+
+// ImportECDSA stores the given secp256r1 key into the key directory, encrypting
+// it with the passphrase.
+func (ks *KeyStore) ImportECDSA(privateKey *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	key, err := NewKey(privateKey)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return ks.importKey(key, passphrase)
+}
+
+// Import stores the given encrypted JSON key into the key directory.
+func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	key, err := DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return ks.importKey(key, newPassphrase)
+}
+
+// importKey encrypts and stores a key, announcing its wallet to subscribers.
+func (ks *KeyStore) importKey(key *Key, passphrase string) (accounts.Account, error) {
+	keyjson, err := EncryptKey(key, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	ks.mu.Lock()
+	if _, ok := ks.pubkeys[key.Address]; ok {
+		ks.mu.Unlock()
+		return accounts.Account{Address: key.Address}, keystore.ErrAccountAlreadyExists
+	}
+	path := filepath.Join(ks.dir, keyFileName(key.Address))
+	if err := writeKeyFile(path, keyjson); err != nil {
+		ks.mu.Unlock()
+		return accounts.Account{}, err
+	}
+	account := accounts.Account{Address: key.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: path}}
+	ks.add(account, &key.PrivateKey.PublicKey)
+	ks.mu.Unlock()
+
+	// Notify outside the lock, as subscribers may query the key store
+	ks.feed.Send(accounts.WalletEvent{Wallet: &wallet{account: account, keystore: ks}, Kind: accounts.WalletArrived})
+	return account, nil
+}
+
+// Export exports a key as an encrypted JSON blob, encrypted with newPassphrase.
+func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptKey(key, newPassphrase, ks.scryptN, ks.scryptP)
+}
+
+// Delete deletes the key matched by account if the passphrase is correct.
+func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
+	a, _, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	if err := os.Remove(a.URL.Path); err != nil {
+		ks.mu.Unlock()
+		return err
+	}
+	ks.accounts = slices.DeleteFunc(ks.accounts, func(account accounts.Account) bool { return account == a })
+	delete(ks.pubkeys, a.Address)
+	delete(ks.unlocked, a.Address)
+	ks.mu.Unlock()
+
+	ks.feed.Send(accounts.WalletEvent{Wallet: &wallet{account: a, keystore: ks}, Kind: accounts.WalletDropped})
+	return nil
+}
+
+// Unlock decrypts a key and keeps it in memory until Lock is called, so hashes
+// can be signed without a passphrase.
+func (ks *KeyStore) Unlock(a accounts.Account, passphrase string) error {
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.unlocked[a.Address] = key
+	return nil
+}
+
+// Lock removes the private key with the given address from memory.
+func (ks *KeyStore) Lock(addr common.Address) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	delete(ks.unlocked, addr)
+	return nil
+}
+
+// SignHash calculates a secp256r1 signature of the given hash with an unlocked
+// key. The signature is returned in the 64 byte r || s format verified by the
+// P256VERIFY precompile.
+func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.unlocked[a.Address]
+	if !ok {
+		return nil, keystore.ErrLocked
+	}
+	return secp256r1.Sign(hash, key.PrivateKey)
+}
+
+// SignHashWithPassphrase signs the hash if the private key matching the given
+// address can be decrypted with the given passphrase.
+func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return secp256r1.Sign(hash, key.PrivateKey)
+}
+
+// getDecryptedKey loads and decrypts the key of an account.
+func (ks *KeyStore) getDecryptedKey(a accounts.Account, passphrase string) (accounts.Account, *Key, error) {
+	ks.mu.RLock()
+	a, err := ks.find(a)
+	ks.mu.RUnlock()
+	if err != nil {
+		return a, nil, err
+	}
+	keyjson, err := os.ReadFile(a.URL.Path)
+	if err != nil {
+		return a, nil, err
+	}
+	key, err := DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return a, nil, err
+	}
+	if key.Address != a.Address {
+		return a, nil, fmt.Errorf("key content mismatch: have account %x, want %x", key.Address, a.Address)
+	}
+	return a, key, nil
+}
+
+// keyFileName implements the naming convention for key files, matching the one
+// of the secp256k1 keystore.
+func keyFileName(addr common.Address) string {
+	ts := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	return fmt.Sprintf("UTC--%s--%s", ts, hex.EncodeToString(addr[:]))
+}
+
+// writeKeyFile atomically writes a key file, creating the directory if needed.
+func writeKeyFile(file string, content []byte) error {
+	const dirPerm = 0700
+	if err := os.MkdirAll(filepath.Dir(file), dirPerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), file)
+}
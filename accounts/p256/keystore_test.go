@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p256
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
+)
+
+// Tests that keys can be created, reloaded, unlocked and used to produce
+// signatures accepted by the P256VERIFY precompile.
+func TestKeyStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ks := NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+
+	events := make(chan accounts.WalletEvent, 2)
+	sub := ks.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	account, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if ev := <-events; ev.Kind != accounts.WalletArrived || ev.Wallet.URL() != account.URL {
+		t.Errorf("unexpected wallet event: %v", ev)
+	}
+	// Reload the directory and check the key is found
+	ks = NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	if wallets := ks.Wallets(); len(wallets) != 1 || !wallets[0].Contains(account) {
+		t.Fatalf("reloaded key store mismatch: %v", ks.Accounts())
+	}
+	pub, err := ks.PublicKey(account)
+	if err != nil {
+		t.Fatalf("failed to retrieve public key: %v", err)
+	}
+	if PubkeyToAddress(pub) != account.Address {
+		t.Errorf("public key does not match the account")
+	}
+	hash := crypto.Keccak256([]byte("hello"))
+
+	// Signing requires the key to be unlocked, or the passphrase
+	if _, err := ks.SignHash(account, hash); !errors.Is(err, keystore.ErrLocked) {
+		t.Errorf("signing with locked key: have %v, want %v", err, keystore.ErrLocked)
+	}
+	if _, err := ks.SignHashWithPassphrase(account, "bar", hash); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Errorf("signing with wrong passphrase: have %v, want %v", err, keystore.ErrDecrypt)
+	}
+	if err := ks.Unlock(account, "foo"); err != nil {
+		t.Fatalf("failed to unlock key: %v", err)
+	}
+	sig, err := ks.SignHash(account, hash)
+	if err != nil {
+		t.Fatalf("failed to sign hash: %v", err)
+	}
+	input := bytes.Join([][]byte{hash, sig, secp256r1.MarshalPubkey(pub)}, nil)
+	precompile := vm.PrecompiledContractsGranite[common.BytesToAddress([]byte{0x01, 0x00})]
+	if out, err := precompile.Run(input); err != nil || !bytes.Equal(out, common.LeftPadBytes([]byte{1}, 32)) {
+		t.Errorf("signature rejected by precompile: %x, %v", out, err)
+	}
+	// Transactions can't be signed with secp256r1 keys
+	if _, err := ks.Wallets()[0].SignTx(account, nil, nil); !errors.Is(err, accounts.ErrNotSupported) {
+		t.Errorf("signing transaction: have %v, want %v", err, accounts.ErrNotSupported)
+	}
+	// Exported keys can be reimported into another directory, but not into the
+	// same one twice
+	keyjson, err := ks.Export(account, "foo", "baz")
+	if err != nil {
+		t.Fatalf("failed to export key: %v", err)
+	}
+	if _, err := ks.Import(keyjson, "baz", "foo"); !errors.Is(err, keystore.ErrAccountAlreadyExists) {
+		t.Errorf("reimporting key: have %v, want %v", err, keystore.ErrAccountAlreadyExists)
+	}
+	other := NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	if imported, err := other.Import(keyjson, "baz", "qux"); err != nil || imported.Address != account.Address {
+		t.Errorf("failed to import key: %v", err)
+	}
+	// The secp256k1 keystore must refuse the key
+	if _, err := keystore.DecryptKey(keyjson, "baz"); err == nil {
+		t.Errorf("secp256k1 keystore decrypted a secp256r1 key")
+	}
+	if err := ks.Delete(account, "foo"); err != nil {
+		t.Fatalf("failed to delete key: %v", err)
+	}
+	if len(NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP).Accounts()) != 0 {
+		t.Errorf("deleted key still present")
+	}
+}
@@ -57,7 +57,7 @@ var (
 	}
 	pkgFlag = &cli.StringFlag{
 		Name:  "pkg",
-		Usage: "Package name to generate the binding into",
+		Usage: "Package name to generate the binding into (go only)",
 	}
 	outFlag = &cli.StringFlag{
 		Name:  "out",
@@ -65,7 +65,7 @@ var (
 	}
 	langFlag = &cli.StringFlag{
 		Name:  "lang",
-		Usage: "Destination language for the bindings (go)",
+		Usage: "Destination language for the bindings (go, ts, py)",
 		Value: "go",
 	}
 	aliasFlag = &cli.StringFlag{
@@ -95,16 +95,21 @@ func init() {
 func abigen(c *cli.Context) error {
 	utils.CheckExclusive(c, abiFlag, jsonFlag) // Only one source can be selected.
 
-	if c.String(pkgFlag.Name) == "" {
-		utils.Fatalf("No destination package specified (--pkg)")
-	}
 	var lang bind.Lang
 	switch c.String(langFlag.Name) {
 	case "go":
 		lang = bind.LangGo
+	case "ts":
+		lang = bind.LangTS
+	case "py":
+		lang = bind.LangPy
 	default:
 		utils.Fatalf("Unsupported destination language \"%s\" (--lang)", c.String(langFlag.Name))
 	}
+	// Only Go bindings are placed into a package
+	if lang == bind.LangGo && c.String(pkgFlag.Name) == "" {
+		utils.Fatalf("No destination package specified (--pkg)")
+	}
 	// If the entire solidity code was specified, build and bind based on that
 	var (
 		abis    []string
@@ -146,6 +151,9 @@ func abigen(c *cli.Context) error {
 		if kind == "" {
 			kind = c.String(pkgFlag.Name)
 		}
+		if kind == "" {
+			utils.Fatalf("No contract type specified (--type)")
+		}
 		types = append(types, kind)
 	} else {
 		// Generate the list of types to exclude from binding
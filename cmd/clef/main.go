@@ -53,6 +53,7 @@ import (
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/ethereum/go-ethereum/signer/fourbyte"
 	"github.com/ethereum/go-ethereum/signer/rules"
+	"github.com/ethereum/go-ethereum/signer/simulate"
 	"github.com/ethereum/go-ethereum/signer/storage"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
@@ -126,6 +127,10 @@ var (
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
 	}
+	simulateRPCFlag = &cli.StringFlag{
+		Name:  "simulate.rpc",
+		Usage: "RPC endpoint of a node to simulate transactions on, exposing their effects to the UI and rules",
+	}
 	stdiouiFlag = &cli.BoolFlag{
 		Name: "stdio-ui",
 		Usage: "Use STDIN/STDOUT as a channel for an external UI. " +
@@ -281,6 +286,7 @@ func init() {
 		customDBFlag,
 		auditLogFlag,
 		ruleFlag,
+		simulateRPCFlag,
 		stdiouiFlag,
 		testFlag,
 		advancedMode,
@@ -706,6 +712,15 @@ func signer(c *cli.Context) error {
 	am := core.StartClefAccountManager(ksLoc, nousb, lightKdf, scpath)
 	defer am.Close()
 	apiImpl := core.NewSignerAPI(am, chainId, nousb, ui, db, advanced, pwStorage)
+	if endpoint := c.String(simulateRPCFlag.Name); endpoint != "" {
+		client, err := rpc.Dial(endpoint)
+		if err != nil {
+			utils.Fatalf("Failed to connect to simulation endpoint: %v", err)
+		}
+		defer client.Close()
+		apiImpl.SetSimulator(simulate.New(client))
+		log.Info("Simulating transactions before approval", "endpoint", endpoint)
+	}
 
 	// Establish the bidirectional communication, by creating a new UI backend and registering
 	// it with the UI.
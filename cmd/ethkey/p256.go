@@ -0,0 +1,270 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/p256"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
+	"github.com/urfave/cli/v2"
+)
+
+type outputP256Inspect struct {
+	Address    string
+	PublicKey  string
+	X          string
+	Y          string
+	PrivateKey string `json:",omitempty"`
+}
+
+type outputP256Sign struct {
+	Signature string
+	R         string
+	S         string
+	// PrecompileInput is the input of the P256VERIFY precompile verifying
+	// the signature: hash || r || s || x || y.
+	PrecompileInput string
+}
+
+var commandP256 = &cli.Command{
+	Name:  "p256",
+	Usage: "manage secp256r1 (P-256) keyfiles",
+	Description: `
+Manage secp256r1 keyfiles, the keys used by passkeys and verified on-chain by
+the P256VERIFY precompile (RIP-7212). These keys can not sign transactions,
+they are meant to control smart accounts.`,
+	Subcommands: []*cli.Command{
+		commandP256Generate,
+		commandP256Inspect,
+		commandP256SignHash,
+	},
+}
+
+var commandP256Generate = &cli.Command{
+	Name:      "generate",
+	Usage:     "generate new secp256r1 keyfile",
+	ArgsUsage: "[ <keyfile> ]",
+	Description: `
+Generate a new secp256r1 keyfile.
+
+If you want to encrypt an existing private key, it can be specified by setting
+--privatekey with the location of the file containing the hex encoded private key.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		privateKeyFlag,
+		lightKDFFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		// Check if keyfile path given and make sure it doesn't already exist.
+		keyfilepath := ctx.Args().First()
+		if keyfilepath == "" {
+			keyfilepath = defaultKeyfileName
+		}
+		if _, err := os.Stat(keyfilepath); err == nil {
+			utils.Fatalf("Keyfile already exists at %s.", keyfilepath)
+		} else if !os.IsNotExist(err) {
+			utils.Fatalf("Error checking if keyfile exists: %v", err)
+		}
+
+		var privateKey *ecdsa.PrivateKey
+		var err error
+		if file := ctx.String(privateKeyFlag.Name); file != "" {
+			// Load private key from file.
+			privateKey, err = loadP256Key(file)
+			if err != nil {
+				utils.Fatalf("Can't load private key: %v", err)
+			}
+		} else {
+			// If not loaded, generate random.
+			privateKey, err = secp256r1.GenerateKey()
+			if err != nil {
+				utils.Fatalf("Failed to generate random private key: %v", err)
+			}
+		}
+		key, err := p256.NewKey(privateKey)
+		if err != nil {
+			utils.Fatalf("Failed to create key: %v", err)
+		}
+
+		// Encrypt key with passphrase.
+		passphrase := getPassphrase(ctx, true)
+		scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+		if ctx.Bool(lightKDFFlag.Name) {
+			scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+		}
+		keyjson, err := p256.EncryptKey(key, passphrase, scryptN, scryptP)
+		if err != nil {
+			utils.Fatalf("Error encrypting key: %v", err)
+		}
+
+		// Store the file to disk.
+		if err := os.MkdirAll(filepath.Dir(keyfilepath), 0700); err != nil {
+			utils.Fatalf("Could not create directory %s", filepath.Dir(keyfilepath))
+		}
+		if err := os.WriteFile(keyfilepath, keyjson, 0600); err != nil {
+			utils.Fatalf("Failed to write keyfile to %s: %v", keyfilepath, err)
+		}
+
+		// Output some information.
+		out := p256Info(key, false)
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Address:   ", out.Address)
+			fmt.Println("Public key:", out.PublicKey)
+		}
+		return nil
+	},
+}
+
+var commandP256Inspect = &cli.Command{
+	Name:      "inspect",
+	Usage:     "inspect a secp256r1 keyfile",
+	ArgsUsage: "<keyfile>",
+	Description: `
+Print various information about the secp256r1 keyfile, including the public key
+coordinates expected by on-chain verifiers.
+
+Private key information can be printed by using the --private flag;
+make sure to use this feature with great caution!`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		privateFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		key := loadP256Keyfile(ctx, ctx.Args().First())
+
+		// Output all relevant information we can retrieve.
+		showPrivate := ctx.Bool(privateFlag.Name)
+		out := p256Info(key, showPrivate)
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Address:       ", out.Address)
+			fmt.Println("Public key:    ", out.PublicKey)
+			fmt.Println("Public key X:  ", out.X)
+			fmt.Println("Public key Y:  ", out.Y)
+			if showPrivate {
+				fmt.Println("Private key:   ", out.PrivateKey)
+			}
+		}
+		return nil
+	},
+}
+
+var commandP256SignHash = &cli.Command{
+	Name:      "signhash",
+	Usage:     "sign a hash with a secp256r1 keyfile",
+	ArgsUsage: "<keyfile> <hash>",
+	Description: `
+Sign a 32 byte hash with a secp256r1 keyfile. The signature is printed in the
+r || s format, along with the input of the P256VERIFY precompile verifying it.
+The s value of the signature is always in the lower half of the curve order.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 2 {
+			utils.Fatalf("This command requires two arguments.")
+		}
+		hash, err := decodeHex(ctx.Args().Get(1))
+		if err != nil || len(hash) != common.HashLength {
+			utils.Fatalf("Invalid hash: must be 32 hex encoded bytes")
+		}
+		key := loadP256Keyfile(ctx, ctx.Args().First())
+
+		sig, err := secp256r1.Sign(hash, key.PrivateKey)
+		if err != nil {
+			utils.Fatalf("Failed to sign hash: %v", err)
+		}
+		input := bytes.Join([][]byte{hash, sig, secp256r1.MarshalPubkey(&key.PrivateKey.PublicKey)}, nil)
+		out := outputP256Sign{
+			Signature:       hex.EncodeToString(sig),
+			R:               hex.EncodeToString(sig[:32]),
+			S:               hex.EncodeToString(sig[32:]),
+			PrecompileInput: hex.EncodeToString(input),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Signature:       ", out.Signature)
+			fmt.Println("Precompile input:", out.PrecompileInput)
+		}
+		return nil
+	},
+}
+
+// loadP256Keyfile reads and decrypts a secp256r1 keyfile.
+func loadP256Keyfile(ctx *cli.Context, keyfilepath string) *p256.Key {
+	keyjson, err := os.ReadFile(keyfilepath)
+	if err != nil {
+		utils.Fatalf("Failed to read the keyfile at '%s': %v", keyfilepath, err)
+	}
+	passphrase := getPassphrase(ctx, false)
+	key, err := p256.DecryptKey(keyjson, passphrase)
+	if err != nil {
+		utils.Fatalf("Error decrypting key: %v", err)
+	}
+	return key
+}
+
+// loadP256Key loads a hex encoded secp256r1 private key from a file.
+func loadP256Key(file string) (*ecdsa.PrivateKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decodeHex(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	return secp256r1.ToECDSA(d)
+}
+
+// decodeHex decodes a hex string, with or without 0x prefix.
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+}
+
+// p256Info assembles the printable information about a key.
+func p256Info(key *p256.Key, showPrivate bool) outputP256Inspect {
+	pub := secp256r1.MarshalPubkey(&key.PrivateKey.PublicKey)
+	out := outputP256Inspect{
+		Address:   key.Address.Hex(),
+		PublicKey: hex.EncodeToString(pub),
+		X:         hex.EncodeToString(pub[:32]),
+		Y:         hex.EncodeToString(pub[32:]),
+	}
+	if showPrivate {
+		out.PrivateKey = hex.EncodeToString(secp256r1.FromECDSA(key.PrivateKey))
+	}
+	return out
+}
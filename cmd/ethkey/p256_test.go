@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/secp256r1"
+)
+
+func TestP256SignHash(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+
+	keyfile := filepath.Join(tmpdir, "the-keyfile")
+	hash := hex.EncodeToString(crypto.Keccak256([]byte("test message")))
+
+	// Create the key.
+	generate := runEthkey(t, "p256", "generate", "--lightkdf", keyfile)
+	generate.Expect(`
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+Repeat password: {{.InputLine "foobar"}}
+`)
+	_, matches := generate.ExpectRegexp(`Address:    0x[0-9a-fA-F]{40}\nPublic key: ([0-9a-f]{128})\n`)
+	pubkey := matches[1]
+	generate.ExpectExit()
+
+	// Sign the hash.
+	sign := runEthkey(t, "p256", "signhash", keyfile, hash)
+	sign.Expect(`
+!! Unsupported terminal, password will be echoed.
+Password: {{.InputLine "foobar"}}
+`)
+	_, matches = sign.ExpectRegexp(`Signature:        ([0-9a-f]{128})\nPrecompile input: ([0-9a-f]{320})\n`)
+	signature, input := matches[1], matches[2]
+	sign.ExpectExit()
+
+	if want := hash + signature + pubkey; input != want {
+		t.Errorf("precompile input mismatch: have %s, want %s", input, want)
+	}
+	blob, _ := hex.DecodeString(input)
+	var (
+		r = new(big.Int).SetBytes(blob[32:64])
+		s = new(big.Int).SetBytes(blob[64:96])
+		x = new(big.Int).SetBytes(blob[96:128])
+		y = new(big.Int).SetBytes(blob[128:])
+	)
+	if !secp256r1.Verify(blob[:32], r, s, x, y) {
+		t.Error("signature verification failed")
+	}
+}
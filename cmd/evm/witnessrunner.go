@@ -0,0 +1,216 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	WitnessBlockFlag = &cli.StringFlag{
+		Name:     "block",
+		Usage:    "File containing the RLP encoded block to verify (binary or hex)",
+		Required: true,
+	}
+	WitnessFlag = &cli.StringFlag{
+		Name:     "witness",
+		Usage:    "File containing the witness of the block: RLP or compact encoded (binary or hex), or the JSON returned by debug_executionWitness",
+		Required: true,
+	}
+	WitnessConfigFlag = &cli.StringFlag{
+		Name:     "config",
+		Usage:    "File containing the chain configuration, or a genesis specification including it",
+		Required: true,
+	}
+)
+
+var verifyWitnessCommand = &cli.Command{
+	Action: verifyWitnessCmd,
+	Name:   "verify-witness",
+	Usage:  "Executes a block statelessly from its witness and verifies the resulting roots",
+	Description: `
+The verify-witness command executes a block using nothing but the state, code and
+headers contained in its execution witness, as produced by debug_executionWitness
+or the stateless Engine API. It reports the computed state and receipt roots and
+exits with a non-zero code if they do not match the ones in the block header.`,
+	Flags: []cli.Flag{
+		WitnessBlockFlag,
+		WitnessFlag,
+		WitnessConfigFlag,
+	},
+}
+
+// witnessResult is the outcome of executing a block from its witness.
+type witnessResult struct {
+	Number               hexutil.Uint64 `json:"number"`
+	Hash                 common.Hash    `json:"hash"`
+	PreStateRoot         common.Hash    `json:"preStateRoot"`
+	StateRoot            common.Hash    `json:"stateRoot"`
+	ExpectedStateRoot    common.Hash    `json:"expectedStateRoot"`
+	ReceiptsRoot         common.Hash    `json:"receiptsRoot"`
+	ExpectedReceiptsRoot common.Hash    `json:"expectedReceiptsRoot"`
+	Valid                bool           `json:"valid"`
+	Error                string         `json:"error,omitempty"`
+}
+
+func verifyWitnessCmd(ctx *cli.Context) error {
+	config, err := loadChainConfig(ctx.String(WitnessConfigFlag.Name))
+	if err != nil {
+		return err
+	}
+	blob, err := readBinaryOrHex(ctx.String(WitnessBlockFlag.Name))
+	if err != nil {
+		return err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(blob, block); err != nil {
+		return fmt.Errorf("invalid block: %v", err)
+	}
+	witness, err := loadWitness(ctx.String(WitnessFlag.Name))
+	if err != nil {
+		return err
+	}
+	result := verifyWitness(config, block, witness)
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	if !result.Valid {
+		return errors.New("witness verification failed")
+	}
+	return nil
+}
+
+// verifyWitness executes the block on top of the witness and compares the
+// resulting roots with the ones in the block header.
+func verifyWitness(config *params.ChainConfig, block *types.Block, witness *stateless.Witness) *witnessResult {
+	result := &witnessResult{
+		Number:               hexutil.Uint64(block.NumberU64()),
+		Hash:                 block.Hash(),
+		PreStateRoot:         witness.Root(),
+		ExpectedStateRoot:    block.Root(),
+		ExpectedReceiptsRoot: block.ReceiptHash(),
+	}
+	if witness.Headers[0].Hash() != block.ParentHash() {
+		result.Error = fmt.Sprintf("witness parent mismatch: have %v, want %v", witness.Headers[0].Hash(), block.ParentHash())
+		return result
+	}
+	// The stateless executor expects the roots it computes to be unset
+	header := block.Header()
+	header.Root, header.ReceiptHash = common.Hash{}, common.Hash{}
+	stripped := types.NewBlockWithHeader(header).WithBody(*block.Body())
+
+	stateRoot, receiptRoot, err := core.ExecuteStateless(config, stripped, witness)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.StateRoot, result.ReceiptsRoot = stateRoot, receiptRoot
+	result.Valid = stateRoot == block.Root() && receiptRoot == block.ReceiptHash()
+	return result
+}
+
+// loadChainConfig reads a chain configuration, either standalone or embedded in
+// a genesis specification.
+func loadChainConfig(path string) (*params.ChainConfig, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var genesis struct {
+		Config *params.ChainConfig `json:"config"`
+	}
+	if err := json.Unmarshal(blob, &genesis); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %v", err)
+	}
+	if genesis.Config != nil {
+		return genesis.Config, nil
+	}
+	config := new(params.ChainConfig)
+	if err := json.Unmarshal(blob, config); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %v", err)
+	}
+	if config.ChainID == nil {
+		return nil, errors.New("invalid chain config: missing chain id")
+	}
+	return config, nil
+}
+
+// loadWitness reads a witness in the RLP, compact or JSON encoding.
+func loadWitness(path string) (*stateless.Witness, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(blob); len(trimmed) > 0 && trimmed[0] == '{' {
+		var ext stateless.ExecutionWitness
+		if err := json.Unmarshal(trimmed, &ext); err != nil {
+			return nil, fmt.Errorf("invalid witness: %v", err)
+		}
+		return ext.ToWitness()
+	}
+	if blob, err = decodeIfHex(blob); err != nil {
+		return nil, err
+	}
+	var witness *stateless.Witness
+	if len(blob) > 0 && blob[0] == stateless.CompactWitnessVersion {
+		if witness, err = stateless.DecodeCompact(blob); err != nil {
+			return nil, fmt.Errorf("invalid witness: %v", err)
+		}
+	} else {
+		witness = new(stateless.Witness)
+		if err := rlp.DecodeBytes(blob, witness); err != nil {
+			return nil, fmt.Errorf("invalid witness: %v", err)
+		}
+	}
+	if len(witness.Headers) == 0 {
+		return nil, errors.New("invalid witness: no headers")
+	}
+	return witness, nil
+}
+
+// readBinaryOrHex reads a file containing either raw binary data or its hex
+// encoding.
+func readBinaryOrHex(path string) ([]byte, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeIfHex(blob)
+}
+
+// decodeIfHex decodes the data if it is a 0x prefixed hex string, returning it
+// unchanged otherwise.
+func decodeIfHex(blob []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(blob)
+	if !bytes.HasPrefix(trimmed, []byte("0x")) {
+		return blob, nil
+	}
+	return hexutil.Decode(string(trimmed))
+}
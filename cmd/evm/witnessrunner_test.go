@@ -0,0 +1,127 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/cmdtest"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that blocks can be verified from the RLP and JSON witnesses produced by
+// a full node, and that tampered blocks are reported as invalid.
+func TestVerifyWitness(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	block := blocks[2]
+	witness, err := chain.InsertBlockWithoutSetHead(context.Background(), block, true)
+	if err != nil {
+		t.Fatalf("failed to create witness: %v", err)
+	}
+	// Write out all the inputs, in both witness encodings
+	var (
+		dir         = t.TempDir()
+		blockFile   = filepath.Join(dir, "block.rlp")
+		badFile     = filepath.Join(dir, "bad.rlp")
+		rlpWitness  = filepath.Join(dir, "witness.rlp")
+		jsonWitness = filepath.Join(dir, "witness.json")
+		compact     = filepath.Join(dir, "witness.compact")
+		configFile  = filepath.Join(dir, "genesis.json")
+	)
+	blob, _ := rlp.EncodeToBytes(block)
+	os.WriteFile(blockFile, blob, 0644)
+
+	header := block.Header()
+	header.Root = common.Hash{0x01}
+	blob, _ = rlp.EncodeToBytes(block.WithSeal(header))
+	os.WriteFile(badFile, []byte(hexutil.Encode(blob)), 0644)
+
+	blob, _ = rlp.EncodeToBytes(witness)
+	os.WriteFile(rlpWitness, blob, 0644)
+	blob, _ = json.Marshal(witness.ToExecutionWitness())
+	os.WriteFile(jsonWitness, blob, 0644)
+	blob, _ = witness.EncodeCompact(true)
+	os.WriteFile(compact, []byte(hexutil.Encode(blob)), 0644)
+	blob, _ = json.Marshal(gspec)
+	os.WriteFile(configFile, blob, 0644)
+
+	for i, tt := range []struct {
+		block   string
+		witness string
+		valid   bool
+	}{
+		{blockFile, rlpWitness, true},
+		{blockFile, jsonWitness, true},
+		{blockFile, compact, true},
+		{badFile, rlpWitness, false},
+	} {
+		cmd := &testT8n{}
+		cmd.TestCmd = cmdtest.NewTestCmd(t, cmd)
+		cmd.Run("evm-test", "verify-witness", "--block", tt.block, "--witness", tt.witness, "--config", configFile)
+
+		var result witnessResult
+		if err := json.Unmarshal(cmd.Output(), &result); err != nil {
+			t.Fatalf("test %d: invalid output: %v", i, err)
+		}
+		cmd.WaitExit()
+		if result.Valid != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want %v", i, result.Valid, tt.valid)
+		}
+		if result.StateRoot != block.Root() || result.ReceiptsRoot != block.ReceiptHash() {
+			t.Errorf("test %d: computed roots mismatch: have %v/%v, want %v/%v", i, result.StateRoot, result.ReceiptsRoot, block.Root(), block.ReceiptHash())
+		}
+		if tt.valid != (cmd.ExitStatus() == 0) {
+			t.Errorf("test %d: exit status mismatch: have %d", i, cmd.ExitStatus())
+		}
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/execdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -44,6 +45,12 @@ import (
 )
 
 var (
+	historyFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Archive format of the exported history (era1, erae)",
+		Value: "era1",
+	}
+
 	initCommand = &cli.Command{
 		Action:    initGenesis,
 		Name:      "init",
@@ -129,7 +136,7 @@ be gzipped.`,
 	importHistoryCommand = &cli.Command{
 		Action:    importHistory,
 		Name:      "import-history",
-		Usage:     "Import an Era archive",
+		Usage:     "Import Era or post-merge history archives",
 		ArgsUsage: "<dir>",
 		Flags: flags.Merge([]cli.Flag{
 			utils.TxLookupLimitFlag,
@@ -139,7 +146,8 @@ be gzipped.`,
 		),
 		Description: `
 The import-history command will import blocks and their corresponding receipts
-from Era archives.
+from Era archives. Post-merge history archives (.erae) named after the chain are
+imported on top of the current head, pre-merge Era1 archives from genesis.
 `,
 	}
 	exportHistoryCommand = &cli.Command{
@@ -147,10 +155,11 @@ from Era archives.
 		Name:      "export-history",
 		Usage:     "Export blockchain history to Era archives",
 		ArgsUsage: "<dir> <first> <last>",
-		Flags:     flags.Merge(utils.DatabaseFlags),
+		Flags:     flags.Merge([]cli.Flag{historyFormatFlag}, utils.DatabaseFlags),
 		Description: `
 The export-history command will export blocks and their corresponding receipts
-into Era archives. Eras are typically packaged in steps of 8192 blocks.
+into Era archives. Eras are typically packaged in steps of 8192 blocks. The era1
+format holds pre-merge history, the erae format post-merge and OP Stack history.
 `,
 	}
 	importPreimagesCommand = &cli.Command{
@@ -412,6 +421,20 @@ func importHistory(ctx *cli.Context) error {
 		network string
 	)
 
+	// Import post-merge archives of the chain if there are any.
+	execNetwork := utils.ExecHistoryNetwork(chain.Config())
+	entries, err := execdb.ReadDir(dir, execNetwork)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		if err := utils.ImportExecHistory(chain, dir, execNetwork); err != nil {
+			return err
+		}
+		fmt.Printf("Import done in %v\n", time.Since(start))
+		return nil
+	}
+
 	// Determine network.
 	if utils.IsNetworkPreset(ctx) {
 		switch {
@@ -476,7 +499,15 @@ func exportHistory(ctx *cli.Context) error {
 	if head := chain.CurrentSnapBlock(); uint64(last) > head.Number.Uint64() {
 		utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.Number.Uint64())
 	}
-	err := utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size))
+	var err error
+	switch format := ctx.String(historyFormatFlag.Name); format {
+	case "era1":
+		err = utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size))
+	case "erae":
+		err = utils.ExportExecHistory(chain, dir, uint64(first), uint64(last), uint64(execdb.MaxSize))
+	default:
+		utils.Fatalf("Export error: unknown history format %q\n", format)
+	}
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
@@ -37,6 +37,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/health"
+	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
@@ -101,7 +103,8 @@ var deprecatedConfigFields = map[string]bool{
 }
 
 type ethstatsConfig struct {
-	URL string `toml:",omitempty"`
+	URL     string `toml:",omitempty"`
+	OPStats bool   `toml:",omitempty"`
 }
 
 type gethConfig struct {
@@ -109,6 +112,7 @@ type gethConfig struct {
 	Node     node.Config
 	Ethstats ethstatsConfig
 	Metrics  metrics.Config
+	Health   health.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -145,6 +149,7 @@ func loadBaseConfig(ctx *cli.Context) gethConfig {
 		Eth:     ethconfig.Defaults,
 		Node:    defaultNodeConfig(),
 		Metrics: metrics.DefaultConfig,
+		Health:  health.DefaultConfig,
 	}
 
 	// Load config file.
@@ -175,7 +180,11 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if ctx.IsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
 	}
+	if ctx.IsSet(utils.EthStatsOPFlag.Name) {
+		cfg.Ethstats.OPStats = ctx.Bool(utils.EthStatsOPFlag.Name)
+	}
 	applyMetricConfig(ctx, &cfg)
+	applyHealthConfig(ctx, &cfg)
 
 	return stack, cfg
 }
@@ -246,9 +255,16 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
 	}
+	// Serve the health and readiness endpoints if requested.
+	if cfg.Health.Enabled {
+		utils.RegisterHealthService(stack, backend, cfg.Health)
+	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
-		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
+		utils.RegisterEthStatsServiceWithConfig(stack, backend, ethstats.Config{
+			URL:     cfg.Ethstats.URL,
+			OPStats: cfg.Ethstats.OPStats,
+		})
 	}
 	// Configure full-sync tester service if requested
 	if ctx.IsSet(utils.SyncTargetFlag.Name) {
@@ -313,6 +329,27 @@ func dumpConfig(ctx *cli.Context) error {
 	return nil
 }
 
+func applyHealthConfig(ctx *cli.Context, cfg *gethConfig) {
+	if ctx.IsSet(utils.HealthEnabledFlag.Name) {
+		cfg.Health.Enabled = ctx.Bool(utils.HealthEnabledFlag.Name)
+	}
+	if ctx.IsSet(utils.HealthMaxHeadAgeFlag.Name) {
+		cfg.Health.MaxHeadAge = ctx.Duration(utils.HealthMaxHeadAgeFlag.Name)
+	}
+	if ctx.IsSet(utils.HealthMaxSafeLagFlag.Name) {
+		cfg.Health.MaxSafeLag = ctx.Uint64(utils.HealthMaxSafeLagFlag.Name)
+	}
+	if ctx.IsSet(utils.HealthMaxFinalizedLagFlag.Name) {
+		cfg.Health.MaxFinalizedLag = ctx.Uint64(utils.HealthMaxFinalizedLagFlag.Name)
+	}
+	if ctx.IsSet(utils.HealthMinPeersFlag.Name) {
+		cfg.Health.MinPeers = ctx.Int(utils.HealthMinPeersFlag.Name)
+	}
+	if ctx.IsSet(utils.HealthTxPoolAdmissionFlag.Name) {
+		cfg.Health.TxPoolAdmission = ctx.Bool(utils.HealthTxPoolAdmissionFlag.Name)
+	}
+}
+
 func applyMetricConfig(ctx *cli.Context, cfg *gethConfig) {
 	if ctx.IsSet(utils.MetricsEnabledFlag.Name) {
 		cfg.Metrics.Enabled = ctx.Bool(utils.MetricsEnabledFlag.Name)
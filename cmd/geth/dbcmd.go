@@ -17,6 +17,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"os"
@@ -32,8 +33,10 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
@@ -54,6 +57,24 @@ var (
 		Name:  "remove.chain",
 		Usage: "If set, selects the state data for removal",
 	}
+	repairDryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "If set, only reports the mismatching receipts without rewriting them",
+	}
+	recompressCodecFlag = &cli.StringFlag{
+		Name:  "codec",
+		Usage: "Compression codec of the rewritten table (snappy, zstd)",
+		Value: "zstd",
+	}
+	recompressDictFlag = &cli.StringFlag{
+		Name:  "dict",
+		Usage: "File holding the zstd dictionary, e.g. trained with 'zstd --train'",
+	}
+	recompressDictSizeFlag = &cli.IntFlag{
+		Name:  "dict.size",
+		Usage: "Size of the zstd dictionary sampled from the table if no dictionary file is given (0 = no dictionary)",
+		Value: rawdb.DefaultFreezerDictSize,
+	}
 
 	removedbCommand = &cli.Command{
 		Action:    removeDB,
@@ -78,11 +99,14 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbMoveAncientCmd,
+			dbRecompressAncientCmd,
 			dbImportCmd,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbRepairReceiptsCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -178,6 +202,36 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command displays information about the freezer index.",
 	}
+	dbMoveAncientCmd = &cli.Command{
+		Action:    moveAncient,
+		Name:      "move-ancient",
+		Usage:     "Move a freezer table to another directory",
+		ArgsUsage: "<freezer-type> <table-type> <dir>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command relocates the data and index files of a freezer table, e.g. to
+keep rarely read tables like chain receipts on cheaper disks. The files are copied and
+synced before the new location is recorded, so the command can be interrupted safely.
+The node must be stopped. If the placement of the table is configured in AncientTableDirs,
+the configuration must be updated to the new directory.`,
+	}
+	dbRecompressAncientCmd = &cli.Command{
+		Action:    recompressAncient,
+		Name:      "recompress-ancient",
+		Usage:     "Rewrite a compressed freezer table with another codec",
+		ArgsUsage: "<freezer-type> <table-type>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			recompressCodecFlag,
+			recompressDictFlag,
+			recompressDictSizeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rewrites the items of a compressed freezer table with the given codec,
+e.g. zstd with a dictionary for the repetitive receipts and bodies of OP chains. Unless
+a dictionary file is given, a raw dictionary is sampled from the table. The new table is
+written next to the old one, which needs the disk space of both. The node must be stopped.`,
+	}
 	dbImportCmd = &cli.Command{
 		Action:    importLDBdata,
 		Name:      "import",
@@ -229,6 +283,22 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbRepairReceiptsCmd = &cli.Command{
+		Action:    repairReceipts,
+		Name:      "repair-receipts",
+		Usage:     "Re-execute a block range and rewrite the receipts which mismatch the stored ones",
+		ArgsUsage: "<first> <last>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			repairDryRunFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command re-executes the canonical blocks in the given range, compares the
+resulting receipts with the stored ones and rewrites the mismatching receipts, both in
+the key-value store and in the freezer. The state of the parent of each block must be
+available, so an archive node is needed for older ranges. With --dry-run, mismatches
+are only reported. Frozen receipts are repaired by rewriting the receipts table of the
+freezer, which is swapped in once complete.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -627,6 +697,35 @@ func freezerInspect(ctx *cli.Context) error {
 	return rawdb.InspectFreezerTable(ancient, freezer, table, start, end)
 }
 
+func moveAncient(ctx *cli.Context) error {
+	if ctx.NArg() != 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+	return rawdb.MoveFreezerTable(ancient, ctx.Args().Get(0), ctx.Args().Get(1), ctx.Args().Get(2))
+}
+
+func recompressAncient(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var (
+		dict []byte
+		err  error
+	)
+	if file := ctx.String(recompressDictFlag.Name); file != "" {
+		if dict, err = os.ReadFile(file); err != nil {
+			return err
+		}
+	}
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+	return rawdb.RecompressFreezerTable(ancient, ctx.Args().Get(0), ctx.Args().Get(1), ctx.String(recompressCodecFlag.Name), dict, ctx.Int(recompressDictSizeFlag.Name))
+}
+
 func importLDBdata(ctx *cli.Context) error {
 	start := 0
 	switch ctx.NArg() {
@@ -930,3 +1029,95 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+func repairReceipts(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	first, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid first block number: %v", err)
+	}
+	last, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid last block number: %v", err)
+	}
+	if first == 0 || first > last {
+		return fmt.Errorf("invalid block range [%d, %d]", first, last)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+
+	// Open the state of the first block's parent once and carry the post-state
+	// of every executed block over to the next one
+	var statedb *state.StateDB
+	source := func(block *types.Block) (types.Receipts, error) {
+		if statedb == nil {
+			parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+			if parent == nil {
+				return nil, fmt.Errorf("parent block #%d not found", block.NumberU64()-1)
+			}
+			statedb, err = chain.StateAt(parent.Root)
+			if err != nil {
+				return nil, fmt.Errorf("state of parent block #%d not available: %w", parent.Number, err)
+			}
+		}
+		res, err := chain.Processor().Process(block, statedb, vm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		if root := statedb.IntermediateRoot(chain.Config().IsEIP158(block.Number())); root != block.Root() {
+			return nil, fmt.Errorf("state root mismatch of block #%d: have %x, want %x", block.NumberU64(), root, block.Root())
+		}
+		return res.Receipts, nil
+	}
+	// Stage the repaired frozen receipts in a file until the freezer is closed
+	var (
+		dryRun = ctx.Bool(repairDryRunFlag.Name)
+		file   *os.File
+		staged *bufio.Writer
+	)
+	if !dryRun {
+		if file, err = os.CreateTemp(stack.InstanceDir(), "receipts-repair-*"); err != nil {
+			chain.Stop()
+			db.Close()
+			return err
+		}
+		defer os.Remove(file.Name())
+		defer file.Close()
+		staged = bufio.NewWriter(file)
+	}
+	repairs, err := rawdb.RepairReceipts(db, first, last, source, staged, dryRun)
+	if err == nil && !dryRun {
+		err = staged.Flush()
+	}
+	chain.Stop()
+	db.Close()
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		// The frozen receipts can only be rewritten with the freezer closed
+		ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+		if err := rawdb.RewriteAncientReceipts(ancient, file.Name()); err != nil {
+			return err
+		}
+	}
+	for _, repair := range repairs {
+		fmt.Printf("Block #%d [%x]:\n", repair.Number, repair.Hash)
+		for _, diff := range repair.Diffs {
+			fmt.Printf("  %s\n", diff)
+		}
+	}
+	switch {
+	case len(repairs) == 0:
+		fmt.Printf("All receipts of blocks #%d-#%d are correct\n", first, last)
+	case dryRun:
+		fmt.Printf("Found %d blocks with mismatching receipts\n", len(repairs))
+	default:
+		fmt.Printf("Repaired the receipts of %d blocks\n", len(repairs))
+	}
+	return nil
+}
@@ -77,6 +77,7 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolJournalRemotesFlag,
+		utils.TxPoolEventJournalFlag,
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
@@ -146,8 +147,10 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.VMTraceFlag,
 		utils.VMTraceJsonConfigFlag,
+		utils.VMParallelFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.EthStatsOPFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
@@ -159,6 +162,7 @@ var (
 		utils.RollupSequencerTxConditionalCostRateLimitFlag,
 		utils.RollupHistoricalRPCFlag,
 		utils.RollupHistoricalRPCTimeoutFlag,
+		utils.RollupSyncRPCFlag,
 		utils.RollupDisableTxPoolGossipFlag,
 		utils.RollupComputePendingBlock,
 		utils.RollupHaltOnIncompatibleProtocolVersionFlag,
@@ -184,11 +188,18 @@ var (
 		utils.AuthListenFlag,
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
+		utils.AuthMethodLimitsFlag,
 		utils.JWTSecretFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.HealthEnabledFlag,
+		utils.HealthMaxHeadAgeFlag,
+		utils.HealthMaxSafeLagFlag,
+		utils.HealthMaxFinalizedLagFlag,
+		utils.HealthMinPeersFlag,
+		utils.HealthTxPoolAdmissionFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
 		utils.WSEnabledFlag,
@@ -206,6 +217,9 @@ var (
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCMethodLimitsFlag,
+		utils.RPCAccessLogFlag,
+		utils.RPCAccessLogSampleRateFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -223,6 +237,11 @@ var (
 		utils.MetricsInfluxDBTokenFlag,
 		utils.MetricsInfluxDBBucketFlag,
 		utils.MetricsInfluxDBOrganizationFlag,
+		utils.TelemetryFlag,
+		utils.TelemetryEndpointFlag,
+		utils.TelemetryProtocolFlag,
+		utils.TelemetryInsecureFlag,
+		utils.TelemetrySampleRatioFlag,
 	}
 )
 
@@ -374,6 +393,8 @@ func geth(ctx *cli.Context) error {
 	stack := makeFullNode(ctx)
 	defer stack.Close()
 
+	utils.SetupTelemetry(ctx, stack)
+
 	startNode(ctx, stack, false)
 	stack.Wait()
 	return nil
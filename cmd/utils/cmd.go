@@ -43,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/execdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
@@ -487,6 +488,188 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 	return nil
 }
 
+// ExecHistoryNetwork returns the network name of the post-merge history archives
+// of a chain: the name of OP Stack chains or known networks, else the chain ID.
+func ExecHistoryNetwork(config *params.ChainConfig) string {
+	for _, name := range params.OPStackChainNames() {
+		if id, err := params.OPStackChainIDByName(name); err == nil && id == config.ChainID.Uint64() {
+			return name
+		}
+	}
+	if name, ok := params.NetworkNames[config.ChainID.String()]; ok {
+		return name
+	}
+	return config.ChainID.String()
+}
+
+// ImportExecHistory imports post-merge history archives containing historical
+// block information, continuing from the current head of the chain. Blocks
+// already known are skipped.
+func ImportExecHistory(chain *core.BlockChain, dir string, network string) error {
+	entries, err := execdb.ReadDir(dir, network)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	// Archives checksum their own contents, an external list is optional.
+	checksums, err := readList(filepath.Join(dir, "checksums.txt"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to read checksums.txt: %w", err)
+	}
+	if checksums != nil && len(checksums) != len(entries) {
+		return fmt.Errorf("expected equal number of checksums and entries, have: %d checksums, %d entries", len(checksums), len(entries))
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		imported = 0
+		h        = sha256.New()
+	)
+	for i, filename := range entries {
+		err := func() error {
+			f, err := os.Open(filepath.Join(dir, filename))
+			if err != nil {
+				return fmt.Errorf("unable to open archive: %w", err)
+			}
+			defer f.Close()
+
+			if checksums != nil {
+				if _, err := io.Copy(h, f); err != nil {
+					return fmt.Errorf("unable to recalculate checksum: %w", err)
+				}
+				if have, want := common.BytesToHash(h.Sum(nil)).Hex(), checksums[i]; have != want {
+					return fmt.Errorf("checksum mismatch: have %s, want %s", have, want)
+				}
+				h.Reset()
+			}
+			e, err := execdb.From(f)
+			if err != nil {
+				return fmt.Errorf("error opening archive: %w", err)
+			}
+			head := chain.CurrentSnapBlock().Number.Uint64()
+			if e.Start()+e.Count() <= head+1 {
+				return nil // all blocks already known
+			}
+			if err := e.Verify(); err != nil {
+				return fmt.Errorf("invalid archive %s: %w", filename, err)
+			}
+			it, err := execdb.NewIterator(e)
+			if err != nil {
+				return fmt.Errorf("error making archive reader: %w", err)
+			}
+			for it.Next() {
+				if it.Number() <= head {
+					continue
+				}
+				block, receipts, err := it.BlockAndReceipts()
+				if err != nil {
+					return fmt.Errorf("error reading block %d: %w", it.Number(), err)
+				}
+				if status, err := chain.HeaderChain().InsertHeaderChain([]*types.Header{block.Header()}, start); err != nil {
+					return fmt.Errorf("error inserting header %d: %w", it.Number(), err)
+				} else if status != core.CanonStatTy {
+					return fmt.Errorf("error inserting header %d, not canon: %v", it.Number(), status)
+				}
+				// The import may continue from any head, write the blocks to the
+				// live database and leave it to the freezer to move them.
+				if _, err := chain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{receipts}, 0); err != nil {
+					return fmt.Errorf("error inserting body %d: %w", it.Number(), err)
+				}
+				imported += 1
+
+				// Give the user some feedback that something is happening.
+				if time.Since(reported) >= 8*time.Second {
+					log.Info("Importing history archives", "head", it.Number(), "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+					imported = 0
+					reported = time.Now()
+				}
+			}
+			return it.Error()
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportExecHistory exports blockchain history into the specified directory,
+// following the post-merge history archive format.
+func ExportExecHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
+	log.Info("Exporting blockchain history", "dir", dir)
+	if head := bc.CurrentBlock().Number.Uint64(); head < last {
+		log.Warn("Last block beyond head, setting last = head", "head", head, "last", last)
+		last = head
+	}
+	network := ExecHistoryNetwork(bc.Config())
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+	var (
+		start     = time.Now()
+		reported  = time.Now()
+		h         = sha256.New()
+		checksums []string
+	)
+	for i := first; i <= last; i += step {
+		err := func() error {
+			filename := filepath.Join(dir, execdb.Filename(network, int(i/step), common.Hash{}))
+			f, err := os.Create(filename)
+			if err != nil {
+				return fmt.Errorf("could not create archive: %w", err)
+			}
+			defer f.Close()
+
+			w := execdb.NewBuilder(f)
+			for j := uint64(0); j < step && j <= last-i; j++ {
+				var (
+					n     = i + j
+					block = bc.GetBlockByNumber(n)
+				)
+				if block == nil {
+					return fmt.Errorf("export failed on #%d: not found", n)
+				}
+				receipts := bc.GetReceiptsByHash(block.Hash())
+				if receipts == nil {
+					return fmt.Errorf("export failed on #%d: receipts not found", n)
+				}
+				if err := w.Add(block, receipts); err != nil {
+					return err
+				}
+			}
+			checksum, err := w.Finalize()
+			if err != nil {
+				return fmt.Errorf("export failed to finalize %d: %w", i/step, err)
+			}
+			// Set correct filename with checksum.
+			if err := os.Rename(filename, filepath.Join(dir, execdb.Filename(network, int(i/step), checksum))); err != nil {
+				return err
+			}
+			// Compute checksum of entire archive.
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if _, err := io.Copy(h, f); err != nil {
+				return fmt.Errorf("unable to calculate checksum: %w", err)
+			}
+			checksums = append(checksums, common.BytesToHash(h.Sum(nil)).Hex())
+			h.Reset()
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting blocks", "exported", i, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(strings.Join(checksums, "\n")), os.ModePerm); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain to", "dir", dir)
+	return nil
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 // It's a part of the deprecated functionality, should be removed in the future.
 func ImportPreimages(db ethdb.Database, fn string) error {
@@ -53,6 +53,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/eth/health"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
@@ -60,6 +61,7 @@ import (
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
@@ -392,6 +394,11 @@ var (
 		Usage:    "Includes remote transactions in the journal",
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolEventJournalFlag = &cli.StringFlag{
+		Name:     "txpool.eventjournal",
+		Usage:    "Append-only disk journal of all pool add/drop events, replayed in arrival order on restart (sequencer mode)",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolRejournalFlag = &cli.DurationFlag{
 		Name:     "txpool.rejournal",
 		Usage:    "Time interval to regenerate the local transaction journal",
@@ -595,6 +602,11 @@ var (
 		Usage:    "Tracer configuration (JSON)",
 		Category: flags.VMCategory,
 	}
+	VMParallelFlag = &cli.BoolFlag{
+		Name:     "vm.parallel",
+		Usage:    "Execute the transactions of imported blocks optimistically in parallel (experimental)",
+		Category: flags.VMCategory,
+	}
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
@@ -633,11 +645,47 @@ var (
 		Value:    strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	AuthMethodLimitsFlag = &cli.StringFlag{
+		Name:     "authrpc.methodlimits",
+		Usage:    "Per-method call limits on the authenticated endpoint, client quotas keyed by JWT subject (same format as --rpc.methodlimits)",
+		Category: flags.APICategory,
+	}
 	JWTSecretFlag = &flags.DirectoryFlag{
 		Name:     "authrpc.jwtsecret",
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	HealthEnabledFlag = &cli.BoolFlag{
+		Name:     "health",
+		Usage:    "Enable the /health and /ready endpoints on the HTTP-RPC server",
+		Category: flags.APICategory,
+	}
+	HealthMaxHeadAgeFlag = &cli.DurationFlag{
+		Name:     "health.maxheadage",
+		Usage:    "Maximum age of the head block for the node to be ready (0 = no limit)",
+		Value:    health.DefaultConfig.MaxHeadAge,
+		Category: flags.APICategory,
+	}
+	HealthMaxSafeLagFlag = &cli.Uint64Flag{
+		Name:     "health.maxsafelag",
+		Usage:    "Maximum number of blocks the safe head may trail the head for the node to be ready (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	HealthMaxFinalizedLagFlag = &cli.Uint64Flag{
+		Name:     "health.maxfinalizedlag",
+		Usage:    "Maximum number of blocks the finalized head may trail the head for the node to be ready (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	HealthMinPeersFlag = &cli.IntFlag{
+		Name:     "health.minpeers",
+		Usage:    "Minimum number of connected peers for the node to be ready",
+		Category: flags.APICategory,
+	}
+	HealthTxPoolAdmissionFlag = &cli.BoolFlag{
+		Name:     "health.txpooladmission",
+		Usage:    "Require submitted transactions to be admitted to the local pool for the node to be ready",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -645,6 +693,11 @@ var (
 		Usage:    "Reporting URL of a ethstats service (nodename:secret@host:port)",
 		Category: flags.MetricsCategory,
 	}
+	EthStatsOPFlag = &cli.BoolFlag{
+		Name:     "ethstats.op",
+		Usage:    "Report OP stack specific stats (head lags, L1 origin, sequencer forwarding, txpool admission, protocol versions) to the ethstats service",
+		Category: flags.MetricsCategory,
+	}
 	NoCompactionFlag = &cli.BoolFlag{
 		Name:     "nocompaction",
 		Usage:    "Disables db compaction after import",
@@ -790,6 +843,22 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCMethodLimitsFlag = &cli.StringFlag{
+		Name:     "rpc.methodlimits",
+		Usage:    "Per-method call limits on public endpoints, client quotas keyed by IP, e.g. 'debug_*:concurrency=4,timeout=30s;eth_getLogs:rate=5,burst=10'",
+		Category: flags.APICategory,
+	}
+	RPCAccessLogFlag = &cli.StringFlag{
+		Name:     "rpc.accesslog",
+		Usage:    "Write structured JSON-RPC access logs to 'stdout' or a rotated file",
+		Category: flags.APICategory,
+	}
+	RPCAccessLogSampleRateFlag = &cli.Float64Flag{
+		Name:     "rpc.accesslog.samplerate",
+		Usage:    "Fraction of successful calls written to the access log (failed calls are always logged)",
+		Value:    1,
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -939,6 +1008,12 @@ var (
 		Category: flags.RollupCategory,
 	}
 
+	RollupSyncRPCFlag = &cli.StringFlag{
+		Name:     "rollup.syncrpc",
+		Usage:    "Trusted RPC endpoint to sync chain history from alongside p2p peers.",
+		Category: flags.RollupCategory,
+	}
+
 	RollupDisableTxPoolGossipFlag = &cli.BoolFlag{
 		Name:     "rollup.disabletxpoolgossip",
 		Usage:    "Disable transaction pool gossip.",
@@ -1067,6 +1142,36 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	// Tracing settings
+	TelemetryFlag = &cli.BoolFlag{
+		Name:     "telemetry",
+		Usage:    "Enable OpenTelemetry trace export of RPC calls, payload building and block import",
+		Category: flags.MetricsCategory,
+	}
+	TelemetryEndpointFlag = &cli.StringFlag{
+		Name:     "telemetry.endpoint",
+		Usage:    "OTLP collector endpoint to export traces to",
+		Value:    telemetry.DefaultConfig.Endpoint,
+		Category: flags.MetricsCategory,
+	}
+	TelemetryProtocolFlag = &cli.StringFlag{
+		Name:     "telemetry.protocol",
+		Usage:    "OTLP transport protocol of the collector ('http' or 'grpc')",
+		Value:    telemetry.DefaultConfig.Protocol,
+		Category: flags.MetricsCategory,
+	}
+	TelemetryInsecureFlag = &cli.BoolFlag{
+		Name:     "telemetry.insecure",
+		Usage:    "Connect to the OTLP collector without TLS (implied for loopback endpoints)",
+		Category: flags.MetricsCategory,
+	}
+	TelemetrySampleRatioFlag = &cli.Float64Flag{
+		Name:     "telemetry.sampleratio",
+		Usage:    "Fraction of traces started locally to sample (traces continued from callers follow their decision)",
+		Value:    telemetry.DefaultConfig.SampleRatio,
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1276,6 +1381,14 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		cfg.AuthVirtualHosts = SplitAndTrim(ctx.String(AuthVirtualHostsFlag.Name))
 	}
 
+	if ctx.IsSet(AuthMethodLimitsFlag.Name) {
+		limits, err := rpc.ParseMethodLimits(ctx.String(AuthMethodLimitsFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", AuthMethodLimitsFlag.Name, err)
+		}
+		cfg.AuthMethodLimits = limits
+	}
+
 	if ctx.IsSet(HTTPCORSDomainFlag.Name) {
 		cfg.HTTPCors = SplitAndTrim(ctx.String(HTTPCORSDomainFlag.Name))
 	}
@@ -1302,6 +1415,25 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCMethodLimitsFlag.Name) {
+		limits, err := rpc.ParseMethodLimits(ctx.String(RPCMethodLimitsFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RPCMethodLimitsFlag.Name, err)
+		}
+		cfg.RPCMethodLimits = limits
+	}
+
+	if ctx.IsSet(RPCAccessLogFlag.Name) {
+		cfg.RPCAccessLog = ctx.String(RPCAccessLogFlag.Name)
+	}
+	if ctx.IsSet(RPCAccessLogSampleRateFlag.Name) {
+		rate := ctx.Float64(RPCAccessLogSampleRateFlag.Name)
+		if rate < 0 || rate > 1 {
+			Fatalf("Option %q: sample rate must be within [0, 1]", RPCAccessLogSampleRateFlag.Name)
+		}
+		cfg.RPCAccessLogSampleRate = rate
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1529,6 +1661,10 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.EnablePersonal = true
 	}
 
+	if ctx.Bool(TelemetryFlag.Name) {
+		cfg.RPCTracer = telemetry.RPCTracer{}
+	}
+
 	if ctx.IsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.String(ExternalSignerFlag.Name)
 	}
@@ -1641,6 +1777,9 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolJournalRemotesFlag.Name) {
 		cfg.JournalRemote = ctx.Bool(TxPoolJournalRemotesFlag.Name)
 	}
+	if ctx.IsSet(TxPoolEventJournalFlag.Name) {
+		cfg.EventJournal = ctx.String(TxPoolEventJournalFlag.Name)
+	}
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
@@ -1906,6 +2045,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	if ctx.IsSet(VMParallelFlag.Name) {
+		cfg.ParallelExecution = ctx.Bool(VMParallelFlag.Name)
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1941,6 +2083,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RollupHistoricalRPCTimeoutFlag.Name) {
 		cfg.RollupHistoricalRPCTimeout = ctx.Duration(RollupHistoricalRPCTimeoutFlag.Name)
 	}
+	if ctx.IsSet(RollupSyncRPCFlag.Name) {
+		cfg.RollupSyncRPC = ctx.String(RollupSyncRPCFlag.Name)
+	}
 	cfg.RollupDisableTxPoolGossip = ctx.Bool(RollupDisableTxPoolGossipFlag.Name)
 	cfg.RollupDisableTxPoolAdmission = cfg.RollupSequencerHTTP != "" && !ctx.Bool(RollupEnableTxPoolAdmissionFlag.Name)
 	cfg.RollupHaltOnIncompatibleProtocolVersion = ctx.String(RollupHaltOnIncompatibleProtocolVersionFlag.Name)
@@ -2104,11 +2249,24 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (*eth.EthAPIBac
 
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to the node.
 func RegisterEthStatsService(stack *node.Node, backend *eth.EthAPIBackend, url string) {
-	if err := ethstats.New(stack, backend, backend.Engine(), url); err != nil {
+	RegisterEthStatsServiceWithConfig(stack, backend, ethstats.Config{URL: url})
+}
+
+// RegisterEthStatsServiceWithConfig configures the Ethereum Stats daemon with the
+// given settings and adds it to the node.
+func RegisterEthStatsServiceWithConfig(stack *node.Node, backend *eth.EthAPIBackend, cfg ethstats.Config) {
+	if err := ethstats.NewWithConfig(stack, backend, backend.Engine(), cfg); err != nil {
 		Fatalf("Failed to register the Ethereum Stats service: %v", err)
 	}
 }
 
+// RegisterHealthService adds the health and readiness endpoints to the node.
+func RegisterHealthService(stack *node.Node, backend *eth.EthAPIBackend, cfg health.Config) {
+	if _, err := health.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the health service: %v", err)
+	}
+}
+
 // RegisterGraphQLService adds the GraphQL API to the node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cfg *node.Config) {
 	err := graphql.New(stack, backend, filterSystem, cfg.GraphQLCors, cfg.GraphQLVirtualHosts)
@@ -2196,6 +2354,41 @@ func SetupMetrics(ctx *cli.Context) {
 	}
 }
 
+// SetupTelemetry starts exporting traces to an OTLP collector if enabled, and
+// registers the flushing of pending spans on node shutdown.
+func SetupTelemetry(ctx *cli.Context, stack *node.Node) {
+	if !ctx.Bool(TelemetryFlag.Name) {
+		return
+	}
+	config := telemetry.Config{
+		Endpoint:       ctx.String(TelemetryEndpointFlag.Name),
+		Protocol:       ctx.String(TelemetryProtocolFlag.Name),
+		Insecure:       ctx.Bool(TelemetryInsecureFlag.Name),
+		SampleRatio:    ctx.Float64(TelemetrySampleRatioFlag.Name),
+		ServiceName:    telemetry.DefaultConfig.ServiceName,
+		ServiceVersion: params.VersionWithMeta,
+	}
+	shutdown, err := telemetry.Setup(context.Background(), config)
+	if err != nil {
+		Fatalf("Failed to set up telemetry: %v", err)
+	}
+	log.Info("Enabling trace export", "endpoint", config.Endpoint, "protocol", config.Protocol, "ratio", config.SampleRatio)
+	stack.RegisterLifecycle(&telemetryService{shutdown: shutdown})
+}
+
+// telemetryService flushes the pending trace spans when the node stops.
+type telemetryService struct {
+	shutdown func(context.Context) error
+}
+
+func (s *telemetryService) Start() error { return nil }
+
+func (s *telemetryService) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.shutdown(ctx)
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
@@ -2361,6 +2554,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	}
 	vmcfg := vm.Config{
 		EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name),
+		ParallelExecution:       ctx.Bool(VMParallelFlag.Name),
 	}
 	if ctx.IsSet(VMTraceFlag.Name) {
 		if name := ctx.String(VMTraceFlag.Name); name != "" {
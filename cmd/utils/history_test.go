@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/execdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -182,3 +183,65 @@ func TestHistoryImportAndExport(t *testing.T) {
 		t.Fatalf("imported chain does not match expected, have (%d, %s) want (%d, %s)", have.Number, have.Hash(), want.Number, want.Hash())
 	}
 }
+
+func TestExecHistoryImportAndExport(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	db, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), int(count), func(i int, g *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(g.TxNonce(address), common.Address{0xaa}, big.NewInt(int64(i)), 50000, g.BaseFee(), nil), signer, key)
+		g.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+	// Export everything but genesis, as post-merge history doesn't start there.
+	dir := t.TempDir()
+	if err := ExportExecHistory(chain, dir, 1, count, step); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	network := ExecHistoryNetwork(genesis.Config)
+	entries, err := execdb.ReadDir(dir, network)
+	if err != nil {
+		t.Fatalf("error reading archives: %v", err)
+	}
+	if len(entries) != int(count/step) {
+		t.Fatalf("archive count mismatch: have %d, want %d", len(entries), count/step)
+	}
+	// Import into a chain which already has part of the history.
+	db2 := rawdb.NewMemoryDatabase()
+	imported, err := core.NewBlockChain(db2, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer imported.Stop()
+	if _, err := imported.InsertChain(blocks[:40]); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+	if err := ImportExecHistory(imported, dir, network); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	if have, want := imported.CurrentSnapBlock(), chain.CurrentHeader(); have.Hash() != want.Hash() {
+		t.Fatalf("imported chain does not match expected, have (%d, %s) want (%d, %s)", have.Number, have.Hash(), want.Number, want.Hash())
+	}
+	for _, block := range blocks {
+		want := chain.GetReceiptsByHash(block.Hash())
+		have := imported.GetReceiptsByHash(block.Hash())
+		if got := types.DeriveSha(have, trie.NewStackTrie(nil)); got != block.ReceiptHash() {
+			t.Fatalf("receipt root %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), got)
+		}
+		if len(have) != len(want) || have[0].GasUsed != want[0].GasUsed {
+			t.Fatalf("receipts %d mismatch", block.NumberU64())
+		}
+	}
+}
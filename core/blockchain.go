@@ -18,6 +18,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -44,6 +45,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/syncx"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -52,6 +54,7 @@ import (
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -316,7 +319,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	bc.statedb = state.NewDatabase(bc.triedb, nil)
 	bc.validator = NewBlockValidator(chainConfig, bc)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc.hc)
-	bc.processor = NewStateProcessor(chainConfig, bc.hc)
+	if vmConfig.ParallelExecution {
+		bc.processor = NewParallelStateProcessor(chainConfig, bc.hc)
+	} else {
+		bc.processor = NewStateProcessor(chainConfig, bc.hc)
+	}
 
 	bc.genesisBlock = bc.GetBlockByNumber(0)
 	if bc.genesisBlock == nil {
@@ -1604,7 +1611,7 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	}
 	defer bc.chainmu.Unlock()
 
-	_, n, err := bc.insertChain(chain, true, false) // No witness collection for mass inserts (would get super large)
+	_, n, err := bc.insertChain(context.Background(), chain, true, false) // No witness collection for mass inserts (would get super large)
 	return n, err
 }
 
@@ -1616,11 +1623,18 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 // racey behaviour. If a sidechain import is in progress, and the historic state
 // is imported, but then new canon-head is added before the actual sidechain
 // completes, then the historic state could be pruned again
-func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness bool) (*stateless.Witness, int, error) {
+func (bc *BlockChain) insertChain(ctx context.Context, chain types.Blocks, setHead bool, makeWitness bool) (_ *stateless.Witness, _ int, err error) {
 	// If the chain is terminating, don't even bother starting up.
 	if bc.insertStopped() {
 		return nil, 0, nil
 	}
+	ctx, span := telemetry.StartSpan(ctx, "BlockChain.insertChain",
+		attribute.Int("blocks", len(chain)),
+		attribute.Int64("first", chain[0].Number().Int64()),
+		attribute.Bool("sethead", setHead),
+	)
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
 	SenderCacher.RecoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number(), chain[0].Time()), chain)
 
@@ -1821,7 +1835,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 		}
 
 		// The traced section of block import.
-		res, err := bc.processBlock(block, statedb, start, setHead)
+		res, err := bc.processBlock(ctx, block, statedb, start, setHead)
 		followupInterrupt.Store(true)
 		if err != nil {
 			return nil, it.index, err
@@ -1884,7 +1898,14 @@ type blockProcessingResult struct {
 
 // processBlock executes and validates the given block. If there was no error
 // it writes the block and associated state to database.
-func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, start time.Time, setHead bool) (_ *blockProcessingResult, blockEndErr error) {
+func (bc *BlockChain) processBlock(ctx context.Context, block *types.Block, statedb *state.StateDB, start time.Time, setHead bool) (_ *blockProcessingResult, blockEndErr error) {
+	ctx, span := telemetry.StartSpan(ctx, "BlockChain.processBlock",
+		attribute.Int64("number", block.Number().Int64()),
+		attribute.String("hash", block.Hash().Hex()),
+		attribute.Int("txs", len(block.Transactions())),
+	)
+	defer func() { telemetry.EndSpan(span, blockEndErr) }()
+
 	if bc.logger != nil && bc.logger.OnBlockStart != nil {
 		td := bc.GetTd(block.ParentHash(), block.NumberU64()-1)
 		bc.logger.OnBlockStart(tracing.BlockEvent{
@@ -1902,7 +1923,9 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 
 	// Process block using the parent state as reference point
 	pstart := time.Now()
+	_, pspan := telemetry.StartSpan(ctx, "BlockChain.execute")
 	res, err := bc.processor.Process(block, statedb, bc.vmConfig)
+	telemetry.EndSpan(pspan, err)
 	if err != nil {
 		bc.reportBlock(block, res, err)
 		return nil, err
@@ -1910,7 +1933,14 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 	ptime := time.Since(pstart)
 
 	vstart := time.Now()
-	if err := bc.validator.ValidateState(block, statedb, res, false); err != nil {
+	_, vspan := telemetry.StartSpan(ctx, "BlockChain.validate")
+	err = bc.validator.ValidateState(block, statedb, res, false)
+	vspan.SetAttributes(
+		attribute.Int64("account_hash_ns", int64(statedb.AccountHashes)),
+		attribute.Int64("trie_update_ns", int64(statedb.AccountUpdates+statedb.StorageUpdates)),
+	)
+	telemetry.EndSpan(vspan, err)
+	if err != nil {
 		bc.reportBlock(block, res, err)
 		return nil, err
 	}
@@ -1970,12 +2000,14 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 		wstart = time.Now()
 		status WriteStatus
 	)
+	_, wspan := telemetry.StartSpan(ctx, "BlockChain.write")
 	if !setHead {
 		// Don't set the head, only insert the block
 		err = bc.writeBlockWithState(block, res.Receipts, statedb)
 	} else {
 		status, err = bc.writeBlockAndSetHead(block, res.Receipts, res.Logs, statedb, false)
 	}
+	telemetry.EndSpan(wspan, err)
 	if err != nil {
 		return nil, err
 	}
@@ -2091,7 +2123,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator, ma
 		// memory here.
 		if len(blocks) >= 2048 || memory > 64*1024*1024 {
 			log.Info("Importing heavy sidechain segment", "blocks", len(blocks), "start", blocks[0].NumberU64(), "end", block.NumberU64())
-			if _, _, err := bc.insertChain(blocks, true, false); err != nil {
+			if _, _, err := bc.insertChain(context.Background(), blocks, true, false); err != nil {
 				return nil, 0, err
 			}
 			blocks, memory = blocks[:0], 0
@@ -2105,7 +2137,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator, ma
 	}
 	if len(blocks) > 0 {
 		log.Info("Importing sidechain segment", "start", blocks[0].NumberU64(), "end", blocks[len(blocks)-1].NumberU64())
-		return bc.insertChain(blocks, true, makeWitness)
+		return bc.insertChain(context.Background(), blocks, true, makeWitness)
 	}
 	return nil, 0, nil
 }
@@ -2154,7 +2186,7 @@ func (bc *BlockChain) recoverAncestors(block *types.Block, makeWitness bool) (co
 		} else {
 			b = bc.GetBlock(hashes[i], numbers[i])
 		}
-		if _, _, err := bc.insertChain(types.Blocks{b}, false, makeWitness && i == 0); err != nil {
+		if _, _, err := bc.insertChain(context.Background(), types.Blocks{b}, false, makeWitness && i == 0); err != nil {
 			return b.ParentHash(), err
 		}
 	}
@@ -2369,14 +2401,14 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 // upon it and then persist the block and the associate state into the database.
 // The key difference between the InsertChain is it won't do the canonical chain
 // updating. It relies on the additional SetCanonical call to finalize the entire
-// procedure.
-func (bc *BlockChain) InsertBlockWithoutSetHead(block *types.Block, makeWitness bool) (*stateless.Witness, error) {
+// procedure. The context carries the tracing span the import belongs to.
+func (bc *BlockChain) InsertBlockWithoutSetHead(ctx context.Context, block *types.Block, makeWitness bool) (*stateless.Witness, error) {
 	if !bc.chainmu.TryLock() {
 		return nil, errChainStopped
 	}
 	defer bc.chainmu.Unlock()
 
-	witness, _, err := bc.insertChain(types.Blocks{block}, false, makeWitness)
+	witness, _, err := bc.insertChain(ctx, types.Blocks{block}, false, makeWitness)
 	return witness, err
 }
 
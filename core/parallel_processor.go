@@ -0,0 +1,280 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	parallelSpeculatedMeter = metrics.NewRegisteredMeter("chain/parallel/speculated", nil)
+	parallelReexecutedMeter = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+	parallelSequentialMeter = metrics.NewRegisteredMeter("chain/parallel/sequential", nil)
+)
+
+// ParallelStateProcessor is a Processor executing the transactions of a block
+// optimistically in parallel.
+//
+// All transactions are first executed speculatively on independent copies of the
+// state, tracking the state each of them read and wrote. The results are then
+// committed in block order: a transaction whose reads were not modified by the
+// transactions committed before it is merged into the state, any other is
+// executed again on top of the committed state. The produced state, receipts and
+// logs are identical to the ones of sequential execution.
+//
+// ParallelStateProcessor implements Processor.
+type ParallelStateProcessor struct {
+	*StateProcessor
+
+	workers int // Number of transactions executed concurrently
+}
+
+// NewParallelStateProcessor initialises a new ParallelStateProcessor.
+func NewParallelStateProcessor(config *params.ChainConfig, chain *HeaderChain) *ParallelStateProcessor {
+	return &ParallelStateProcessor{
+		StateProcessor: NewStateProcessor(config, chain),
+		workers:        runtime.NumCPU(),
+	}
+}
+
+// speculation is the result of executing a transaction speculatively.
+type speculation struct {
+	tracker *accessTracker
+	result  *ExecutionResult
+	err     error
+}
+
+// Process processes the state changes according to the Ethereum rules by running
+// the transaction messages using the statedb and applying any rewards to both
+// the processor (coinbase) and any included uncles.
+//
+// Blocks the speculative execution can't handle are processed sequentially.
+func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*ProcessResult, error) {
+	// Deposit transactions update the L1 attributes every other transaction reads
+	// outside of the EVM, they must come first and are executed sequentially.
+	txs := block.Transactions()
+	deposits := 0
+	for deposits < len(txs) && txs[deposits].IsDepositTx() {
+		deposits++
+	}
+	if !p.parallelizable(block, statedb, cfg, deposits) {
+		parallelSequentialMeter.Mark(1)
+		return p.StateProcessor.Process(block, statedb, cfg)
+	}
+	var (
+		receipts    types.Receipts
+		usedGas     = new(uint64)
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		allLogs     []*types.Log
+		gp          = new(GasPool).AddGas(block.GasLimit())
+	)
+
+	// Mutate the block and state according to any hard-fork specs
+	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	misc.EnsureCreate2Deployer(p.config, block.Time(), statedb)
+	var (
+		context vm.BlockContext
+		signer  = types.MakeSigner(p.config, header.Number, header.Time)
+		err     error
+	)
+	context = NewEVMBlockContext(header, p.chain, nil, p.config, statedb)
+	vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+	}
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
+	// Convert all the transactions to messages, deferring any error to the point
+	// where sequential execution would hit it.
+	var (
+		msgs    = make([]*Message, len(txs))
+		msgErrs = make([]error, len(txs))
+	)
+	for i, tx := range txs {
+		msgs[i], msgErrs[i] = TransactionToMessage(tx, signer, header.BaseFee)
+	}
+	for i, tx := range txs[:deposits] {
+		if msgErrs[i] != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), msgErrs[i])
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+
+		receipt, err := ApplyTransactionWithEVM(msgs[i], p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	// Execute the remaining transactions speculatively and commit them in order
+	specs := p.speculate(block, statedb, cfg, msgs, msgErrs, deposits)
+
+	written := make(map[accessKey]struct{})
+	for i := deposits; i < len(txs); i++ {
+		tx, msg, spec := txs[i], msgs[i], specs[i]
+		if msgErrs[i] != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), msgErrs[i])
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+
+		var (
+			tracker = spec.tracker
+			result  = spec.result
+		)
+		if spec.err != nil || tracker.destructed || gp.Gas() < msg.GasLimit || tracker.conflicts(written) {
+			// The speculative result can't be used, execute the transaction again
+			parallelReexecutedMeter.Mark(1)
+
+			tracker = newAccessTracker(statedb)
+			vmenv.Reset(NewEVMTxContext(msg), tracker)
+			if result, err = ApplyMessage(vmenv, msg, gp); err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+		} else {
+			tracker.apply(statedb)
+			for _, l := range tracker.GetLogs(tx.Hash(), blockNumber.Uint64(), blockHash) {
+				statedb.AddLog(&types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+			}
+			if cfg.EnablePreimageRecording {
+				for hash, preimage := range tracker.Preimages() {
+					statedb.AddPreimage(hash, preimage)
+				}
+			}
+			gp.SubGas(result.UsedGas)
+			vmenv.Reset(NewEVMTxContext(msg), statedb)
+		}
+		for key := range tracker.writes {
+			written[key] = struct{}{}
+		}
+		statedb.Finalise(true)
+		*usedGas += result.UsedGas
+
+		receipt := MakeReceipt(vmenv, result, statedb, blockNumber, blockHash, tx, *usedGas, nil, p.config, tx.Nonce())
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	// Read requests if Prague is enabled.
+	var requests types.Requests
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		requests, err = ParseDepositLogs(allLogs, p.config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
+	p.chain.engine.Finalize(p.chain, header, statedb, block.Body())
+
+	return &ProcessResult{
+		Receipts: receipts,
+		Requests: requests,
+		Logs:     allLogs,
+		GasUsed:  *usedGas,
+	}, nil
+}
+
+// parallelizable reports whether the transactions of a block can be executed
+// speculatively, or whether sequential execution is needed to exactly reproduce
+// the side effects of the processing.
+func (p *ParallelStateProcessor) parallelizable(block *types.Block, statedb *state.StateDB, cfg vm.Config, deposits int) bool {
+	// Per transaction intermediate roots require sequential execution
+	if !p.config.IsByzantium(block.Number()) {
+		return false
+	}
+	// Tracers, witnesses and verkle access events all observe the execution
+	if cfg.Tracer != nil || statedb.Witness() != nil || statedb.GetTrie().IsVerkle() {
+		return false
+	}
+	// Deposits are only supported at the start of the block
+	txs := block.Transactions()
+	for _, tx := range txs[deposits:] {
+		if tx.IsDepositTx() {
+			return false
+		}
+	}
+	return len(txs)-deposits > 1
+}
+
+// speculate executes the transactions of a block after the given index, each on
+// its own copy of the given state.
+func (p *ParallelStateProcessor) speculate(block *types.Block, statedb *state.StateDB, cfg vm.Config, msgs []*Message, msgErrs []error, start int) []*speculation {
+	var (
+		header = block.Header()
+		txs    = block.Transactions()
+		specs  = make([]*speculation, len(txs))
+		next   = atomic.Int64{}
+		base   = statedb.Copy()
+		lock   sync.Mutex // Protects the base state from concurrent copies
+		wg     sync.WaitGroup
+	)
+	next.Store(int64(start))
+
+	workers := min(p.workers, len(txs)-start)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(txs) {
+					return
+				}
+				if msgErrs[i] != nil {
+					specs[i] = &speculation{err: msgErrs[i]}
+					continue
+				}
+				lock.Lock()
+				db := base.Copy()
+				lock.Unlock()
+
+				db.SetTxContext(txs[i].Hash(), i)
+				var (
+					tracker = newAccessTracker(db)
+					context = NewEVMBlockContext(header, p.chain, nil, p.config, db)
+					evm     = vm.NewEVM(context, NewEVMTxContext(msgs[i]), tracker, p.config, cfg)
+					gp      = new(GasPool).AddGas(block.GasLimit())
+				)
+				result, err := ApplyMessage(evm, msgs[i], gp)
+				if err == nil {
+					db.Finalise(true)
+				}
+				specs[i] = &speculation{tracker: tracker, result: result, err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	parallelSpeculatedMeter.Mark(int64(len(txs) - start))
+	log.Trace("Speculatively executed transactions", "number", block.Number(), "txs", len(txs)-start, "workers", workers)
+	return specs
+}
@@ -0,0 +1,373 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that executing blocks in parallel produces the exact same state, receipts
+// and logs as sequential execution, for a mix of independent and conflicting
+// transactions.
+func TestParallelStateProcessor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		config = *params.MergedTestChainConfig
+		keys   = make([]*ecdsa.PrivateKey, 32)
+		alloc  = make(types.GenesisAlloc)
+
+		// counter increments slot 0 and logs the new value: every call conflicts
+		counter = common.HexToAddress("0xc0")
+		// registry stores the call value under the caller: calls are independent
+		registry = common.HexToAddress("0xc1")
+		// destructor self-destructs to the caller
+		destructor = common.HexToAddress("0xc2")
+		// feeReader stores the balance of the coinbase, which every transaction pays
+		feeReader = common.HexToAddress("0xc3")
+		// empty is an existing empty account, deleted when touched
+		empty = common.HexToAddress("0xe0")
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+	}
+	alloc[counter] = types.Account{Code: common.FromHex("0x600054600101806000556000526020600060a000")}
+	alloc[registry] = types.Account{Code: common.FromHex("0x34335500")}
+	alloc[destructor] = types.Account{Code: common.FromHex("0x33ff"), Balance: big.NewInt(1)}
+	alloc[feeReader] = types.Account{Code: common.FromHex("0x413160005500")}
+	alloc[empty] = types.Account{Balance: common.Big0, Storage: map[common.Hash]common.Hash{}}
+
+	gspec := &Genesis{Config: &config, Alloc: alloc, Difficulty: common.Big0}
+	signer := types.LatestSigner(gspec.Config)
+
+	engine := beacon.New(ethash.NewFaker())
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 8, func(n int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0xc0, 0x1b})
+		for i, key := range keys {
+			sender := crypto.PubkeyToAddress(key.PublicKey)
+			send := func(to *common.Address, value int64, data []byte) {
+				tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+					ChainID:   config.ChainID,
+					Nonce:     b.TxNonce(sender),
+					To:        to,
+					Value:     big.NewInt(value),
+					Gas:       200000,
+					GasFeeCap: b.header.BaseFee,
+					GasTipCap: big.NewInt(int64(i)),
+					Data:      data,
+				})
+				if err != nil {
+					t.Fatalf("failed to sign transaction: %v", err)
+				}
+				b.AddTx(tx)
+			}
+			switch (n + i) % 7 {
+			case 0, 1:
+				// Independent transfers and storage writes
+				fresh := common.Address{byte(n), byte(i), 0xff}
+				send(&fresh, int64(1000+i), nil)
+			case 2:
+				send(&registry, int64(n*len(keys)+i), nil)
+			case 3:
+				// Transactions conflicting with each other or with every fee payment
+				send(&counter, 0, nil)
+			case 4:
+				send(&feeReader, 0, nil)
+			case 5:
+				send(&empty, 0, nil)
+			case 6:
+				if n%2 == 0 {
+					send(&destructor, 0, nil)
+				} else {
+					send(nil, 0, common.FromHex("0x600a600c600039600a6000f3600054600101600055"))
+				}
+			}
+		}
+		// Transfers between senders, conflicting with their own transactions
+		for i := 0; i < len(keys); i += 8 {
+			sender := crypto.PubkeyToAddress(keys[i].PublicKey)
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), crypto.PubkeyToAddress(keys[i+1].PublicKey), big.NewInt(1), params.TxGas, b.header.BaseFee, nil), signer, keys[i])
+			b.AddTx(tx)
+		}
+	})
+	// Import the chain with parallel execution, which validates the produced
+	// state root, receipts root, bloom and gas used.
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{ParallelExecution: true}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, ok := chain.processor.(*ParallelStateProcessor); !ok {
+		t.Fatalf("parallel processor not used: %T", chain.processor)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	// Compare the processing results field by field, including the ones not
+	// covered by consensus
+	sequential := NewStateProcessor(gspec.Config, chain.hc)
+	for _, block := range blocks {
+		parent := chain.GetHeaderByHash(block.ParentHash())
+		seqdb, _ := chain.StateAt(parent.Root)
+		pardb, _ := chain.StateAt(parent.Root)
+
+		want, err := sequential.Process(block, seqdb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: sequential processing failed: %v", block.NumberU64(), err)
+		}
+		have, err := chain.processor.Process(block, pardb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: parallel processing failed: %v", block.NumberU64(), err)
+		}
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if string(haveJSON) != string(wantJSON) {
+			t.Errorf("block %d: result mismatch:\nhave %s\nwant %s", block.NumberU64(), haveJSON, wantJSON)
+		}
+		if have, want := pardb.IntermediateRoot(true), seqdb.IntermediateRoot(true); have != want {
+			t.Errorf("block %d: state root mismatch: have %v, want %v", block.NumberU64(), have, want)
+		}
+	}
+}
+
+// Tests that the speculative results of independent transactions are merged,
+// and only the conflicting ones are executed again.
+func TestParallelStateProcessorConflicts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys     = make([]*ecdsa.PrivateKey, 4)
+		alloc    = make(types.GenesisAlloc)
+		registry = common.HexToAddress("0xc1")
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+	}
+	alloc[registry] = types.Account{Code: common.FromHex("0x34335500")}
+
+	gspec := &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	// Every sender calls the registry twice: the second call fails speculatively,
+	// as it requires the nonce bumped by the first one.
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(n int, b *BlockGen) {
+		for _, key := range keys {
+			sender := crypto.PubkeyToAddress(key.PublicKey)
+			for j := 0; j < 2; j++ {
+				tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), registry, big.NewInt(1), 100000, b.header.BaseFee, nil), signer, key)
+				b.AddTx(tx)
+			}
+		}
+	})
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+	statedb, _ := chain.State()
+
+	var (
+		block = blocks[0]
+		msgs  = make([]*Message, len(block.Transactions()))
+		errs  = make([]error, len(msgs))
+	)
+	for i, tx := range block.Transactions() {
+		msgs[i], errs[i] = TransactionToMessage(tx, signer, block.BaseFee())
+	}
+	specs := NewParallelStateProcessor(gspec.Config, chain.hc).speculate(block, statedb, vm.Config{}, msgs, errs, 0)
+
+	written := make(map[accessKey]struct{})
+	for i, spec := range specs {
+		if i%2 == 1 {
+			if !errors.Is(spec.err, ErrNonceTooHigh) {
+				t.Errorf("tx %d: speculative error mismatch: have %v, want %v", i, spec.err, ErrNonceTooHigh)
+			}
+			continue
+		}
+		if spec.err != nil {
+			t.Fatalf("tx %d: speculative execution failed: %v", i, spec.err)
+		}
+		if spec.tracker.conflicts(written) {
+			t.Errorf("tx %d: independent transaction conflicts", i)
+		}
+		for key := range spec.tracker.writes {
+			written[key] = struct{}{}
+		}
+	}
+}
+
+// Tests that parallel execution reproduces the sequential results on an OP Stack
+// chain, where blocks start with the L1 attributes deposit and user deposits,
+// and the remaining transactions pay the L1 data fee on top of the L2 fees.
+func TestParallelStateProcessorOptimism(t *testing.T) {
+	t.Parallel()
+
+	var (
+		config = *params.MergedTestChainConfig
+		keys   = make([]*ecdsa.PrivateKey, 16)
+		alloc  = make(types.GenesisAlloc)
+
+		// registry stores the call value under the caller: calls are independent
+		registry = common.HexToAddress("0xc1")
+		// counter increments slot 0: every call conflicts
+		counter = common.HexToAddress("0xc0")
+
+		depositor = common.HexToAddress("0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001")
+		zero      = uint64(0)
+		canyon    = uint64(250)
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.BedrockBlock = common.Big0
+	config.RegolithTime = &zero
+	config.CanyonTime = &zero
+	config.EcotoneTime = &zero
+	config.FjordTime = &zero
+	config.Optimism = &params.OptimismConfig{EIP1559Elasticity: 6, EIP1559Denominator: 50, EIP1559DenominatorCanyon: &canyon}
+
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+	}
+	alloc[registry] = types.Account{Code: common.FromHex("0x34335500")}
+	alloc[counter] = types.Account{Code: common.FromHex("0x600054600101600055")}
+
+	// The L1 attributes are stored by a stub of the L1Block predeploy, writing
+	// the L1 base fee and blob base fee from the Ecotone deposit calldata.
+	const baseFeeScalar, blobBaseFeeScalar = 1368, 810949
+
+	var scalars common.Hash
+	binary.BigEndian.PutUint32(scalars[16:20], baseFeeScalar)
+	binary.BigEndian.PutUint32(scalars[20:24], blobBaseFeeScalar)
+	alloc[types.L1BlockAddr] = types.Account{
+		Code:    common.FromHex("0x60243560015560443560075500"),
+		Storage: map[common.Hash]common.Hash{types.L1FeeScalarsSlot: scalars},
+	}
+	gspec := &Genesis{Config: &config, Alloc: alloc, Difficulty: common.Big0}
+	signer := types.LatestSigner(gspec.Config)
+
+	engine := beacon.New(ethash.NewFaker())
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 4, func(n int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0xc0, 0x1b})
+
+		// L1 attributes deposit, followed by user deposits minting funds
+		l1Info := make([]byte, 164)
+		binary.BigEndian.PutUint32(l1Info[4:8], baseFeeScalar)
+		binary.BigEndian.PutUint32(l1Info[8:12], blobBaseFeeScalar)
+		big.NewInt(int64(7 + n)).FillBytes(l1Info[36:68])
+		big.NewInt(int64(1 + n)).FillBytes(l1Info[68:100])
+		b.AddTx(types.NewTx(&types.DepositTx{
+			SourceHash: common.Hash{byte(n), 0x01},
+			From:       depositor,
+			To:         &types.L1BlockAddr,
+			Gas:        1_000_000,
+			Data:       l1Info,
+		}))
+		for i := 0; i < 3; i++ {
+			b.AddTx(types.NewTx(&types.DepositTx{
+				SourceHash: common.Hash{byte(n), 0x02, byte(i)},
+				From:       common.Address{0xd0, byte(i)},
+				To:         &registry,
+				Mint:       big.NewInt(params.Ether),
+				Value:      big.NewInt(int64(1 + i)),
+				Gas:        100_000,
+			}))
+		}
+		// Fee paying transactions, independent ones and conflicting ones
+		for i, key := range keys {
+			to := &registry
+			switch {
+			case i%5 == 0:
+				to = &counter
+			case i%3 == 0:
+				to = &common.Address{byte(n), byte(i), 0xff}
+			}
+			tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   config.ChainID,
+				Nonce:     b.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+				To:        to,
+				Value:     big.NewInt(int64(1000 + i)),
+				Gas:       100_000,
+				GasFeeCap: new(big.Int).Mul(b.header.BaseFee, common.Big2),
+				GasTipCap: big.NewInt(int64(i)),
+				Data:      common.FromHex("0xdeadbeef"),
+			})
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	// Import the chain with parallel execution, which validates the produced
+	// state root, receipts root, bloom and gas used.
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{ParallelExecution: true}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	processor, ok := chain.processor.(*ParallelStateProcessor)
+	if !ok {
+		t.Fatalf("parallel processor not used: %T", chain.processor)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	// Compare the processing results and the collected L1 fees
+	sequential := NewStateProcessor(gspec.Config, chain.hc)
+	for _, block := range blocks {
+		parent := chain.GetHeaderByHash(block.ParentHash())
+		seqdb, _ := chain.StateAt(parent.Root)
+		pardb, _ := chain.StateAt(parent.Root)
+
+		if !processor.parallelizable(block, pardb, vm.Config{}, 4) {
+			t.Fatalf("block %d: not executed in parallel", block.NumberU64())
+		}
+		want, err := sequential.Process(block, seqdb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: sequential processing failed: %v", block.NumberU64(), err)
+		}
+		have, err := processor.Process(block, pardb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: parallel processing failed: %v", block.NumberU64(), err)
+		}
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if string(haveJSON) != string(wantJSON) {
+			t.Errorf("block %d: result mismatch:\nhave %s\nwant %s", block.NumberU64(), haveJSON, wantJSON)
+		}
+		if have, want := pardb.IntermediateRoot(true), seqdb.IntermediateRoot(true); have != want {
+			t.Errorf("block %d: state root mismatch: have %v, want %v", block.NumberU64(), have, want)
+		}
+		if have, want := pardb.GetBalance(params.OptimismL1FeeRecipient), seqdb.GetBalance(params.OptimismL1FeeRecipient); have.Cmp(want) != 0 || have.IsZero() {
+			t.Errorf("block %d: L1 fee vault mismatch: have %v, want %v", block.NumberU64(), have, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
+)
+
+// accessKind is the piece of an account accessed by a transaction.
+type accessKind byte
+
+const (
+	accessExist accessKind = iota
+	accessBalance
+	accessNonce
+	accessCode
+	accessStorage
+	accessStorageRoot
+)
+
+// accessKey identifies a piece of state accessed by a transaction.
+type accessKey struct {
+	addr common.Address
+	kind accessKind
+	slot common.Hash // Only set for storage accesses
+}
+
+// accountWrites tracks the modifications made to an account by a transaction,
+// along with the account state they were made on top of.
+type accountWrites struct {
+	exist    bool                     // Whether the account existed before the transaction
+	empty    bool                     // Whether the account was empty before the transaction
+	balance  *uint256.Int             // Balance of the account before the transaction
+	contract bool                     // Whether the account was turned into a contract
+	nonce    bool                     // Whether the nonce was modified
+	code     bool                     // Whether the code was modified
+	storage  map[common.Hash]struct{} // Storage slots modified
+}
+
+// accessTracker is a vm.StateDB recording the state read and written by a single
+// transaction, used to detect conflicts between speculatively executed ones.
+//
+// Reads are recorded at the granularity of the account fields and storage slots
+// the EVM asks for. Balance changes are recorded as blind writes, which allows
+// transactions paying fees into the same accounts to be executed in parallel.
+type accessTracker struct {
+	*state.StateDB
+
+	reads    map[accessKey]struct{}
+	writes   map[accessKey]struct{}
+	accounts map[common.Address]*accountWrites
+
+	destructed bool // Whether a self-destruct was attempted, which can't be merged
+}
+
+// newAccessTracker wraps a state database to track the accesses made to it.
+func newAccessTracker(db *state.StateDB) *accessTracker {
+	return &accessTracker{
+		StateDB:  db,
+		reads:    make(map[accessKey]struct{}),
+		writes:   make(map[accessKey]struct{}),
+		accounts: make(map[common.Address]*accountWrites),
+	}
+}
+
+// readAccount records reads of the given fields of an account. All account reads
+// depend on the existence of the account.
+func (t *accessTracker) readAccount(addr common.Address, kinds ...accessKind) {
+	t.reads[accessKey{addr: addr, kind: accessExist}] = struct{}{}
+	for _, kind := range kinds {
+		t.reads[accessKey{addr: addr, kind: kind}] = struct{}{}
+	}
+}
+
+// account returns the modification tracker of an account, snapshotting its
+// original state on first access.
+func (t *accessTracker) account(addr common.Address) *accountWrites {
+	if acc, ok := t.accounts[addr]; ok {
+		return acc
+	}
+	acc := &accountWrites{
+		exist:   t.StateDB.Exist(addr),
+		empty:   t.StateDB.Empty(addr),
+		balance: t.StateDB.GetBalance(addr).Clone(),
+	}
+	t.accounts[addr] = acc
+	return acc
+}
+
+// writeAccount records writes to the given fields of an account. Writes to
+// missing or empty accounts are assumed to change their existence, as they may
+// create or delete them.
+func (t *accessTracker) writeAccount(addr common.Address, kinds ...accessKind) *accountWrites {
+	acc := t.account(addr)
+	if !acc.exist || acc.empty {
+		t.writes[accessKey{addr: addr, kind: accessExist}] = struct{}{}
+	}
+	for _, kind := range kinds {
+		t.writes[accessKey{addr: addr, kind: kind}] = struct{}{}
+	}
+	return acc
+}
+
+func (t *accessTracker) CreateAccount(addr common.Address) {
+	t.writeAccount(addr, accessBalance, accessNonce, accessCode, accessStorageRoot)
+	t.StateDB.CreateAccount(addr)
+}
+
+func (t *accessTracker) CreateContract(addr common.Address) {
+	t.writeAccount(addr).contract = true
+	t.StateDB.CreateContract(addr)
+}
+
+func (t *accessTracker) SubBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	t.writeAccount(addr, accessBalance)
+	t.StateDB.SubBalance(addr, amount, reason)
+}
+
+func (t *accessTracker) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	t.writeAccount(addr, accessBalance)
+	t.StateDB.AddBalance(addr, amount, reason)
+}
+
+func (t *accessTracker) GetBalance(addr common.Address) *uint256.Int {
+	t.readAccount(addr, accessBalance)
+	return t.StateDB.GetBalance(addr)
+}
+
+func (t *accessTracker) GetNonce(addr common.Address) uint64 {
+	t.readAccount(addr, accessNonce)
+	return t.StateDB.GetNonce(addr)
+}
+
+func (t *accessTracker) SetNonce(addr common.Address, nonce uint64) {
+	t.writeAccount(addr, accessNonce).nonce = true
+	t.StateDB.SetNonce(addr, nonce)
+}
+
+func (t *accessTracker) GetCodeHash(addr common.Address) common.Hash {
+	t.readAccount(addr, accessCode)
+	return t.StateDB.GetCodeHash(addr)
+}
+
+func (t *accessTracker) GetCode(addr common.Address) []byte {
+	t.readAccount(addr, accessCode)
+	return t.StateDB.GetCode(addr)
+}
+
+func (t *accessTracker) SetCode(addr common.Address, code []byte) {
+	t.writeAccount(addr, accessCode).code = true
+	t.StateDB.SetCode(addr, code)
+}
+
+func (t *accessTracker) GetCodeSize(addr common.Address) int {
+	t.readAccount(addr, accessCode)
+	return t.StateDB.GetCodeSize(addr)
+}
+
+func (t *accessTracker) GetCommittedState(addr common.Address, slot common.Hash) common.Hash {
+	t.reads[accessKey{addr: addr, kind: accessStorage, slot: slot}] = struct{}{}
+	return t.StateDB.GetCommittedState(addr, slot)
+}
+
+func (t *accessTracker) GetState(addr common.Address, slot common.Hash) common.Hash {
+	t.reads[accessKey{addr: addr, kind: accessStorage, slot: slot}] = struct{}{}
+	return t.StateDB.GetState(addr, slot)
+}
+
+func (t *accessTracker) SetState(addr common.Address, slot common.Hash, value common.Hash) {
+	acc := t.account(addr)
+	if !acc.exist || acc.empty {
+		t.writes[accessKey{addr: addr, kind: accessExist}] = struct{}{}
+	}
+	if acc.storage == nil {
+		acc.storage = make(map[common.Hash]struct{})
+	}
+	acc.storage[slot] = struct{}{}
+
+	t.writes[accessKey{addr: addr, kind: accessStorage, slot: slot}] = struct{}{}
+	t.writes[accessKey{addr: addr, kind: accessStorageRoot}] = struct{}{}
+	t.StateDB.SetState(addr, slot, value)
+}
+
+func (t *accessTracker) GetStorageRoot(addr common.Address) common.Hash {
+	t.readAccount(addr, accessStorageRoot)
+	return t.StateDB.GetStorageRoot(addr)
+}
+
+func (t *accessTracker) SelfDestruct(addr common.Address) {
+	t.destructed = true
+	t.writeAccount(addr, accessExist, accessBalance, accessNonce, accessCode, accessStorageRoot)
+	t.StateDB.SelfDestruct(addr)
+}
+
+func (t *accessTracker) Selfdestruct6780(addr common.Address) {
+	t.destructed = true
+	t.writeAccount(addr, accessExist, accessBalance, accessNonce, accessCode, accessStorageRoot)
+	t.StateDB.Selfdestruct6780(addr)
+}
+
+func (t *accessTracker) HasSelfDestructed(addr common.Address) bool {
+	t.readAccount(addr)
+	return t.StateDB.HasSelfDestructed(addr)
+}
+
+func (t *accessTracker) Exist(addr common.Address) bool {
+	t.readAccount(addr)
+	return t.StateDB.Exist(addr)
+}
+
+func (t *accessTracker) Empty(addr common.Address) bool {
+	t.readAccount(addr, accessBalance, accessNonce, accessCode)
+	return t.StateDB.Empty(addr)
+}
+
+// conflicts reports whether the transaction read any of the given state.
+func (t *accessTracker) conflicts(written map[accessKey]struct{}) bool {
+	// Iterate over the smaller set, they are usually vastly different in size
+	if len(t.reads) > len(written) {
+		for key := range written {
+			if _, ok := t.reads[key]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	for key := range t.reads {
+		if _, ok := written[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply replays the modifications of the finalised transaction onto another
+// state database, which must not have had any of the state read by the
+// transaction modified since the tracked one was copied from it.
+//
+// Absolute values are written for everything but balances: those are applied as
+// deltas, as other transactions may have blindly modified them in the meantime.
+func (t *accessTracker) apply(db *state.StateDB) {
+	addrs := make([]common.Address, 0, len(t.accounts))
+	for addr := range t.accounts {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, func(a, b common.Address) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	for _, addr := range addrs {
+		acc := t.accounts[addr]
+		if !t.StateDB.Exist(addr) {
+			// The account was deleted as empty after being touched. Touch it
+			// in the target too, unless it was never there.
+			if acc.exist {
+				db.AddBalance(addr, new(uint256.Int), tracing.BalanceChangeTouchAccount)
+			}
+			continue
+		}
+		if !acc.exist && !db.Exist(addr) {
+			db.CreateAccount(addr)
+		}
+		if acc.contract {
+			db.CreateContract(addr)
+		}
+		if balance := t.StateDB.GetBalance(addr); balance.Gt(acc.balance) {
+			db.AddBalance(addr, new(uint256.Int).Sub(balance, acc.balance), tracing.BalanceChangeUnspecified)
+		} else if balance.Lt(acc.balance) {
+			db.SubBalance(addr, new(uint256.Int).Sub(acc.balance, balance), tracing.BalanceChangeUnspecified)
+		}
+		if acc.nonce {
+			db.SetNonce(addr, t.StateDB.GetNonce(addr))
+		}
+		if acc.code {
+			db.SetCode(addr, t.StateDB.GetCode(addr))
+		}
+		for slot := range acc.storage {
+			db.SetState(addr, slot, t.StateDB.GetState(addr, slot))
+		}
+	}
+}
@@ -18,7 +18,6 @@ package rawdb
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -91,7 +90,7 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 		case MerkleStateFreezerName, VerkleStateFreezerName:
 			datadir, err := db.AncientDatadir()
 			if err != nil {
-				return nil, err
+				continue // state freezers are only inspectable locally
 			}
 			f, err := NewStateFreezer(datadir, freezer == VerkleStateFreezerName, true)
 			if err != nil {
@@ -117,25 +116,13 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 // be opened. Start and end specify the range for dumping out indexes.
 // Note this function can only be used for debugging purposes.
 func InspectFreezerTable(ancient string, freezerName string, tableName string, start, end int64) error {
-	var (
-		path   string
-		tables map[string]bool
-	)
-	switch freezerName {
-	case ChainFreezerName:
-		path, tables = resolveChainFreezerDir(ancient), chainFreezerNoSnappy
-	case MerkleStateFreezerName, VerkleStateFreezerName:
-		path, tables = filepath.Join(ancient, freezerName), stateFreezerNoSnappy
-	default:
-		return fmt.Errorf("unknown freezer, supported ones: %v", freezers)
+	datadir, noSnappy, err := resolveFreezerTable(ancient, freezerName, tableName)
+	if err != nil {
+		return err
 	}
-	noSnappy, exist := tables[tableName]
-	if !exist {
-		var names []string
-		for name := range tables {
-			names = append(names, name)
-		}
-		return fmt.Errorf("unknown table, supported ones: %v", names)
+	path, err := freezerTableDir(datadir, tableName)
+	if err != nil {
+		return err
 	}
 	table, err := newFreezerTable(path, tableName, noSnappy, true)
 	if err != nil {
@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/olekukonko/tablewriter"
+	"golang.org/x/time/rate"
 )
 
 // freezerdb is a database wrapper that enables ancient chain segment freezing.
@@ -354,10 +356,16 @@ type OpenOptions struct {
 	Type              string // "leveldb" | "pebble"
 	Directory         string // the datadir
 	AncientsDirectory string // the ancients-dir
-	Namespace         string // the namespace for database relevant metrics
-	Cache             int    // the capacity(in megabytes) of the data caching
-	Handles           int    // number of files to be open simultaneously
-	ReadOnly          bool
+	// AncientTableDirs places ancient tables outside of the ancients-dir, keyed by
+	// <freezer>/<table>, see PlaceFreezerTables.
+	AncientTableDirs map[string]string
+	// AncientTableCodecs sets the compression codec of ancient tables, keyed by
+	// <freezer>/<table>, see ConfigureFreezerCodecs.
+	AncientTableCodecs map[string]string
+	Namespace          string // the namespace for database relevant metrics
+	Cache              int    // the capacity(in megabytes) of the data caching
+	Handles            int    // number of files to be open simultaneously
+	ReadOnly           bool
 	// Ephemeral means that filesystem sync operations should be avoided: data integrity in the face of
 	// a crash is not important. This option should typically be used in tests.
 	Ephemeral bool
@@ -406,6 +414,18 @@ func Open(o OpenOptions) (ethdb.Database, error) {
 	if len(o.AncientsDirectory) == 0 {
 		return kvdb, nil
 	}
+	if len(o.AncientTableDirs) > 0 {
+		if err := PlaceFreezerTables(o.AncientsDirectory, o.AncientTableDirs, o.ReadOnly); err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+	}
+	if len(o.AncientTableCodecs) > 0 {
+		if err := ConfigureFreezerCodecs(o.AncientsDirectory, o.AncientTableCodecs, o.ReadOnly); err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+	}
 	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
 	if err != nil {
 		kvdb.Close()
@@ -444,16 +464,72 @@ func (s *stat) Count() string {
 	return s.count.String()
 }
 
+// DatabaseStat is the size and item count of a category of database entries.
+type DatabaseStat struct {
+	Database string             `json:"database"`
+	Category string             `json:"category"`
+	Size     common.StorageSize `json:"size"`
+	Count    uint64             `json:"count"`
+}
+
+// DatabaseStats is the result of a database inspection.
+type DatabaseStats struct {
+	Stats       []DatabaseStat     `json:"stats"`
+	Total       common.StorageSize `json:"total"`
+	Unaccounted DatabaseStat       `json:"unaccounted"`
+}
+
+// InspectConfig contains the optional parameters of a database inspection.
+type InspectConfig struct {
+	Prefix    []byte // Key prefix to limit the inspection to
+	Start     []byte // Key to start the inspection from, relative to the prefix
+	RateLimit int    // Maximum number of bytes read per second, 0 for unlimited
+
+	// Progress is invoked periodically with the number of entries inspected
+	// and the last inspected key.
+	Progress func(count int64, key []byte)
+}
+
 // InspectDatabase traverses the entire database and checks the size
 // of all different categories of data.
 func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
-	it := db.NewIterator(keyPrefix, keyStart)
+	stats, err := InspectDatabaseStats(context.Background(), db, &InspectConfig{Prefix: keyPrefix, Start: keyStart})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(stats.Stats))
+	for i, stat := range stats.Stats {
+		rows[i] = []string{stat.Database, stat.Category, stat.Size.String(), fmt.Sprintf("%d", stat.Count)}
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
+	table.SetFooter([]string{"", "Total", stats.Total.String(), " "})
+	table.AppendBulk(rows)
+	table.Render()
+
+	if stats.Unaccounted.Size > 0 {
+		log.Error("Database contains unaccounted data", "size", stats.Unaccounted.Size, "count", stats.Unaccounted.Count)
+	}
+	return nil
+}
+
+// InspectDatabaseStats traverses the database and collects the size of all
+// different categories of data. The traversal only holds an iterator, so it can
+// run against a live database, in which case the rate limit can be used to leave
+// the disk bandwidth to the node.
+func InspectDatabaseStats(ctx context.Context, db ethdb.Database, config *InspectConfig) (*DatabaseStats, error) {
+	it := db.NewIterator(config.Prefix, config.Start)
 	defer it.Release()
 
+	var limiter *rate.Limiter
+	if config.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.RateLimit), config.RateLimit)
+	}
 	var (
-		count  int64
-		start  = time.Now()
-		logged = time.Now()
+		count   int64
+		pending int // Bytes read since the last rate limiter wait
+		start   = time.Now()
+		logged  = time.Now()
 
 		// Key-value store statistics
 		headers         stat
@@ -586,63 +662,90 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			}
 		}
 		count++
-		if count%1000 == 0 && time.Since(logged) > 8*time.Second {
-			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
-			logged = time.Now()
+		pending += int(size)
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for ; limiter != nil && pending > 0; pending -= config.RateLimit {
+				if err := limiter.WaitN(ctx, min(pending, config.RateLimit)); err != nil {
+					return nil, err
+				}
+			}
+			pending = 0
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+				if config.Progress != nil {
+					config.Progress(count, key)
+				}
+				logged = time.Now()
+			}
 		}
 	}
-	// Display the database statistic of key-value store.
-	stats := [][]string{
-		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
-		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
-		{"Key-Value store", "Receipt lists", receipts.Size(), receipts.Count()},
-		{"Key-Value store", "Difficulties", tds.Size(), tds.Count()},
-		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
-		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
-		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
-		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
-		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
-		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
-		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
-		{"Key-Value store", "Path trie account nodes", accountTries.Size(), accountTries.Count()},
-		{"Key-Value store", "Path trie storage nodes", storageTries.Size(), storageTries.Count()},
-		{"Key-Value store", "Verkle trie nodes", verkleTries.Size(), verkleTries.Count()},
-		{"Key-Value store", "Verkle trie state lookups", verkleStateLookups.Size(), verkleStateLookups.Count()},
-		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
-		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
-		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
-		{"Key-Value store", "Beacon sync headers", beaconHeaders.Size(), beaconHeaders.Count()},
-		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
-		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
-		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
-		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	// Gather the database statistic of key-value store.
+	stats := []DatabaseStat{
+		{"Key-Value store", "Headers", headers.size, uint64(headers.count)},
+		{"Key-Value store", "Bodies", bodies.size, uint64(bodies.count)},
+		{"Key-Value store", "Receipt lists", receipts.size, uint64(receipts.count)},
+		{"Key-Value store", "Difficulties", tds.size, uint64(tds.count)},
+		{"Key-Value store", "Block number->hash", numHashPairings.size, uint64(numHashPairings.count)},
+		{"Key-Value store", "Block hash->number", hashNumPairings.size, uint64(hashNumPairings.count)},
+		{"Key-Value store", "Transaction index", txLookups.size, uint64(txLookups.count)},
+		{"Key-Value store", "Bloombit index", bloomBits.size, uint64(bloomBits.count)},
+		{"Key-Value store", "Contract codes", codes.size, uint64(codes.count)},
+		{"Key-Value store", "Hash trie nodes", legacyTries.size, uint64(legacyTries.count)},
+		{"Key-Value store", "Path trie state lookups", stateLookups.size, uint64(stateLookups.count)},
+		{"Key-Value store", "Path trie account nodes", accountTries.size, uint64(accountTries.count)},
+		{"Key-Value store", "Path trie storage nodes", storageTries.size, uint64(storageTries.count)},
+		{"Key-Value store", "Verkle trie nodes", verkleTries.size, uint64(verkleTries.count)},
+		{"Key-Value store", "Verkle trie state lookups", verkleStateLookups.size, uint64(verkleStateLookups.count)},
+		{"Key-Value store", "Trie preimages", preimages.size, uint64(preimages.count)},
+		{"Key-Value store", "Account snapshot", accountSnaps.size, uint64(accountSnaps.count)},
+		{"Key-Value store", "Storage snapshot", storageSnaps.size, uint64(storageSnaps.count)},
+		{"Key-Value store", "Beacon sync headers", beaconHeaders.size, uint64(beaconHeaders.count)},
+		{"Key-Value store", "Clique snapshots", cliqueSnaps.size, uint64(cliqueSnaps.count)},
+		{"Key-Value store", "Singleton metadata", metadata.size, uint64(metadata.count)},
+		{"Light client", "CHT trie nodes", chtTrieNodes.size, uint64(chtTrieNodes.count)},
+		{"Light client", "Bloom trie nodes", bloomTrieNodes.size, uint64(bloomTrieNodes.count)},
 	}
 	// Inspect all registered append-only file store then.
+	ancients, err := InspectFreezers(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancient := range ancients {
+		total += ancient.Size
+	}
+	return &DatabaseStats{
+		Stats:       append(stats, ancients...),
+		Total:       total,
+		Unaccounted: DatabaseStat{"Key-Value store", "Unaccounted", unaccounted.size, uint64(unaccounted.count)},
+	}, nil
+}
+
+// InspectFreezers collects the size and item count of the tables of all the
+// registered freezers. The state freezers can only be inspected if they are not
+// held open by a running node.
+func InspectFreezers(db ethdb.Database) ([]DatabaseStat, error) {
 	ancients, err := inspectFreezers(db)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var stats []DatabaseStat
 	for _, ancient := range ancients {
 		for _, table := range ancient.sizes {
-			stats = append(stats, []string{
-				fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
-				strings.Title(table.name),
-				table.size.String(),
-				fmt.Sprintf("%d", ancient.count()),
+			stats = append(stats, DatabaseStat{
+				Database: fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
+				Category: strings.Title(table.name),
+				Size:     table.size,
+				Count:    ancient.count(),
 			})
 		}
-		total += ancient.size()
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
-	table.SetFooter([]string{"", "Total", total.String(), " "})
-	table.AppendBulk(stats)
-	table.Render()
-
-	if unaccounted.size > 0 {
-		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
-	}
-	return nil
+	return stats, nil
 }
 
 // printChainMetadata prints out chain metadata to stderr.
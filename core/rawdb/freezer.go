@@ -25,6 +25,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -114,9 +115,24 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		instanceLock: lock,
 	}
 
-	// Create the tables.
+	// Create the tables, which may have been relocated to other directories.
 	for name, disableSnappy := range tables {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, readonly)
+		dir, err := freezerTableDir(datadir, name)
+		if err == nil {
+			// Deal with an interrupted rewrite of the table files
+			if !readonly {
+				err = recoverFreezerTableSwap(dir, name)
+			} else if common.FileExist(filepath.Join(dir, name+freezerRewriteBackupSuffix)) {
+				err = fmt.Errorf("freezer table %s has an interrupted rewrite", name)
+			}
+		}
+		if err == nil && dir != datadir && !freezerTableExists(dir, name) {
+			err = fmt.Errorf("freezer table %s missing from %s", name, dir)
+		}
+		var table *freezerTable
+		if err == nil {
+			table, err = newTable(dir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, readonly)
+		}
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
@@ -0,0 +1,483 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/gofrs/flock"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressed freezer tables (the ones with .cidx and .cdat files) encode their
+// items with the codec recorded in the table metadata. Tables without a recorded
+// codec, including all tables written before codecs were introduced, are snappy
+// encoded. The codec of an existing table can only be changed by rewriting it,
+// see RecompressFreezerTable.
+//
+// Zstd encoded tables may carry a dictionary in their metadata, which helps a lot
+// with the small and repetitive items of the freezer, e.g. the receipts of the L1
+// attributes deposit included in every OP block. Both dictionaries trained with
+// 'zstd --train' and raw content dictionaries sampled from the table are supported.
+
+// freezerCodec is the compression codec of a freezer table.
+type freezerCodec uint8
+
+const (
+	freezerCodecSnappy freezerCodec = iota // Default codec of compressed tables
+	freezerCodecZstd
+)
+
+// DefaultFreezerDictSize is the default size of the dictionaries sampled from
+// freezer tables, same as the default of 'zstd --train'.
+const DefaultFreezerDictSize = 112640
+
+// zstdDictMagic is the magic number prefixing dictionaries in the zstd format.
+var zstdDictMagic = []byte{0x37, 0xa4, 0x30, 0xec}
+
+// parseFreezerCodec parses the name of a freezer codec.
+func parseFreezerCodec(name string) (freezerCodec, error) {
+	switch strings.ToLower(name) {
+	case "snappy":
+		return freezerCodecSnappy, nil
+	case "zstd":
+		return freezerCodecZstd, nil
+	default:
+		return 0, fmt.Errorf("unknown freezer codec %q, supported ones: snappy, zstd", name)
+	}
+}
+
+// String implements the fmt.Stringer interface.
+func (c freezerCodec) String() string {
+	switch c {
+	case freezerCodecSnappy:
+		return "snappy"
+	case freezerCodecZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// freezerCompressor encodes and decodes the items of a compressed freezer table.
+// It's safe for concurrent use.
+type freezerCompressor interface {
+	// compress encodes data, reusing the buffer dst if it's large enough.
+	compress(dst, data []byte) []byte
+
+	// decodedLen returns the length of the decoded item.
+	decodedLen(item []byte) (int, error)
+
+	// decompress decodes an item.
+	decompress(item []byte) ([]byte, error)
+
+	// close releases the resources of the compressor.
+	close()
+}
+
+// newFreezerCompressor creates the compressor of the given codec and dictionary.
+func newFreezerCompressor(codec freezerCodec, dict []byte) (freezerCompressor, error) {
+	switch codec {
+	case freezerCodecSnappy:
+		if len(dict) > 0 {
+			return nil, errors.New("snappy doesn't support dictionaries")
+		}
+		return snappyCompressor{}, nil
+	case freezerCodecZstd:
+		return newZstdCompressor(dict)
+	default:
+		return nil, fmt.Errorf("unknown freezer codec %d", codec)
+	}
+}
+
+// snappyCompressor encodes items in the snappy block format.
+type snappyCompressor struct{}
+
+func (snappyCompressor) compress(dst, data []byte) []byte {
+	// The snappy library does not care what the capacity of the buffer is,
+	// but only checks the length. If the length is too small, it will
+	// allocate a brand new buffer.
+	// To avoid that, we check the required size here, and grow the size of the
+	// buffer to utilize the full capacity.
+	if n := snappy.MaxEncodedLen(len(data)); len(dst) < n {
+		if cap(dst) < n {
+			dst = make([]byte, n)
+		}
+		dst = dst[:n]
+	}
+	return snappy.Encode(dst, data)
+}
+
+func (snappyCompressor) decodedLen(item []byte) (int, error) {
+	return snappy.DecodedLen(item)
+}
+
+func (snappyCompressor) decompress(item []byte) ([]byte, error) {
+	return snappy.Decode(nil, item)
+}
+
+func (snappyCompressor) close() {}
+
+// zstdCompressor encodes every item as a separate zstd frame, optionally using
+// a dictionary.
+type zstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCompressor(dict []byte) (*zstdCompressor, error) {
+	var (
+		// Items are encoded one by one while holding the freezer write lock,
+		// a single encoder is enough. Every item, even an empty one, is a frame
+		// recording its decoded size. Checksums are omitted like with snappy,
+		// they would take a considerable share of the small items.
+		encOpts = []zstd.EOption{
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithZeroFrames(true),
+			zstd.WithSingleSegment(true),
+			zstd.WithEncoderCRC(false),
+		}
+		decOpts []zstd.DOption
+	)
+	switch {
+	case len(dict) == 0:
+	case bytes.HasPrefix(dict, zstdDictMagic):
+		encOpts = append(encOpts, zstd.WithEncoderDict(dict))
+		decOpts = append(decOpts, zstd.WithDecoderDicts(dict))
+	default:
+		id := rawDictID(dict)
+		encOpts = append(encOpts, zstd.WithEncoderDictRaw(id, dict))
+		decOpts = append(decOpts, zstd.WithDecoderDictRaw(id, dict))
+	}
+	encoder, err := zstd.NewWriter(nil, encOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zstd dictionary: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil, decOpts...)
+	if err != nil {
+		encoder.Close()
+		return nil, fmt.Errorf("invalid zstd dictionary: %w", err)
+	}
+	return &zstdCompressor{encoder: encoder, decoder: decoder}, nil
+}
+
+// rawDictID derives the id of a raw content dictionary from its hash. The id is
+// recorded in every frame, and falls outside the ranges reserved by the format.
+func rawDictID(dict []byte) uint32 {
+	return binary.BigEndian.Uint32(crypto.Keccak256(dict)[:4])&0x7fffffff | 0x8000
+}
+
+func (z *zstdCompressor) compress(dst, data []byte) []byte {
+	return z.encoder.EncodeAll(data, dst[:0])
+}
+
+func (z *zstdCompressor) decodedLen(item []byte) (int, error) {
+	var header zstd.Header
+	if err := header.Decode(item); err != nil {
+		return 0, err
+	}
+	if !header.HasFCS {
+		return len(item), nil // Not written by us, only used for size estimation
+	}
+	return int(header.FrameContentSize), nil
+}
+
+func (z *zstdCompressor) decompress(item []byte) ([]byte, error) {
+	return z.decoder.DecodeAll(item, nil)
+}
+
+func (z *zstdCompressor) close() {
+	z.encoder.Close()
+	z.decoder.Close()
+}
+
+// ConfigureFreezerCodecs applies the configured codecs of compressed freezer
+// tables in the given ancient root directory. The codecs are keyed by
+// <freezer>/<table>, e.g. chain/receipts. Tables are created with their
+// configured codec, and empty tables are switched to it. Tables already holding
+// items keep their codec, as changing it requires rewriting them with
+// RecompressFreezerTable, which can't run on an open freezer.
+func ConfigureFreezerCodecs(ancient string, codecs map[string]string, readonly bool) error {
+	type config struct {
+		key, table, dir string
+		codec           freezerCodec
+	}
+	var configs []config
+	for key, name := range codecs {
+		freezer, table, ok := strings.Cut(key, "/")
+		if !ok {
+			return fmt.Errorf("invalid freezer table %q, expected <freezer>/<table>", key)
+		}
+		datadir, tables, err := resolveFreezer(ancient, freezer)
+		if err != nil {
+			return err
+		}
+		noSnappy, exist := tables[table]
+		if !exist {
+			return fmt.Errorf("unknown freezer table %q", key)
+		}
+		if noSnappy {
+			return fmt.Errorf("freezer table %s is not compressed", key)
+		}
+		codec, err := parseFreezerCodec(name)
+		if err != nil {
+			return err
+		}
+		dir, err := freezerTableDir(datadir, table)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, config{key: key, table: table, dir: dir, codec: codec})
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	if !readonly {
+		// Pin the location of the chain freezer before creating anything in the
+		// ancient root, which would make it look like a legacy one.
+		if err := os.MkdirAll(resolveChainFreezerDir(ancient), 0755); err != nil {
+			return err
+		}
+	}
+	for _, c := range configs {
+		if readonly && !freezerTableExists(c.dir, c.table) {
+			continue
+		}
+		table, err := newTable(c.dir, c.table, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, false, readonly)
+		if err != nil {
+			return err
+		}
+		switch {
+		case table.codec == c.codec:
+		case !readonly && table.items.Load() == 0:
+			err = table.setCodec(c.codec, nil)
+		default:
+			log.Warn("Freezer table uses a different codec than configured", "table", c.key, "codec", table.codec, "configured", c.codec, "hint", "recompress it with 'geth db recompress-ancient'")
+		}
+		if cerr := table.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecompressFreezerTable rewrites a compressed table of the named freezer in the
+// given ancient root directory with the named codec. For zstd, dict is used as
+// the dictionary if given, either in the zstd format or as raw content. Otherwise
+// a raw dictionary of dictSize bytes is sampled from the table, unless dictSize
+// is zero. Other codecs don't use dictionaries. Items removed from the tail are dropped. The freezer must not be open.
+//
+// The new table is written and synced next to the old one before swapping their
+// files. If the swap is interrupted, the old files are left in a backup directory
+// and must be moved back manually.
+func RecompressFreezerTable(ancient string, freezer string, table string, codec string, dict []byte, dictSize int) error {
+	datadir, noSnappy, err := resolveFreezerTable(ancient, freezer, table)
+	if err != nil {
+		return err
+	}
+	if noSnappy {
+		return fmt.Errorf("freezer table %s/%s is not compressed", freezer, table)
+	}
+	newCodec, err := parseFreezerCodec(codec)
+	if err != nil {
+		return err
+	}
+	if newCodec != freezerCodecZstd && len(dict) > 0 {
+		return fmt.Errorf("codec %s doesn't support dictionaries", newCodec)
+	}
+	// Make sure the freezer is not open while rewriting the table
+	lock := flock.New(filepath.Join(datadir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("freezer is in use")
+	}
+	defer lock.Unlock()
+
+	dir, err := freezerTableDir(datadir, table)
+	if err != nil {
+		return err
+	}
+	if !freezerTableExists(dir, table) {
+		return fmt.Errorf("freezer table %s/%s not found in %s", freezer, table, dir)
+	}
+	var (
+		tmpdir = filepath.Join(dir, table+".recompress")
+		backup = filepath.Join(dir, table+".backup")
+	)
+	if common.FileExist(backup) {
+		return fmt.Errorf("backup directory %s of an interrupted recompression exists", backup)
+	}
+	if err := os.RemoveAll(tmpdir); err != nil {
+		return err
+	}
+	src, err := newTable(dir, table, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, false, true)
+	if err != nil {
+		return err
+	}
+	var (
+		tail  = src.itemHidden.Load()
+		items = src.items.Load()
+	)
+	if newCodec == freezerCodecZstd && len(dict) == 0 && dictSize > 0 {
+		if dict, err = sampleFreezerDict(src, dictSize); err != nil {
+			src.Close()
+			return err
+		}
+	}
+	log.Info("Recompressing freezer table", "table", freezer+"/"+table, "from", src.codec, "to", newCodec, "dict", len(dict), "tail", tail, "items", items)
+	err = recompressFreezerTable(src, tmpdir, newCodec, dict)
+	src.Close()
+	if err != nil {
+		os.RemoveAll(tmpdir)
+		return err
+	}
+	// Swap the files of the old and the new table
+	files, err := freezerTableFiles(dir, table)
+	if err != nil {
+		return err
+	}
+	newFiles, err := freezerTableFiles(tmpdir, table)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(backup, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(dir, file), filepath.Join(backup, file)); err != nil {
+			return err
+		}
+	}
+	for _, file := range newFiles {
+		if err := os.Rename(filepath.Join(tmpdir, file), filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(backup); err != nil {
+		log.Warn("Failed to remove old freezer files", "dir", backup, "err", err)
+	}
+	if err := os.RemoveAll(tmpdir); err != nil {
+		log.Warn("Failed to remove temporary freezer directory", "dir", tmpdir, "err", err)
+	}
+	return nil
+}
+
+// recompressFreezerTable copies the items of the table src after its tail into a
+// new table in dir, which uses the given codec and dictionary.
+func recompressFreezerTable(src *freezerTable, dir string, codec freezerCodec, dict []byte) error {
+	var (
+		tail  = src.itemHidden.Load()
+		items = src.items.Load()
+	)
+	// Start the new table at the tail of the old one. The first index entry holds
+	// the number of items removed from the table.
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	first := indexEntry{filenum: 0, offset: uint32(tail)}
+	if err := os.WriteFile(filepath.Join(dir, src.name+".cidx"), first.append(nil), 0644); err != nil {
+		return err
+	}
+	dst, err := newTable(dir, src.name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, src.maxFileSize, false, false)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if err := dst.setCodec(codec, dict); err != nil {
+		return err
+	}
+	var (
+		batch  = dst.newBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := tail; number < items; {
+		blobs, err := src.RetrieveItems(number, 1024, freezerBatchBufferLimit)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			if err := batch.AppendRaw(number, blob); err != nil {
+				return err
+			}
+			number++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Recompressing freezer table", "table", src.name, "number", number, "items", items, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.commit(); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return err
+	}
+	before, _ := src.size()
+	after, _ := dst.size()
+	log.Info("Recompressed freezer table", "table", src.name, "size", common.StorageSize(before), "recompressed", common.StorageSize(after), "elapsed", common.PrettyDuration(time.Since(start)))
+	return syncDir(dir)
+}
+
+// sampleFreezerDict builds a raw content dictionary of at most size bytes from
+// items evenly spread across the table.
+func sampleFreezerDict(t *freezerTable, size int) ([]byte, error) {
+	var (
+		tail    = t.itemHidden.Load()
+		items   = t.items.Load()
+		samples = uint64(1024)
+		limit   = size/64 + 1 // Cap on the bytes sampled from a single item
+	)
+	if items-tail < samples {
+		samples = items - tail
+	}
+	dict := make([]byte, 0, size)
+	for i := uint64(0); i < samples && len(dict) < size; i++ {
+		blob, err := t.Retrieve(tail + i*(items-tail)/samples)
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) > limit {
+			blob = blob[:limit]
+		}
+		if len(blob) > size-len(dict) {
+			blob = blob[:size-len(dict)]
+		}
+		dict = append(dict, blob...)
+	}
+	return dict, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// codecTestPrefix is the random content shared by the test items.
+var codecTestPrefix = crypto.Keccak512(nil)
+
+// codecTestItem returns an item sharing most of its content with the others,
+// like the receipts of the L1 attributes deposits in OP blocks.
+func codecTestItem(i int) []byte {
+	if i%10 == 0 {
+		return nil
+	}
+	return []byte(fmt.Sprintf("%x-item-%d-%x", codecTestPrefix, i, crypto.Keccak256([]byte{byte(i)})[:i%32]))
+}
+
+// Tests that zstd encoded tables round-trip their items, with and without a
+// dictionary, and keep their codec across reopens.
+func TestFreezerTableZstd(t *testing.T) {
+	for _, dict := range [][]byte{nil, bytes.Repeat([]byte("deposit"), 100)} {
+		dir := t.TempDir()
+		table, err := newFreezerTable(dir, "test", false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := table.setCodec(freezerCodecZstd, dict); err != nil {
+			t.Fatalf("failed to set codec: %v", err)
+		}
+		batch := table.newBatch()
+		for i := 0; i < 200; i++ {
+			if err := batch.AppendRaw(uint64(i), codecTestItem(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := batch.commit(); err != nil {
+			t.Fatal(err)
+		}
+		if err := table.setCodec(freezerCodecSnappy, nil); err == nil {
+			t.Fatalf("changed codec of non-empty table")
+		}
+		table.Close()
+
+		table, err = newFreezerTable(dir, "test", false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if table.codec != freezerCodecZstd || !bytes.Equal(table.dict, dict) {
+			t.Fatalf("codec not persisted: %v, %d bytes dictionary", table.codec, len(table.dict))
+		}
+		for i := 0; i < 200; i++ {
+			blob, err := table.Retrieve(uint64(i))
+			if err != nil || !bytes.Equal(blob, codecTestItem(i)) {
+				t.Fatalf("item %d mismatch: %x, %v", i, blob, err)
+			}
+		}
+		blobs, err := table.RetrieveItems(0, 300, 0)
+		if err != nil || len(blobs) != 200 {
+			t.Fatalf("ranged retrieval mismatch: %d items, %v", len(blobs), err)
+		}
+		table.Close()
+	}
+}
+
+// Tests that recompressing a snappy table preserves its items and tail.
+func TestRecompressFreezerTable(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		datadir = resolveChainFreezerDir(ancient)
+	)
+	f, err := NewFreezer(datadir, "", false, freezerTableSize, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 1000; i++ {
+			for table := range chainFreezerNoSnappy {
+				if err := op.AppendRaw(table, uint64(i), codecTestItem(i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to append items: %v", err)
+	}
+	if _, err := f.TruncateTail(100); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	before, _ := f.tables[ChainFreezerReceiptTable].size()
+
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable, "zstd", nil, 16384); err == nil {
+		t.Fatalf("recompressed table of open freezer")
+	}
+	f.Close()
+
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerHashTable, "zstd", nil, 16384); err == nil {
+		t.Fatalf("recompressed uncompressed table")
+	}
+	if err := RecompressFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable, "zstd", nil, 16384); err != nil {
+		t.Fatalf("failed to recompress table: %v", err)
+	}
+	if files, _ := freezerTableFiles(filepath.Join(datadir, ChainFreezerReceiptTable+".backup"), ChainFreezerReceiptTable); len(files) != 0 {
+		t.Fatalf("leftover backup files: %v", files)
+	}
+	f, err = NewFreezer(datadir, "", false, freezerTableSize, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	defer f.Close()
+
+	table := f.tables[ChainFreezerReceiptTable]
+	if table.codec != freezerCodecZstd || len(table.dict) != 16384 {
+		t.Fatalf("table not recompressed: %v, %d bytes dictionary", table.codec, len(table.dict))
+	}
+	if after, _ := table.size(); after >= before {
+		t.Fatalf("table not shrunk: %d >= %d", after, before)
+	}
+	if tail, _ := f.Tail(); tail != 100 {
+		t.Fatalf("tail mismatch: have %d, want 100", tail)
+	}
+	if _, err := f.Ancient(ChainFreezerReceiptTable, 99); err == nil {
+		t.Fatalf("item below tail retrievable")
+	}
+	for i := 100; i < 1000; i++ {
+		blob, err := f.Ancient(ChainFreezerReceiptTable, uint64(i))
+		if err != nil || !bytes.Equal(blob, codecTestItem(i)) {
+			t.Fatalf("item %d mismatch: %x, %v", i, blob, err)
+		}
+	}
+	// New items are written with the new codec
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for table := range chainFreezerNoSnappy {
+			if err := op.AppendRaw(table, 1000, codecTestItem(1000)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to append items: %v", err)
+	}
+	if blob, err := f.Ancient(ChainFreezerReceiptTable, 1000); err != nil || !bytes.Equal(blob, codecTestItem(1000)) {
+		t.Fatalf("new item mismatch: %x, %v", blob, err)
+	}
+}
+
+// Tests that configured codecs apply to new and empty tables only.
+func TestConfigureFreezerCodecs(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		datadir = resolveChainFreezerDir(ancient)
+	)
+	for _, invalid := range []map[string]string{{"chain/hashes": "zstd"}, {"chain/receipts": "lz4"}, {"chain/unknown": "zstd"}} {
+		if err := ConfigureFreezerCodecs(ancient, invalid, false); err == nil {
+			t.Fatalf("invalid codecs accepted: %v", invalid)
+		}
+	}
+	if err := ConfigureFreezerCodecs(ancient, map[string]string{"chain/receipts": "zstd"}, false); err != nil {
+		t.Fatalf("failed to configure codecs: %v", err)
+	}
+	f, err := NewFreezer(datadir, "", false, freezerTableSize, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	if codec := f.tables[ChainFreezerReceiptTable].codec; codec != freezerCodecZstd {
+		t.Fatalf("codec mismatch: have %v, want zstd", codec)
+	}
+	if codec := f.tables[ChainFreezerBodiesTable].codec; codec != freezerCodecSnappy {
+		t.Fatalf("codec mismatch: have %v, want snappy", codec)
+	}
+	appendChainItems(t, f, 8)
+	f.Close()
+
+	// Tables holding items keep their codec
+	if err := ConfigureFreezerCodecs(ancient, map[string]string{"chain/receipts": "snappy", "chain/bodies": "zstd"}, false); err != nil {
+		t.Fatalf("failed to configure codecs: %v", err)
+	}
+	f, err = NewFreezer(datadir, "", true, freezerTableSize, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	defer f.Close()
+	if codec := f.tables[ChainFreezerReceiptTable].codec; codec != freezerCodecZstd {
+		t.Fatalf("codec mismatch: have %v, want zstd", codec)
+	}
+	checkChainItems(t, f, 8)
+}
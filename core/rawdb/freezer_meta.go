@@ -24,7 +24,10 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
-const freezerVersion = 1 // The initial version tag of freezer table metadata
+const (
+	freezerVersion      = 1 // The initial version tag of freezer table metadata
+	freezerCodecVersion = 2 // The version tag of metadata recording a compression codec
+)
 
 // freezerTableMeta wraps all the metadata of the freezer table.
 type freezerTableMeta struct {
@@ -36,6 +39,13 @@ type freezerTableMeta struct {
 	// plus the number of items hidden in the table, so it should never
 	// be lower than the "actual tail".
 	VirtualTail uint64
+
+	// Codec is the compression codec of the items, only meaningful for
+	// compressed tables. Tables without it are snappy encoded.
+	Codec freezerCodec `rlp:"optional"`
+
+	// Dict is the compression dictionary of the items, if any.
+	Dict []byte `rlp:"optional"`
 }
 
 // newMetadata initializes the metadata object with the given virtual tail.
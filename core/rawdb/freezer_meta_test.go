@@ -19,6 +19,8 @@ package rawdb
 import (
 	"os"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestReadWriteFreezerTableMeta(t *testing.T) {
@@ -60,3 +62,25 @@ func TestInitializeFreezerTableMeta(t *testing.T) {
 		t.Fatalf("Unexpected virtual tail field")
 	}
 }
+
+func TestReadLegacyFreezerTableMeta(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "*")
+	if err != nil {
+		t.Fatalf("Failed to create file %v", err)
+	}
+	defer f.Close()
+	legacy := struct {
+		Version     uint16
+		VirtualTail uint64
+	}{freezerVersion, 100}
+	if err := rlp.Encode(f, &legacy); err != nil {
+		t.Fatalf("Failed to write metadata %v", err)
+	}
+	meta, err := readMetadata(f)
+	if err != nil {
+		t.Fatalf("Failed to read metadata %v", err)
+	}
+	if meta.VirtualTail != uint64(100) || meta.Codec != freezerCodecSnappy || meta.Dict != nil {
+		t.Fatalf("Unexpected metadata %v", meta)
+	}
+}
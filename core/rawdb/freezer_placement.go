@@ -0,0 +1,354 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/gofrs/flock"
+)
+
+// Freezer tables can be stored outside of the freezer directory, e.g. to keep the
+// rarely read ones on cheaper disks. The directory of such a relocated table is
+// recorded in a location file inside the freezer directory, so the freezer can be
+// opened without knowing the placement.
+//
+// A relocated table must always exist in its directory, which is created when the
+// table is placed. A missing one, e.g. on an unmounted disk, aborts opening the
+// freezer instead of silently truncating all tables to zero.
+
+// tableLocationSuffix is the suffix of the file recording the directory of a
+// relocated freezer table.
+const tableLocationSuffix = ".location"
+
+// freezerTableDir returns the directory holding the given table of the freezer
+// in datadir.
+func freezerTableDir(datadir, table string) (string, error) {
+	blob, err := os.ReadFile(filepath.Join(datadir, table+tableLocationSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return datadir, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(blob))
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("invalid location of freezer table %s: %q", table, dir)
+	}
+	return dir, nil
+}
+
+// writeFreezerTableDir records the directory holding the given table of the
+// freezer in datadir.
+func writeFreezerTableDir(datadir, table, dir string) error {
+	path := filepath.Join(datadir, table+tableLocationSuffix)
+	if dir == datadir {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return syncDir(datadir)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(dir+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(datadir)
+}
+
+// freezerTableExists reports whether the index file of a table exists in dir.
+func freezerTableExists(dir, table string) bool {
+	return common.FileExist(filepath.Join(dir, table+".ridx")) || common.FileExist(filepath.Join(dir, table+".cidx"))
+}
+
+// freezerTableFiles returns the index, metadata and data files of a table in dir.
+func freezerTableFiles(dir, table string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, table+".") {
+			continue
+		}
+		switch ext := filepath.Ext(name); ext {
+		case ".ridx", ".cidx", ".meta":
+			if name == table+ext {
+				files = append(files, name)
+			}
+		case ".rdat", ".cdat":
+			// Data files are named <table>.<4 digit number>.<ext>
+			if number := strings.TrimSuffix(strings.TrimPrefix(name, table+"."), ext); len(number) == 4 && strings.Trim(number, "0123456789") == "" {
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// removeFreezerTable deletes the files of a table in dir.
+func removeFreezerTable(dir, table string) error {
+	files, err := freezerTableFiles(dir, table)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveFreezer returns the directory and the tables of the named freezer in the
+// given ancient root directory.
+func resolveFreezer(ancient string, freezer string) (string, map[string]bool, error) {
+	switch freezer {
+	case ChainFreezerName:
+		return resolveChainFreezerDir(ancient), chainFreezerNoSnappy, nil
+	case MerkleStateFreezerName, VerkleStateFreezerName:
+		return filepath.Join(ancient, freezer), stateFreezerNoSnappy, nil
+	default:
+		return "", nil, fmt.Errorf("unknown freezer, supported ones: %v", freezers)
+	}
+}
+
+// resolveFreezerTable is like resolveFreezer, also checking the table name and
+// returning whether compression is disabled for it.
+func resolveFreezerTable(ancient string, freezer string, table string) (string, bool, error) {
+	datadir, tables, err := resolveFreezer(ancient, freezer)
+	if err != nil {
+		return "", false, err
+	}
+	noSnappy, exist := tables[table]
+	if !exist {
+		var names []string
+		for name := range tables {
+			names = append(names, name)
+		}
+		return "", false, fmt.Errorf("unknown table, supported ones: %v", names)
+	}
+	return datadir, noSnappy, nil
+}
+
+// PlaceFreezerTables applies the configured placement of freezer tables in the
+// given ancient root directory. The dirs are keyed by <freezer>/<table>, e.g.
+// chain/receipts. Tables of freezers not created yet are created in their
+// configured directory, while the placement of existing tables is only verified:
+// moving them is left to MoveFreezerTable, which can't run on an open freezer.
+func PlaceFreezerTables(ancient string, dirs map[string]string, readonly bool) error {
+	type placement struct {
+		key, table, datadir, dir string
+		noSnappy                 bool
+	}
+	var (
+		pending []placement
+		fresh   = make(map[string]bool) // Whether the freezers in datadirs are empty
+	)
+	for key, dir := range dirs {
+		freezer, table, ok := strings.Cut(key, "/")
+		if !ok {
+			return fmt.Errorf("invalid freezer table %q, expected <freezer>/<table>", key)
+		}
+		datadir, tables, err := resolveFreezer(ancient, freezer)
+		if err != nil {
+			return err
+		}
+		noSnappy, exist := tables[table]
+		if !exist {
+			return fmt.Errorf("unknown freezer table %q", key)
+		}
+		want, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		have, err := freezerTableDir(datadir, table)
+		if err != nil {
+			return err
+		}
+		switch {
+		case have == want:
+			continue
+		case freezerTableExists(have, table):
+			return fmt.Errorf("freezer table %s is stored in %s instead of the configured %s, relocate it with 'geth db move-ancient'", key, have, want)
+		case have != datadir:
+			return fmt.Errorf("freezer table %s missing from %s", key, have)
+		}
+		// The table doesn't exist yet. It can only be created along with the other
+		// tables of its freezer, as they would be truncated to its length.
+		if _, ok := fresh[datadir]; !ok {
+			fresh[datadir] = true
+			for name := range tables {
+				if dir, err := freezerTableDir(datadir, name); err != nil || freezerTableExists(dir, name) {
+					fresh[datadir] = false
+					break
+				}
+			}
+		}
+		if !fresh[datadir] {
+			return fmt.Errorf("freezer table %s missing from non-empty freezer %s", key, datadir)
+		}
+		if freezerTableExists(want, table) {
+			return fmt.Errorf("freezer table %s has leftover files in %s", key, want)
+		}
+		pending = append(pending, placement{key: key, table: table, datadir: datadir, dir: want, noSnappy: noSnappy})
+	}
+	if readonly || len(pending) == 0 {
+		return nil
+	}
+	// Pin the location of the chain freezer before creating anything in the
+	// ancient root, which would make it look like a legacy one.
+	if err := os.MkdirAll(resolveChainFreezerDir(ancient), 0755); err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if err := os.MkdirAll(p.datadir, 0755); err != nil {
+			return err
+		}
+		table, err := newTable(p.dir, p.table, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, p.noSnappy, false)
+		if err != nil {
+			return err
+		}
+		if err := table.Close(); err != nil {
+			return err
+		}
+		if err := writeFreezerTableDir(p.datadir, p.table, p.dir); err != nil {
+			return err
+		}
+		log.Info("Placed freezer table", "table", p.key, "dir", p.dir)
+	}
+	return nil
+}
+
+// MoveFreezerTable relocates a table of the named freezer in the given ancient
+// root directory to dir. The freezer must not be open. The files are copied and
+// synced before the new location is recorded, so an interrupted move leaves the
+// table intact in its old directory.
+func MoveFreezerTable(ancient string, freezer string, table string, dir string) error {
+	datadir, _, err := resolveFreezerTable(ancient, freezer, table)
+	if err != nil {
+		return err
+	}
+	dest, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	// Make sure the freezer is not open while moving the files
+	lock := flock.New(filepath.Join(datadir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("freezer is in use")
+	}
+	defer lock.Unlock()
+
+	src, err := freezerTableDir(datadir, table)
+	if err != nil {
+		return err
+	}
+	if src == dest {
+		return nil
+	}
+	if !freezerTableExists(src, table) {
+		return fmt.Errorf("freezer table %s/%s not found in %s", freezer, table, src)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	if existing, err := freezerTableFiles(dest, table); err != nil {
+		return err
+	} else if len(existing) > 0 {
+		return fmt.Errorf("destination %s already contains files of table %s, e.g. from an interrupted move: %v", dest, table, existing)
+	}
+	files, err := freezerTableFiles(src, table)
+	if err != nil {
+		return err
+	}
+	log.Info("Copying freezer table", "table", freezer+"/"+table, "from", src, "to", dest, "files", len(files))
+	for _, file := range files {
+		if err := copyFile(filepath.Join(src, file), filepath.Join(dest, file)); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file, err)
+		}
+	}
+	if err := syncDir(dest); err != nil {
+		return err
+	}
+	if err := writeFreezerTableDir(datadir, table, dest); err != nil {
+		return err
+	}
+	// The table has been moved, the old files are leftovers from now on
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(src, file)); err != nil {
+			log.Warn("Failed to remove moved freezer file", "file", filepath.Join(src, file), "err", err)
+		}
+	}
+	log.Info("Moved freezer table", "table", freezer+"/"+table, "dir", dest)
+	return nil
+}
+
+// copyFile copies the file at src to the new file dst and syncs it.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir syncs the directory entries of dir. Directories can't be synced on
+// Windows, where it is a no-op.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// appendChainItems appends n items to all tables of the chain freezer.
+func appendChainItems(t *testing.T, f *Freezer, n int) {
+	t.Helper()
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		frozen, _ := f.Ancients()
+		for i := uint64(0); i < uint64(n); i++ {
+			for table := range chainFreezerNoSnappy {
+				if err := op.AppendRaw(table, frozen+i, bytes.Repeat([]byte{byte(frozen + i)}, 100)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to append items: %v", err)
+	}
+}
+
+// checkChainItems checks that the chain freezer contains the items appended by
+// appendChainItems.
+func checkChainItems(t *testing.T, f *Freezer, n int) {
+	t.Helper()
+
+	if frozen, _ := f.Ancients(); frozen != uint64(n) {
+		t.Fatalf("item count mismatch: have %d, want %d", frozen, n)
+	}
+	for i := 0; i < n; i++ {
+		for table := range chainFreezerNoSnappy {
+			blob, err := f.Ancient(table, uint64(i))
+			if err != nil || !bytes.Equal(blob, bytes.Repeat([]byte{byte(i)}, 100)) {
+				t.Fatalf("table %s item %d mismatch: %x, %v", table, i, blob, err)
+			}
+		}
+	}
+}
+
+func TestMoveFreezerTable(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		cold    = filepath.Join(t.TempDir(), "cold")
+		datadir = resolveChainFreezerDir(ancient)
+	)
+	f, err := NewFreezer(datadir, "", false, 2048, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	appendChainItems(t, f, 64)
+
+	// Moving an open freezer is refused
+	if err := MoveFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable, cold); err == nil {
+		t.Fatalf("moved table of open freezer")
+	}
+	f.Close()
+
+	if err := MoveFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable, cold); err != nil {
+		t.Fatalf("failed to move table: %v", err)
+	}
+	if freezerTableExists(datadir, ChainFreezerReceiptTable) || !freezerTableExists(cold, ChainFreezerReceiptTable) {
+		t.Fatalf("table files not moved")
+	}
+	if files, _ := freezerTableFiles(cold, ChainFreezerReceiptTable); len(files) < 3 {
+		t.Fatalf("data files not moved: %v", files)
+	}
+	// The relocated table is used on reopen, also for writing
+	f, err = NewFreezer(datadir, "", false, 2048, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	appendChainItems(t, f, 16)
+	checkChainItems(t, f, 80)
+	f.Close()
+
+	// A missing relocated table aborts opening the freezer
+	os.Rename(cold, cold+".unmounted")
+	if _, err := NewFreezer(datadir, "", false, 2048, chainFreezerNoSnappy); err == nil {
+		t.Fatalf("opened freezer with missing table")
+	}
+	os.Rename(cold+".unmounted", cold)
+
+	// Move the table back
+	if err := MoveFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable, datadir); err != nil {
+		t.Fatalf("failed to move table back: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(datadir, ChainFreezerReceiptTable+tableLocationSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("location file not removed: %v", err)
+	}
+	f, err = NewFreezer(datadir, "", true, 2048, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	checkChainItems(t, f, 80)
+	f.Close()
+}
+
+func TestPlaceFreezerTables(t *testing.T) {
+	var (
+		ancient = t.TempDir()
+		cold    = filepath.Join(t.TempDir(), "cold")
+		datadir = resolveChainFreezerDir(ancient)
+		dirs    = map[string]string{"chain/receipts": cold, "chain/bodies": cold}
+	)
+	for _, invalid := range []map[string]string{{"receipts": cold}, {"chain/unknown": cold}, {"unknown/receipts": cold}} {
+		if err := PlaceFreezerTables(ancient, invalid, false); err == nil {
+			t.Fatalf("invalid placement accepted: %v", invalid)
+		}
+	}
+	// New tables are created in their configured directory
+	if err := PlaceFreezerTables(ancient, dirs, false); err != nil {
+		t.Fatalf("failed to place tables: %v", err)
+	}
+	f, err := NewFreezer(datadir, "", false, 2048, chainFreezerNoSnappy)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	appendChainItems(t, f, 8)
+	f.Close()
+
+	if !freezerTableExists(cold, ChainFreezerReceiptTable) || !freezerTableExists(cold, ChainFreezerBodiesTable) || freezerTableExists(cold, ChainFreezerHeaderTable) {
+		t.Fatalf("tables not placed")
+	}
+	// The placement is idempotent
+	if err := PlaceFreezerTables(ancient, dirs, false); err != nil {
+		t.Fatalf("failed to verify placement: %v", err)
+	}
+	// Existing tables can't be placed elsewhere without moving them
+	if err := PlaceFreezerTables(ancient, map[string]string{"chain/headers": cold}, false); err == nil {
+		t.Fatalf("existing table placed elsewhere")
+	}
+	if err := PlaceFreezerTables(ancient, map[string]string{"chain/receipts": t.TempDir()}, false); err == nil {
+		t.Fatalf("relocated table placed elsewhere")
+	}
+}
+
+// Tests that resetting a freezer also empties its relocated tables.
+func TestResetRelocatedTable(t *testing.T) {
+	var (
+		datadir = filepath.Join(t.TempDir(), "state")
+		cold    = filepath.Join(t.TempDir(), "cold")
+		tables  = map[string]bool{"a": true, "b": false}
+	)
+	f, err := newResettableFreezer(datadir, "", false, 2048, tables)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	f.Close()
+	if err := os.MkdirAll(cold, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"b.cidx", "b.meta", "b.0000.cdat"} {
+		if err := copyFile(filepath.Join(datadir, file), filepath.Join(cold, file)); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(filepath.Join(datadir, file))
+	}
+	writeFreezerTableDir(datadir, "b", cold)
+
+	f, err = newResettableFreezer(datadir, "", false, 2048, tables)
+	if err != nil {
+		t.Fatalf("failed to reopen freezer: %v", err)
+	}
+	defer f.Close()
+	f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		op.AppendRaw("a", 0, []byte{0x01})
+		op.AppendRaw("b", 0, []byte{0x02})
+		return nil
+	})
+	if err := f.Reset(); err != nil {
+		t.Fatalf("failed to reset freezer: %v", err)
+	}
+	if frozen, _ := f.Ancients(); frozen != 0 {
+		t.Fatalf("freezer not reset: %d items", frozen)
+	}
+	if dir, _ := freezerTableDir(datadir, "b"); dir != cold || !freezerTableExists(cold, "b") {
+		t.Fatalf("relocated table not recreated in place: %s", dir)
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 const tmpSuffix = ".tmp"
@@ -82,6 +83,24 @@ func (f *resettableFreezer) Reset() error {
 	if err := f.freezer.Close(); err != nil {
 		return err
 	}
+	// Empty the relocated tables, which live outside of the directory, and
+	// remember the tables to recreate with their placement or codec.
+	recreate := make(map[string]string)
+	for name, table := range f.freezer.tables {
+		dir, err := freezerTableDir(f.datadir, name)
+		if err != nil {
+			return err
+		}
+		if dir != f.datadir {
+			if err := removeFreezerTable(dir, name); err != nil {
+				return err
+			}
+			recreate[name] = dir
+		}
+		if table.codec != freezerCodecSnappy || len(table.dict) > 0 {
+			recreate[name] = dir
+		}
+	}
 	tmp := tmpName(f.datadir)
 	if err := os.Rename(f.datadir, tmp); err != nil {
 		return err
@@ -89,6 +108,31 @@ func (f *resettableFreezer) Reset() error {
 	if err := os.RemoveAll(tmp); err != nil {
 		return err
 	}
+	// Recreate the relocated tables in their directories and keep the codecs
+	if len(recreate) > 0 {
+		if err := os.MkdirAll(f.datadir, 0755); err != nil {
+			return err
+		}
+		for name, dir := range recreate {
+			old := f.freezer.tables[name]
+			table, err := newTable(dir, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, old.noCompression, false)
+			if err != nil {
+				return err
+			}
+			if !old.noCompression {
+				err = table.setCodec(old.codec, old.dict)
+			}
+			table.Close()
+			if err != nil {
+				return err
+			}
+			if dir != f.datadir {
+				if err := writeFreezerTableDir(f.datadir, name, dir); err != nil {
+					return err
+				}
+			}
+		}
+	}
 	freezer, err := f.opener()
 	if err != nil {
 		return err
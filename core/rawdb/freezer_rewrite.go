@@ -0,0 +1,238 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Freezer tables are append-only, so items below the head can only be changed
+// by copying the table into a new one and swapping their files. The swap first
+// moves the old files into a backup directory, then creates a marker there and
+// finally moves the new files in. An interrupted swap is rolled back or completed
+// when the freezer is opened next, depending on the marker.
+
+const (
+	// freezerRewriteSuffix is appended to the name of a table to get the
+	// directory its rewritten version is built in.
+	freezerRewriteSuffix = ".rewrite"
+
+	// freezerRewriteBackupSuffix is appended to the name of a table to get the
+	// directory its old files are moved to while swapping in the rewritten table.
+	freezerRewriteBackupSuffix = ".rewrite-backup"
+
+	// freezerSwapMarker is created in the backup directory once all the old
+	// files of a table have been moved there.
+	freezerSwapMarker = "SWAPPING"
+)
+
+// freezerItemReplacer returns the content to store instead of the item with the
+// given number while rewriting a table, or nil to keep the stored one. It is
+// called for the items in ascending order.
+type freezerItemReplacer func(number uint64) ([]byte, error)
+
+// openRewrittenTable returns the directory of a table about to be rewritten,
+// after finishing any interrupted swap of its files and discarding the leftovers
+// of an interrupted rewrite.
+func openRewrittenTable(datadir string, freezer string, table string) (string, error) {
+	dir, err := freezerTableDir(datadir, table)
+	if err != nil {
+		return "", err
+	}
+	if err := recoverFreezerTableSwap(dir, table); err != nil {
+		return "", err
+	}
+	if !freezerTableExists(dir, table) {
+		return "", fmt.Errorf("freezer table %s/%s not found in %s", freezer, table, dir)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, table+freezerRewriteSuffix)); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// rewriteFreezerTable copies the items of the table src after its tail into a
+// new table in dir with the same codec, replacing the items the replacer returns
+// content for. The items are copied in bounded chunks.
+func rewriteFreezerTable(src *freezerTable, dir string, replace freezerItemReplacer) error {
+	var (
+		tail  = src.itemHidden.Load()
+		items = src.items.Load()
+	)
+	// Start the new table at the tail of the old one. The first index entry holds
+	// the number of items removed from the table.
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	index := src.name + ".ridx"
+	if !src.noCompression {
+		index = src.name + ".cidx"
+	}
+	first := indexEntry{filenum: 0, offset: uint32(tail)}
+	if err := os.WriteFile(filepath.Join(dir, index), first.append(nil), 0644); err != nil {
+		return err
+	}
+	dst, err := newTable(dir, src.name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, src.maxFileSize, src.noCompression, false)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if !src.noCompression {
+		if err := dst.setCodec(src.codec, src.dict); err != nil {
+			return err
+		}
+	}
+	var (
+		batch  = dst.newBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := tail; number < items; {
+		blobs, err := src.RetrieveItems(number, 1024, freezerBatchBufferLimit)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			fixed, err := replace(number)
+			if err != nil {
+				return err
+			}
+			if fixed != nil {
+				blob = fixed
+			}
+			if err := batch.AppendRaw(number, blob); err != nil {
+				return err
+			}
+			number++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Rewriting freezer table", "table", src.name, "number", number, "items", items, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.commit(); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return err
+	}
+	log.Info("Rewrote freezer table", "table", src.name, "items", items-tail, "elapsed", common.PrettyDuration(time.Since(start)))
+	return syncDir(dir)
+}
+
+// swapFreezerTable replaces the files of the table in dir with the ones of its
+// rewritten version, which must be complete and synced.
+func swapFreezerTable(dir string, table string) error {
+	backup := filepath.Join(dir, table+freezerRewriteBackupSuffix)
+	files, err := freezerTableFiles(dir, table)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(backup, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(dir, file), filepath.Join(backup, file)); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(backup, freezerSwapMarker), nil, 0644); err != nil {
+		return err
+	}
+	if err := syncDir(backup); err != nil {
+		return err
+	}
+	return finishFreezerTableSwap(dir, table)
+}
+
+// finishFreezerTableSwap moves the files of the rewritten table into dir and
+// removes the old ones, the marker being removed last.
+func finishFreezerTableSwap(dir string, table string) error {
+	var (
+		tmpdir = filepath.Join(dir, table+freezerRewriteSuffix)
+		backup = filepath.Join(dir, table+freezerRewriteBackupSuffix)
+	)
+	if common.FileExist(tmpdir) {
+		files, err := freezerTableFiles(tmpdir, table)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := os.Rename(filepath.Join(tmpdir, file), filepath.Join(dir, file)); err != nil {
+				return err
+			}
+		}
+		if err := syncDir(dir); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(tmpdir); err != nil {
+			return err
+		}
+	}
+	files, err := freezerTableFiles(backup, table)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(backup, file)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(backup)
+}
+
+// recoverFreezerTableSwap deals with an interrupted swap of the files of the
+// table in dir. If all the old files were moved to the backup directory, the
+// swap is completed, otherwise the old files are restored.
+func recoverFreezerTableSwap(dir string, table string) error {
+	backup := filepath.Join(dir, table+freezerRewriteBackupSuffix)
+	if !common.FileExist(backup) {
+		return nil
+	}
+	if common.FileExist(filepath.Join(backup, freezerSwapMarker)) {
+		log.Warn("Completing interrupted freezer table rewrite", "table", table, "dir", dir)
+		return finishFreezerTableSwap(dir, table)
+	}
+	log.Warn("Rolling back interrupted freezer table rewrite", "table", table, "dir", dir)
+	files, err := freezerTableFiles(backup, table)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(backup, file), filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(dir, table+freezerRewriteSuffix)); err != nil {
+		return err
+	}
+	return os.RemoveAll(backup)
+}
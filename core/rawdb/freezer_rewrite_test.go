@@ -0,0 +1,113 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Tests that an interrupted swap of rewritten table files is rolled back before
+// all old files were moved away, and completed afterwards.
+func TestFreezerTableSwapRecovery(t *testing.T) {
+	tables := map[string]bool{"test": false}
+	for _, complete := range []bool{false, true} {
+		dir := t.TempDir()
+		f, err := NewFreezer(dir, "", false, freezerTableSize, tables)
+		if err != nil {
+			t.Fatalf("failed to open freezer: %v", err)
+		}
+		_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := 0; i < 100; i++ {
+				if err := op.AppendRaw("test", uint64(i), codecTestItem(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to append items: %v", err)
+		}
+		f.Close()
+
+		// Rewrite the table with a replaced item and interrupt the swap
+		src, err := newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, false, true)
+		if err != nil {
+			t.Fatalf("failed to open table: %v", err)
+		}
+		err = rewriteFreezerTable(src, filepath.Join(dir, "test"+freezerRewriteSuffix), func(number uint64) ([]byte, error) {
+			if number == 5 {
+				return []byte("fixed"), nil
+			}
+			return nil, nil
+		})
+		src.Close()
+		if err != nil {
+			t.Fatalf("failed to rewrite table: %v", err)
+		}
+		files, _ := freezerTableFiles(dir, "test")
+		backup := filepath.Join(dir, "test"+freezerRewriteBackupSuffix)
+		if err := os.Mkdir(backup, 0755); err != nil {
+			t.Fatal(err)
+		}
+		moved := files[:1]
+		if complete {
+			moved = files
+		}
+		for _, file := range moved {
+			if err := os.Rename(filepath.Join(dir, file), filepath.Join(backup, file)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if complete {
+			if err := os.WriteFile(filepath.Join(backup, freezerSwapMarker), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// A read-only freezer must refuse the table, a writable one recovers it
+		if _, err := NewFreezer(dir, "", true, freezerTableSize, tables); err == nil {
+			t.Fatalf("complete %v: opened interrupted table read-only", complete)
+		}
+		f, err = NewFreezer(dir, "", false, freezerTableSize, tables)
+		if err != nil {
+			t.Fatalf("complete %v: failed to reopen freezer: %v", complete, err)
+		}
+		if common.FileExist(backup) || common.FileExist(filepath.Join(dir, "test"+freezerRewriteSuffix)) {
+			t.Errorf("complete %v: leftover directories", complete)
+		}
+		if frozen, _ := f.Ancients(); frozen != 100 {
+			t.Errorf("complete %v: item count mismatch: have %d, want 100", complete, frozen)
+		}
+		want := codecTestItem(5)
+		if complete {
+			want = []byte("fixed")
+		}
+		if blob, err := f.Ancient("test", 5); err != nil || !bytes.Equal(blob, want) {
+			t.Errorf("complete %v: item mismatch: have %q, want %q (%v)", complete, blob, want, err)
+		}
+		if blob, err := f.Ancient("test", 6); err != nil || !bytes.Equal(blob, codecTestItem(6)) {
+			t.Errorf("complete %v: untouched item mismatch: %q, %v", complete, blob, err)
+		}
+		f.Close()
+	}
+}
@@ -30,7 +30,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
-	"github.com/golang/snappy"
 )
 
 var (
@@ -85,7 +84,7 @@ func (i *indexEntry) bounds(end *indexEntry) (startOffset, endOffset, fileId uin
 }
 
 // freezerTable represents a single chained data table within the freezer (e.g. blocks).
-// It consists of a data file (snappy encoded arbitrary data blobs) and an indexEntry
+// It consists of a data file (compressed arbitrary data blobs) and an indexEntry
 // file (uncompressed 64 bit indices into the data file).
 type freezerTable struct {
 	items      atomic.Uint64 // Number of items stored in the table (including items removed from tail)
@@ -98,7 +97,10 @@ type freezerTable struct {
 	// should never be lower than itemOffset.
 	itemHidden atomic.Uint64
 
-	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
+	noCompression bool              // if true, disables compression. Note: does not work retroactively
+	codec         freezerCodec      // Compression codec of the items, recorded in the metadata
+	dict          []byte            // Compression dictionary of the items, recorded in the metadata
+	compressor    freezerCompressor // Encoder of the items, nil if compression is disabled
 	readonly      bool
 	maxFileSize   uint32 // Max file size for data-files
 	name          string
@@ -252,6 +254,11 @@ func (t *freezerTable) repair() error {
 	}
 	t.itemHidden.Store(meta.VirtualTail)
 
+	// Set up the compression of the items
+	if err := t.loadCodec(meta.Codec, meta.Dict); err != nil {
+		return err
+	}
+
 	// Read the last index, use the default value in case the freezer is empty
 	if offsetsSize == indexEntrySize {
 		lastIndex = indexEntry{filenum: t.tailId, offset: 0}
@@ -364,6 +371,53 @@ func (t *freezerTable) repair() error {
 	return nil
 }
 
+// loadCodec sets up the compression of the items with the codec and dictionary
+// recorded in the metadata.
+func (t *freezerTable) loadCodec(codec freezerCodec, dict []byte) error {
+	if t.noCompression {
+		if codec != freezerCodecSnappy || len(dict) > 0 {
+			return fmt.Errorf("codec %s recorded for uncompressed table", codec)
+		}
+		return nil
+	}
+	compressor, err := newFreezerCompressor(codec, dict)
+	if err != nil {
+		return err
+	}
+	if t.compressor != nil {
+		t.compressor.close()
+	}
+	t.codec, t.dict, t.compressor = codec, dict, compressor
+	return nil
+}
+
+// setCodec changes the codec and dictionary of a table without stored items.
+func (t *freezerTable) setCodec(codec freezerCodec, dict []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.items.Load() != t.itemOffset.Load() {
+		return errors.New("codec of non-empty table can't be changed")
+	}
+	if err := t.loadCodec(codec, dict); err != nil {
+		return err
+	}
+	if err := writeMetadata(t.meta, t.metadata(t.itemHidden.Load())); err != nil {
+		return err
+	}
+	return t.meta.Sync()
+}
+
+// metadata returns the metadata of the table with the given virtual tail.
+func (t *freezerTable) metadata(tail uint64) *freezerTableMeta {
+	meta := newMetadata(tail)
+	if t.codec != freezerCodecSnappy || len(t.dict) > 0 {
+		meta.Version = freezerCodecVersion
+		meta.Codec, meta.Dict = t.codec, t.dict
+	}
+	return meta
+}
+
 // preopen opens all files that the freezer will need. This method should be called from an init-context,
 // since it assumes that it doesn't have to bother with locking
 // The rationale for doing preopen is to not have to do it from within Retrieve, thus not needing to ever
@@ -517,7 +571,7 @@ func (t *freezerTable) truncateTail(items uint64) error {
 	}
 	// Update the virtual tail marker and hidden these entries in table.
 	t.itemHidden.Store(items)
-	if err := writeMetadata(t.meta, newMetadata(items)); err != nil {
+	if err := writeMetadata(t.meta, t.metadata(items)); err != nil {
 		return err
 	}
 	// Hidden items still fall in the current tail file, no data file
@@ -625,6 +679,10 @@ func (t *freezerTable) Close() error {
 	t.meta = nil
 	t.head = nil
 
+	if t.compressor != nil {
+		t.compressor.close()
+	}
+
 	if errs != nil {
 		return fmt.Errorf("%v", errs)
 	}
@@ -752,14 +810,14 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
 		decompressedSize := diskSize
-		if !t.noCompression {
-			decompressedSize, _ = snappy.DecodedLen(item)
+		if t.compressor != nil {
+			decompressedSize, _ = t.compressor.decodedLen(item)
 		}
 		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
 			break
 		}
-		if !t.noCompression {
-			data, err := snappy.Decode(nil, item)
+		if t.compressor != nil {
+			data, err := t.compressor.decompress(item)
 			if err != nil {
 				return nil, err
 			}
@@ -969,6 +1027,9 @@ func (t *freezerTable) dumpIndex(w io.Writer, start, stop int64) {
 	}
 	fmt.Fprintf(w, "Version %d count %d, deleted %d, hidden %d\n", meta.Version,
 		t.items.Load(), t.itemOffset.Load(), t.itemHidden.Load())
+	if !t.noCompression {
+		fmt.Fprintf(w, "Codec %v, dictionary %d bytes\n", t.codec, len(t.dict))
+	}
 
 	buf := make([]byte, indexEntrySize)
 
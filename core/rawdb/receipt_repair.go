@@ -0,0 +1,345 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/gofrs/flock"
+)
+
+// ReceiptSource produces the correct receipts of a canonical block, e.g. by
+// executing it again. It is called for every block of the repaired range in
+// ascending order, so it may carry the post-state of a block over to the next.
+type ReceiptSource func(block *types.Block) (types.Receipts, error)
+
+// ReceiptRepair describes a block whose stored receipts differ from the ones
+// produced by the receipt source.
+type ReceiptRepair struct {
+	Number uint64
+	Hash   common.Hash
+	Diffs  []string // Human readable differences of the stored receipts
+	Frozen bool     // Whether the receipts are in the freezer, see RewriteAncientReceipts
+}
+
+// stagedReceipts holds the correct receipts of a frozen block, staged by
+// RepairReceipts until the freezer is rewritten.
+type stagedReceipts struct {
+	Number   uint64
+	Hash     common.Hash
+	Receipts []byte // Receipts in their storage encoding
+}
+
+// RepairReceipts compares the stored receipts of the canonical blocks in the
+// range [first, last] with the ones produced by the source, and unless dryRun is
+// set, rewrites the mismatching ones. Blocks without stored receipts are skipped.
+//
+// Receipts in the key-value store are overwritten in place, block by block.
+// Frozen receipts can't be changed while the freezer is open: their correct
+// version is written to staged instead, for RewriteAncientReceipts to replace
+// them with once the database is closed.
+func RepairReceipts(db ethdb.Database, first, last uint64, source ReceiptSource, staged io.Writer, dryRun bool) ([]*ReceiptRepair, error) {
+	frozen, err := db.Ancients()
+	if err != nil {
+		frozen = 0 // No freezer
+	}
+	var (
+		repairs []*ReceiptRepair
+		batch   = db.NewBatch()
+		start   = time.Now()
+		logged  = time.Now()
+	)
+	for number := first; number <= last; number++ {
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return nil, fmt.Errorf("canonical block #%d not found", number)
+		}
+		block := ReadBlock(db, hash, number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d [%x] not found", number, hash)
+		}
+		receipts, err := source(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to produce receipts of block #%d: %w", number, err)
+		}
+		stored := ReadRawReceipts(db, hash, number)
+		if stored == nil {
+			log.Debug("Skipping block without receipts", "number", number, "hash", hash)
+		} else if diffs := diffReceipts(stored, receipts); len(diffs) > 0 {
+			repair := &ReceiptRepair{Number: number, Hash: hash, Diffs: diffs, Frozen: number < frozen}
+			repairs = append(repairs, repair)
+
+			switch {
+			case dryRun:
+			case repair.Frozen:
+				blob, err := encodeStorageReceipts(receipts)
+				if err != nil {
+					return nil, err
+				}
+				if err := rlp.Encode(staged, &stagedReceipts{Number: number, Hash: hash, Receipts: blob}); err != nil {
+					return nil, fmt.Errorf("failed to stage receipts of block #%d: %w", number, err)
+				}
+			default:
+				WriteReceipts(batch, hash, number, receipts)
+				if batch.ValueSize() >= ethdb.IdealBatchSize {
+					if err := batch.Write(); err != nil {
+						return nil, fmt.Errorf("failed to write receipts: %w", err)
+					}
+					batch.Reset()
+				}
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Checking receipts", "number", number, "last", last, "mismatches", len(repairs), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		if number == last {
+			break // Avoid overflows at the uint64 limit
+		}
+	}
+	if dryRun || len(repairs) == 0 {
+		return repairs, nil
+	}
+	if err := batch.Write(); err != nil {
+		return nil, fmt.Errorf("failed to write receipts: %w", err)
+	}
+	log.Info("Repaired receipts", "blocks", len(repairs), "elapsed", common.PrettyDuration(time.Since(start)))
+	return repairs, nil
+}
+
+// RewriteAncientReceipts replaces the frozen receipts staged by RepairReceipts
+// in the file staged, in the chain freezer of the ancient root directory, which
+// must not be open.
+//
+// The receipts table is copied in bounded chunks into a new table with the
+// repaired receipts, which is swapped in once complete. Interrupting the rewrite
+// leaves the freezer untouched, while an interrupted swap of the table files is
+// rolled back or completed the next time the freezer is opened.
+func RewriteAncientReceipts(ancient string, staged string) error {
+	datadir, _, err := resolveFreezerTable(ancient, ChainFreezerName, ChainFreezerReceiptTable)
+	if err != nil {
+		return err
+	}
+	// Make sure the freezer is not open while rewriting the table
+	lock := flock.New(filepath.Join(datadir, "FLOCK"))
+	if locked, err := lock.TryLock(); err != nil {
+		return err
+	} else if !locked {
+		return errors.New("freezer is in use")
+	}
+	defer lock.Unlock()
+
+	repairs, err := checkStagedReceipts(datadir, staged)
+	if err != nil || repairs == 0 {
+		return err
+	}
+	dir, err := openRewrittenTable(datadir, ChainFreezerName, ChainFreezerReceiptTable)
+	if err != nil {
+		return err
+	}
+	src, err := newTable(dir, ChainFreezerReceiptTable, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, chainFreezerNoSnappy[ChainFreezerReceiptTable], true)
+	if err != nil {
+		return err
+	}
+	log.Info("Rewriting ancient receipts", "tail", src.itemHidden.Load(), "items", src.items.Load(), "repairs", repairs)
+
+	reader, err := openStagedReceipts(staged)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	tmpdir := filepath.Join(dir, ChainFreezerReceiptTable+freezerRewriteSuffix)
+	err = rewriteFreezerTable(src, tmpdir, reader.replace)
+	reader.close()
+	src.Close()
+	if err != nil {
+		os.RemoveAll(tmpdir)
+		return err
+	}
+	return swapFreezerTable(dir, ChainFreezerReceiptTable)
+}
+
+// stagedReceiptsReader streams the receipts staged by RepairReceipts.
+type stagedReceiptsReader struct {
+	file   *os.File
+	stream *rlp.Stream
+	next   *stagedReceipts // Next staged entry, nil once exhausted
+	index  int
+}
+
+// openStagedReceipts opens a file of staged receipts, positioned at the first
+// entry.
+func openStagedReceipts(path string) (*stagedReceiptsReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &stagedReceiptsReader{file: file, stream: rlp.NewStream(bufio.NewReader(file), 0)}
+	if err := r.advance(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// advance reads the next staged entry, which must follow the current one.
+func (r *stagedReceiptsReader) advance() error {
+	entry := new(stagedReceipts)
+	if err := r.stream.Decode(entry); err == io.EOF {
+		r.next = nil
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("staged receipts %d: %v", r.index, err)
+	}
+	if r.next != nil && entry.Number <= r.next.Number {
+		return fmt.Errorf("staged receipts %d: block #%d out of order", r.index, entry.Number)
+	}
+	r.next = entry
+	r.index++
+	return nil
+}
+
+// replace implements freezerItemReplacer, returning the staged receipts of the
+// requested block if any.
+func (r *stagedReceiptsReader) replace(number uint64) ([]byte, error) {
+	if r.next == nil || r.next.Number != number {
+		return nil, nil
+	}
+	blob := r.next.Receipts
+	return blob, r.advance()
+}
+
+func (r *stagedReceiptsReader) close() error {
+	return r.file.Close()
+}
+
+// checkStagedReceipts verifies that the blocks of the staged receipts are the
+// ones stored in the chain freezer in datadir, returning the number of entries.
+func checkStagedReceipts(datadir string, staged string) (int, error) {
+	dir, err := freezerTableDir(datadir, ChainFreezerHashTable)
+	if err != nil {
+		return 0, err
+	}
+	hashes, err := newTable(dir, ChainFreezerHashTable, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, freezerTableSize, chainFreezerNoSnappy[ChainFreezerHashTable], true)
+	if err != nil {
+		return 0, err
+	}
+	defer hashes.Close()
+
+	reader, err := openStagedReceipts(staged)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.close()
+
+	for reader.next != nil {
+		entry := reader.next
+		blob, err := hashes.Retrieve(entry.Number)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read ancient hash #%d: %w", entry.Number, err)
+		}
+		if hash := common.BytesToHash(blob); hash != entry.Hash {
+			return 0, fmt.Errorf("ancient block #%d hash mismatch: have %x, want %x", entry.Number, hash, entry.Hash)
+		}
+		if err := reader.advance(); err != nil {
+			return 0, err
+		}
+	}
+	return reader.index, nil
+}
+
+// encodeStorageReceipts encodes receipts in their storage form.
+func encodeStorageReceipts(receipts types.Receipts) ([]byte, error) {
+	stored := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		stored[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	return rlp.EncodeToBytes(stored)
+}
+
+// diffReceipts returns the differences between the stored and expected receipts
+// of a block, limited to the fields persisted in the database.
+func diffReceipts(stored, want types.Receipts) []string {
+	if len(stored) != len(want) {
+		return []string{fmt.Sprintf("receipt count: have %d, want %d", len(stored), len(want))}
+	}
+	var diffs []string
+	for i := range want {
+		have, want := stored[i], want[i]
+		if !bytes.Equal(have.PostState, want.PostState) || have.Status != want.Status {
+			diffs = append(diffs, fmt.Sprintf("receipt %d status: have %d (%x), want %d (%x)", i, have.Status, have.PostState, want.Status, want.PostState))
+		}
+		if have.CumulativeGasUsed != want.CumulativeGasUsed {
+			diffs = append(diffs, fmt.Sprintf("receipt %d cumulative gas: have %d, want %d", i, have.CumulativeGasUsed, want.CumulativeGasUsed))
+		}
+		if diff := diffLogs(have.Logs, want.Logs); diff != "" {
+			diffs = append(diffs, fmt.Sprintf("receipt %d %s", i, diff))
+		}
+		if !equalUint64Ptr(have.DepositNonce, want.DepositNonce) {
+			diffs = append(diffs, fmt.Sprintf("receipt %d deposit nonce: have %s, want %s", i, formatUint64Ptr(have.DepositNonce), formatUint64Ptr(want.DepositNonce)))
+		}
+		if !equalUint64Ptr(have.DepositReceiptVersion, want.DepositReceiptVersion) {
+			diffs = append(diffs, fmt.Sprintf("receipt %d deposit receipt version: have %s, want %s", i, formatUint64Ptr(have.DepositReceiptVersion), formatUint64Ptr(want.DepositReceiptVersion)))
+		}
+	}
+	return diffs
+}
+
+// diffLogs describes the first difference between two lists of logs.
+func diffLogs(have, want []*types.Log) string {
+	if len(have) != len(want) {
+		return fmt.Sprintf("log count: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i].Address != want[i].Address || !bytes.Equal(have[i].Data, want[i].Data) || len(have[i].Topics) != len(want[i].Topics) {
+			return fmt.Sprintf("log %d mismatch", i)
+		}
+		for j := range want[i].Topics {
+			if have[i].Topics[j] != want[i].Topics[j] {
+				return fmt.Sprintf("log %d topic %d mismatch", i, j)
+			}
+		}
+	}
+	return ""
+}
+
+func equalUint64Ptr(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatUint64Ptr(n *uint64) string {
+	if n == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*n)
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that mismatching receipts are reported, and repaired both in the freezer
+// and in the key-value store.
+func TestRepairReceipts(t *testing.T) {
+	ancient := t.TempDir()
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), ancient, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database with ancient backend: %v", err)
+	}
+
+	var (
+		blocks = makeTestBlocks(8, 2)
+		want   = make([]types.Receipts, len(blocks))
+		stored = make([]types.Receipts, len(blocks))
+		nonce  = uint64(3)
+	)
+	for i := range blocks {
+		want[i] = types.Receipts{
+			{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}},
+			{Status: types.ReceiptStatusFailed, CumulativeGasUsed: 42000, Logs: []*types.Log{}, DepositNonce: &nonce},
+		}
+		stored[i] = want[i]
+	}
+	// Break the receipts of a frozen and a live block
+	stored[1] = types.Receipts{want[1][0], {Status: types.ReceiptStatusFailed, CumulativeGasUsed: 40000, Logs: []*types.Log{}}}
+	stored[6] = types.Receipts{want[6][0]}
+
+	if _, err := WriteAncientBlocks(db, blocks[:4], stored[:4], big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	for i, block := range blocks[4:] {
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteReceipts(db, block.Hash(), block.NumberU64(), stored[4+i])
+	}
+	// Drop the receipts of a live block, which must be skipped but still passed
+	// to the source for it to carry its state forward
+	DeleteReceipts(db, blocks[5].Hash(), 5)
+
+	var next uint64
+	source := func(block *types.Block) (types.Receipts, error) {
+		if block.NumberU64() != next {
+			t.Fatalf("source called out of order: have #%d, want #%d", block.NumberU64(), next)
+		}
+		next++
+		return want[block.NumberU64()], nil
+	}
+	staged := new(bytes.Buffer)
+	check := func(dryRun bool) []*ReceiptRepair {
+		next = 0
+		staged.Reset()
+		repairs, err := RepairReceipts(db, 0, 7, source, staged, dryRun)
+		if err != nil {
+			t.Fatalf("failed to repair receipts: %v", err)
+		}
+		if next != 8 {
+			t.Fatalf("source called for %d blocks, want 8", next)
+		}
+		if len(repairs) != 2 || repairs[0].Number != 1 || repairs[1].Number != 6 {
+			t.Fatalf("unexpected repairs: %v", repairs)
+		}
+		if len(repairs[0].Diffs) != 2 || len(repairs[1].Diffs) != 1 {
+			t.Fatalf("unexpected diffs: %v, %v", repairs[0].Diffs, repairs[1].Diffs)
+		}
+		if !repairs[0].Frozen || repairs[1].Frozen {
+			t.Fatalf("frozen repairs mismatch: %v, %v", repairs[0].Frozen, repairs[1].Frozen)
+		}
+		return repairs
+	}
+	// A dry run should report but not touch anything
+	check(true)
+	check(true)
+	if staged.Len() != 0 {
+		t.Fatalf("dry run staged %d bytes", staged.Len())
+	}
+
+	// An actual run should report and fix the live receipts, the frozen ones are
+	// rewritten once the freezer is closed
+	check(false)
+	file := filepath.Join(t.TempDir(), "receipts.repair")
+	if err := os.WriteFile(file, staged.Bytes(), 0600); err != nil {
+		t.Fatalf("failed to write staged receipts: %v", err)
+	}
+	next = 0
+	if repairs, err := RepairReceipts(db, 0, 7, source, nil, true); err != nil || len(repairs) != 1 || repairs[0].Number != 1 {
+		t.Fatalf("live receipts not repaired: %v, %v", repairs, err)
+	}
+	if err := RewriteAncientReceipts(ancient, file); err == nil {
+		t.Fatalf("rewrote receipts of open freezer")
+	}
+	db.Close()
+	if err := RewriteAncientReceipts(ancient, file); err != nil {
+		t.Fatalf("failed to rewrite ancient receipts: %v", err)
+	}
+	db, err = NewDatabaseWithFreezer(NewMemoryDatabase(), ancient, "", false)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	next = 0
+	if repairs, err := RepairReceipts(db, 0, 3, source, nil, true); err != nil || len(repairs) != 0 {
+		t.Fatalf("receipts not repaired: %v, %v", repairs, err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 4 {
+		t.Fatalf("ancient count mismatch: have %d, want 4", frozen)
+	}
+	for i, block := range blocks[:4] {
+		if ReadBlock(db, block.Hash(), block.NumberU64()) == nil {
+			t.Errorf("block %d missing after repair", i)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// CompactWitnessVersion is the leading byte of the compact witness encoding. It
+// can not be mistaken for the RLP encoding, which always starts with a list.
+const CompactWitnessVersion = 0x01
+
+// maxCompactWitnessSize is the maximum decompressed size of a compact witness
+// accepted by the decoder.
+const maxCompactWitnessSize = 512 * 1024 * 1024
+
+// Kinds of the blobs in a compact witness. A blob may be of both kinds.
+const (
+	compactKindCode  = 1 << 0
+	compactKindState = 1 << 1
+)
+
+var (
+	errCompactVersion = errors.New("unsupported compact witness version")
+	errCompactSize    = errors.New("compact witness too large")
+)
+
+// compactWitness is the payload of the compact witness encoding. Codes and trie
+// nodes are stored once in a single table, each flagged with the kinds it is
+// used as.
+type compactWitness struct {
+	Headers []*types.Header
+	Blobs   [][]byte
+	Kinds   []byte
+}
+
+// EncodeCompact serializes a witness into the compact encoding: a version byte
+// followed by the snappy compressed RLP encoding of the deduplicated codes and
+// trie nodes.
+//
+// By default the blobs are ordered by hash. If grouped is set, the trie nodes are
+// ordered by a walk of the account trie, with every storage trie following the
+// account trie. Keeping related nodes close together improves the compression
+// ratio, at the cost of walking the tries.
+func (w *Witness) EncodeCompact(grouped bool) ([]byte, error) {
+	kinds := make(map[string]byte, len(w.Codes)+len(w.State))
+	for code := range w.Codes {
+		kinds[code] |= compactKindCode
+	}
+	for node := range w.State {
+		kinds[node] |= compactKindState
+	}
+	var blobs [][]byte
+	if grouped && len(w.Headers) > 0 {
+		blobs = w.walkState(w.Root())
+	}
+	// Append all the blobs not reached by the walk, in a deterministic order
+	var (
+		seen = make(map[string]struct{}, len(blobs))
+		rest [][]byte
+	)
+	for _, blob := range blobs {
+		seen[string(blob)] = struct{}{}
+	}
+	for blob := range kinds {
+		if _, ok := seen[blob]; !ok {
+			rest = append(rest, []byte(blob))
+		}
+	}
+	slices.SortFunc(rest, func(a, b []byte) int {
+		return bytes.Compare(crypto.Keccak256(a), crypto.Keccak256(b))
+	})
+	blobs = append(blobs, rest...)
+
+	ext := &compactWitness{
+		Headers: w.Headers,
+		Blobs:   blobs,
+		Kinds:   make([]byte, len(blobs)),
+	}
+	for i, blob := range blobs {
+		ext.Kinds[i] = kinds[string(blob)]
+	}
+	payload, err := rlp.EncodeToBytes(ext)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{CompactWitnessVersion}, snappy.Encode(nil, payload)...), nil
+}
+
+// DecodeCompact decodes a witness from the compact encoding.
+func DecodeCompact(blob []byte) (*Witness, error) {
+	if len(blob) == 0 || blob[0] != CompactWitnessVersion {
+		return nil, errCompactVersion
+	}
+	if size, err := snappy.DecodedLen(blob[1:]); err != nil {
+		return nil, err
+	} else if size > maxCompactWitnessSize {
+		return nil, errCompactSize
+	}
+	payload, err := snappy.Decode(nil, blob[1:])
+	if err != nil {
+		return nil, err
+	}
+	var ext compactWitness
+	if err := rlp.DecodeBytes(payload, &ext); err != nil {
+		return nil, err
+	}
+	if len(ext.Kinds) != len(ext.Blobs) {
+		return nil, fmt.Errorf("kind count mismatch: have %d, want %d", len(ext.Kinds), len(ext.Blobs))
+	}
+	w := &Witness{
+		Headers: ext.Headers,
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+	}
+	for i, blob := range ext.Blobs {
+		if ext.Kinds[i]&compactKindCode != 0 {
+			w.Codes[string(blob)] = struct{}{}
+		}
+		if ext.Kinds[i]&compactKindState != 0 {
+			w.State[string(blob)] = struct{}{}
+		}
+	}
+	return w, nil
+}
+
+// walkState returns the trie nodes of the witness reachable from the given state
+// root: the account trie nodes in depth-first order, followed by the nodes of
+// each storage trie in the order the accounts were encountered.
+func (w *Witness) walkState(root common.Hash) [][]byte {
+	nodes := make(map[common.Hash][]byte, len(w.State))
+	for node := range w.State {
+		nodes[crypto.Keccak256Hash([]byte(node))] = []byte(node)
+	}
+	var (
+		visited = make(map[common.Hash]struct{})
+		order   [][]byte
+		storage []common.Hash
+	)
+	walkTrie(root, nodes, visited, &order, func(value []byte) {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(value, &account); err == nil && account.Root != types.EmptyRootHash {
+			storage = append(storage, account.Root)
+		}
+	})
+	for _, root := range storage {
+		walkTrie(root, nodes, visited, &order, nil)
+	}
+	return order
+}
+
+// walkTrie appends the nodes of a trie present in the given node set to order,
+// in depth-first order, invoking onLeaf with the value of every leaf reached.
+// Nodes missing from the set are skipped along with their subtries.
+func walkTrie(hash common.Hash, nodes map[common.Hash][]byte, visited map[common.Hash]struct{}, order *[][]byte, onLeaf func([]byte)) {
+	if _, ok := visited[hash]; ok {
+		return
+	}
+	blob, ok := nodes[hash]
+	if !ok {
+		return
+	}
+	visited[hash] = struct{}{}
+	*order = append(*order, blob)
+
+	var walkNode func(node []byte)
+	walkChild := func(ref []byte) {
+		kind, content, _, err := rlp.Split(ref)
+		if err != nil {
+			return
+		}
+		switch {
+		case kind == rlp.List:
+			walkNode(ref) // Node embedded into its parent
+		case kind == rlp.String && len(content) == common.HashLength:
+			walkTrie(common.BytesToHash(content), nodes, visited, order, onLeaf)
+		}
+	}
+	walkNode = func(node []byte) {
+		elems, _, err := rlp.SplitList(node)
+		if err != nil {
+			return
+		}
+		count, err := rlp.CountValues(elems)
+		if err != nil {
+			return
+		}
+		switch count {
+		case 2:
+			key, rest, err := rlp.SplitString(elems)
+			if err != nil || len(key) == 0 {
+				return
+			}
+			// The hex-prefix flag of the key tells leaves and extensions apart
+			if key[0]&0x20 != 0 {
+				if value, _, err := rlp.SplitString(rest); err == nil && onLeaf != nil {
+					onLeaf(value)
+				}
+				return
+			}
+			walkChild(rest)
+
+		case 17:
+			for i := 0; i < 16; i++ {
+				_, _, rest, err := rlp.Split(elems)
+				if err != nil {
+					return
+				}
+				walkChild(elems[:len(elems)-len(rest)])
+				elems = rest
+			}
+		}
+	}
+	walkNode(blob)
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless_test
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that witnesses survive a round trip through the compact encoding, in
+// both blob orders, and that it is smaller than the RLP encoding.
+func TestCompactWitness(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		// Contract storing the call value in slot zero
+		contract = common.HexToAddress("0xc0de")
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr:     {Balance: big.NewInt(params.Ether)},
+				contract: {Code: []byte{0x34, 0x60, 0x00, 0x55, 0x00}, Storage: map[common.Hash]common.Hash{{}: {0x01}}},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *core.BlockGen) {
+		for j := 0; j < 8; j++ {
+			to := common.Address{byte(i + 1), byte(j)}
+			if j == 0 {
+				to = contract
+			}
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), to, big.NewInt(1000), 100000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	witness, err := chain.InsertBlockWithoutSetHead(context.Background(), blocks[1], true)
+	if err != nil {
+		t.Fatalf("failed to create witness: %v", err)
+	}
+	legacy, _ := rlp.EncodeToBytes(witness)
+
+	for _, grouped := range []bool{false, true} {
+		blob, err := witness.EncodeCompact(grouped)
+		if err != nil {
+			t.Fatalf("grouped %v: failed to encode witness: %v", grouped, err)
+		}
+		if len(blob) >= len(legacy) {
+			t.Errorf("grouped %v: compact encoding not smaller: have %d, rlp %d", grouped, len(blob), len(legacy))
+		}
+		dec, err := stateless.DecodeCompact(blob)
+		if err != nil {
+			t.Fatalf("grouped %v: failed to decode witness: %v", grouped, err)
+		}
+		if len(dec.Headers) != len(witness.Headers) || dec.Headers[0].Hash() != witness.Headers[0].Hash() {
+			t.Errorf("grouped %v: headers mismatch", grouped)
+		}
+		if !reflect.DeepEqual(dec.Codes, witness.Codes) {
+			t.Errorf("grouped %v: codes mismatch: have %d, want %d", grouped, len(dec.Codes), len(witness.Codes))
+		}
+		if !reflect.DeepEqual(dec.State, witness.State) {
+			t.Errorf("grouped %v: state mismatch: have %d, want %d", grouped, len(dec.State), len(witness.State))
+		}
+	}
+	// Unknown versions and the RLP encoding must be rejected
+	if _, err := stateless.DecodeCompact(legacy); err == nil {
+		t.Errorf("decoded rlp witness as compact")
+	}
+	blob, _ := witness.EncodeCompact(false)
+	blob[0] = stateless.CompactWitnessVersion + 1
+	if _, err := stateless.DecodeCompact(blob); err == nil {
+		t.Errorf("decoded witness with unknown version")
+	}
+}
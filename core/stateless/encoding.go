@@ -17,8 +17,11 @@
 package stateless
 
 import (
+	"errors"
+	"fmt"
 	"io"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -106,3 +109,39 @@ func (w *Witness) ToExecutionWitness() *ExecutionWitness {
 		State:   transformMap(w.State),
 	}
 }
+
+// ToWitness converts an execution witness back into the internal witness format,
+// checking that every code and state entry is keyed by its hash.
+func (w *ExecutionWitness) ToWitness() (*Witness, error) {
+	if len(w.Headers) == 0 {
+		return nil, errors.New("witness contains no headers")
+	}
+	codes, err := untransformMap(w.Codes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid codes: %w", err)
+	}
+	state, err := untransformMap(w.State)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state: %w", err)
+	}
+	return &Witness{
+		Headers: w.Headers,
+		Codes:   codes,
+		State:   state,
+	}, nil
+}
+
+func untransformMap(in map[string]string) (map[string]struct{}, error) {
+	out := make(map[string]struct{}, len(in))
+	for key, item := range in {
+		blob, err := hexutil.Decode(item)
+		if err != nil {
+			return nil, err
+		}
+		if hash := crypto.Keccak256Hash(blob); hash != common.HexToHash(key) {
+			return nil, fmt.Errorf("hash mismatch: have %v, want %v", hash, key)
+		}
+		out[string(blob)] = struct{}{}
+	}
+	return out, nil
+}
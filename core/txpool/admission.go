@@ -0,0 +1,325 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ErrAdmissionRejected is the sentinel wrapped by every AdmissionError, allowing
+// callers to detect policy rejections via errors.Is.
+var ErrAdmissionRejected = errors.New("transaction rejected by admission policy")
+
+// AdmissionRejectedErrCode is the JSON-RPC error code returned for transactions
+// rejected by an admission policy (EIP-1474 "transaction rejected").
+const AdmissionRejectedErrCode = -32003
+
+// admissionRejectMeterName is the prefix of the per-policy rejection meters.
+const admissionRejectMeterName = "txpool/admission/rejected/"
+
+// AdmissionContext is the information made available to admission policies about
+// the transaction being evaluated.
+type AdmissionContext struct {
+	From  common.Address // Sender of the transaction, already recovered
+	Local bool           // Whether the transaction was submitted locally
+
+	// Pooled returns the number of transactions (pending and queued) currently
+	// tracked by the pool for the given account.
+	Pooled func(addr common.Address) int
+}
+
+// AdmissionPolicy is an operator supplied rule deciding whether a transaction
+// may enter the pool, on top of the consensus and pool validation rules. It is
+// configured when embedding the node and evaluated in TxPool.Add before the
+// transaction reaches any subpool.
+//
+// Implementations must be safe for concurrent use.
+type AdmissionPolicy interface {
+	// Name returns a short identifier of the policy, used in errors and metrics.
+	Name() string
+
+	// Admit returns nil if the transaction is acceptable, or the reason of the
+	// rejection otherwise.
+	Admit(ctx *AdmissionContext, tx *types.Transaction) error
+}
+
+// AdmissionError is the typed error returned for transactions rejected by an
+// admission policy. It implements the rpc.Error and rpc.DataError interfaces so
+// that the rejection reaches RPC callers with a dedicated code.
+type AdmissionError struct {
+	Policy string // Name of the rejecting policy
+	Reason error  // Reason reported by the policy
+}
+
+// Error implements error.
+func (e *AdmissionError) Error() string {
+	return fmt.Sprintf("%v: %s: %v", ErrAdmissionRejected, e.Policy, e.Reason)
+}
+
+// Unwrap returns the list of wrapped errors, allowing errors.Is to match both
+// ErrAdmissionRejected and the policy specific reason.
+func (e *AdmissionError) Unwrap() []error {
+	return []error{ErrAdmissionRejected, e.Reason}
+}
+
+// ErrorCode implements rpc.Error.
+func (e *AdmissionError) ErrorCode() int {
+	return AdmissionRejectedErrCode
+}
+
+// ErrorData implements rpc.DataError.
+func (e *AdmissionError) ErrorData() interface{} {
+	return e.Policy
+}
+
+// SetAdmissionPolicies replaces the set of admission policies the pool evaluates
+// for every new transaction. Policies are checked in order, the first rejection
+// is returned.
+func (p *TxPool) SetAdmissionPolicies(policies ...AdmissionPolicy) {
+	p.policyLock.Lock()
+	defer p.policyLock.Unlock()
+
+	p.policies = policies
+}
+
+// AdmissionPolicies returns the names of the configured admission policies.
+func (p *TxPool) AdmissionPolicies() []string {
+	p.policyLock.RLock()
+	defer p.policyLock.RUnlock()
+
+	names := make([]string, len(p.policies))
+	for i, policy := range p.policies {
+		names[i] = policy.Name()
+	}
+	return names
+}
+
+// admit runs the configured admission policies against a transaction. Failing
+// to recover the sender is not treated as a rejection here, the subpools will
+// report the invalid signature on their own.
+func (p *TxPool) admit(tx *types.Transaction, local bool) error {
+	return p.admitWith(tx, local, func(addr common.Address) int {
+		pending, queued := p.ContentFrom(addr)
+		return len(pending) + len(queued)
+	})
+}
+
+// admitWith runs the admission policies with a custom pooled transaction counter.
+func (p *TxPool) admitWith(tx *types.Transaction, local bool, pooled func(common.Address) int) error {
+	p.policyLock.RLock()
+	policies := p.policies
+	p.policyLock.RUnlock()
+
+	if len(policies) == 0 {
+		return nil
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil
+	}
+	ctx := &AdmissionContext{
+		From:   from,
+		Local:  local,
+		Pooled: pooled,
+	}
+	for _, policy := range policies {
+		if err := policy.Admit(ctx, tx); err != nil {
+			metrics.GetOrRegisterMeter(admissionRejectMeterName+policy.Name(), nil).Mark(1)
+			return &AdmissionError{Policy: policy.Name(), Reason: err}
+		}
+	}
+	return nil
+}
+
+// Errors returned by the built-in admission policies.
+var (
+	// ErrDeniedAddress is returned if the sender or recipient of a transaction
+	// is on the configured deny-list.
+	ErrDeniedAddress = errors.New("address denied")
+
+	// ErrCalldataTooLarge is returned if the calldata of a transaction exceeds
+	// the configured limit.
+	ErrCalldataTooLarge = errors.New("calldata too large")
+
+	// ErrSenderPendingCap is returned if the sender already has the maximum
+	// number of transactions in the pool.
+	ErrSenderPendingCap = errors.New("sender pool quota exceeded")
+
+	// ErrContractCreationDenied is returned if the sender is not allowed to
+	// deploy contracts.
+	ErrContractCreationDenied = errors.New("contract creation not allowed")
+)
+
+// DenyListPolicy rejects transactions sent from or to a set of addresses.
+type DenyListPolicy struct {
+	path string // Optional file the list was loaded from, used by Reload
+
+	lock  sync.RWMutex
+	addrs map[common.Address]struct{}
+}
+
+// NewDenyListPolicy creates a deny-list policy from a set of addresses.
+func NewDenyListPolicy(addrs []common.Address) *DenyListPolicy {
+	policy := &DenyListPolicy{addrs: make(map[common.Address]struct{}, len(addrs))}
+	for _, addr := range addrs {
+		policy.addrs[addr] = struct{}{}
+	}
+	return policy
+}
+
+// NewDenyListPolicyFromFile creates a deny-list policy from a file containing one
+// hex address per line. Empty lines and lines starting with '#' are ignored.
+func NewDenyListPolicyFromFile(path string) (*DenyListPolicy, error) {
+	addrs, err := readAddressList(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := NewDenyListPolicy(addrs)
+	policy.path = path
+	return policy, nil
+}
+
+// Reload re-reads the deny-list from the file it was created from.
+func (p *DenyListPolicy) Reload() error {
+	if p.path == "" {
+		return errors.New("deny-list not backed by a file")
+	}
+	addrs, err := readAddressList(p.path)
+	if err != nil {
+		return err
+	}
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	p.lock.Lock()
+	p.addrs = set
+	p.lock.Unlock()
+	return nil
+}
+
+// Name implements AdmissionPolicy.
+func (p *DenyListPolicy) Name() string { return "denylist" }
+
+// Admit implements AdmissionPolicy.
+func (p *DenyListPolicy) Admit(ctx *AdmissionContext, tx *types.Transaction) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if _, ok := p.addrs[ctx.From]; ok {
+		return fmt.Errorf("%w: sender %v", ErrDeniedAddress, ctx.From)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.addrs[*to]; ok {
+			return fmt.Errorf("%w: recipient %v", ErrDeniedAddress, *to)
+		}
+	}
+	return nil
+}
+
+// readAddressList parses a file of newline separated hex addresses.
+func readAddressList(path string) ([]common.Address, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		addrs   []common.Address
+		scanner = bufio.NewScanner(file)
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !common.IsHexAddress(entry) {
+			return nil, fmt.Errorf("invalid address on line %d: %q", line, entry)
+		}
+		addrs = append(addrs, common.HexToAddress(entry))
+	}
+	return addrs, scanner.Err()
+}
+
+// MaxCalldataPolicy rejects transactions whose calldata exceeds a byte limit.
+type MaxCalldataPolicy struct {
+	Limit int // Maximum number of calldata bytes permitted
+}
+
+// Name implements AdmissionPolicy.
+func (p *MaxCalldataPolicy) Name() string { return "maxcalldata" }
+
+// Admit implements AdmissionPolicy.
+func (p *MaxCalldataPolicy) Admit(ctx *AdmissionContext, tx *types.Transaction) error {
+	if size := len(tx.Data()); size > p.Limit {
+		return fmt.Errorf("%w: have %d, max %d", ErrCalldataTooLarge, size, p.Limit)
+	}
+	return nil
+}
+
+// SenderCapPolicy rejects transactions from senders which already have a given
+// number of transactions in the pool.
+type SenderCapPolicy struct {
+	Cap          int  // Maximum number of pooled transactions per sender
+	ExemptLocals bool // Whether locally submitted transactions bypass the cap
+}
+
+// Name implements AdmissionPolicy.
+func (p *SenderCapPolicy) Name() string { return "sendercap" }
+
+// Admit implements AdmissionPolicy.
+func (p *SenderCapPolicy) Admit(ctx *AdmissionContext, tx *types.Transaction) error {
+	if ctx.Local && p.ExemptLocals {
+		return nil
+	}
+	if pooled := ctx.Pooled(ctx.From); pooled >= p.Cap {
+		return fmt.Errorf("%w: have %d, max %d", ErrSenderPendingCap, pooled, p.Cap)
+	}
+	return nil
+}
+
+// ContractCreationPolicy rejects contract deployments, unless the sender is on
+// an allow-list.
+type ContractCreationPolicy struct {
+	Allowed map[common.Address]struct{} // Senders permitted to deploy contracts
+}
+
+// Name implements AdmissionPolicy.
+func (p *ContractCreationPolicy) Name() string { return "contractcreation" }
+
+// Admit implements AdmissionPolicy.
+func (p *ContractCreationPolicy) Admit(ctx *AdmissionContext, tx *types.Transaction) error {
+	if tx.To() != nil {
+		return nil
+	}
+	if _, ok := p.Allowed[ctx.From]; ok {
+		return nil
+	}
+	return fmt.Errorf("%w: sender %v", ErrContractCreationDenied, ctx.From)
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the built-in admission policies accept and reject the expected
+// transactions and that rejections surface as typed errors.
+func TestAdmissionPolicies(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSigner(params.TestChainConfig)
+
+	denied := common.HexToAddress("0xdead")
+	other := common.HexToAddress("0xbeef")
+
+	sign := func(to *common.Address, data []byte) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{
+			To:       to,
+			Gas:      100000,
+			GasPrice: big.NewInt(1),
+			Data:     data,
+		})
+	}
+	// Load the deny-list from a file to exercise the parser too
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(path, []byte("# sanctioned\n\n"+denied.Hex()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	denylist, err := NewDenyListPolicyFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load deny-list: %v", err)
+	}
+	pooled := 0
+	pool := &TxPool{}
+	pool.SetAdmissionPolicies(
+		denylist,
+		&MaxCalldataPolicy{Limit: 4},
+		&SenderCapPolicy{Cap: 2, ExemptLocals: true},
+		&ContractCreationPolicy{},
+	)
+	tests := []struct {
+		tx     *types.Transaction
+		local  bool
+		pooled int
+		err    error
+	}{
+		{tx: sign(&other, nil)},
+		{tx: sign(&denied, nil), err: ErrDeniedAddress},
+		{tx: sign(&other, make([]byte, 5)), err: ErrCalldataTooLarge},
+		{tx: sign(&other, nil), pooled: 2, err: ErrSenderPendingCap},
+		{tx: sign(&other, nil), pooled: 2, local: true},
+		{tx: sign(nil, nil), err: ErrContractCreationDenied},
+	}
+	for i, tt := range tests {
+		pooled = tt.pooled
+
+		err := pool.admitWith(tt.tx, tt.local, func(addr common.Address) int {
+			if addr != sender {
+				t.Errorf("test %d: pooled count requested for wrong account %v", i, addr)
+			}
+			return pooled
+		})
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("test %d: unexpected rejection: %v", i, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.err) || !errors.Is(err, ErrAdmissionRejected) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		var aerr *AdmissionError
+		if !errors.As(err, &aerr) || aerr.ErrorCode() != AdmissionRejectedErrCode {
+			t.Errorf("test %d: error not typed: %v", i, err)
+		}
+	}
+	// Update the deny-list on disk and ensure reloading picks it up
+	if err := os.WriteFile(path, []byte(other.Hex()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := denylist.Reload(); err != nil {
+		t.Fatalf("failed to reload deny-list: %v", err)
+	}
+	if err := pool.admitWith(sign(&denied, nil), false, func(common.Address) int { return 0 }); err != nil {
+		t.Errorf("reloaded deny-list still rejects removed address: %v", err)
+	}
+	if err := pool.admitWith(sign(&other, nil), false, func(common.Address) int { return 0 }); !errors.Is(err, ErrDeniedAddress) {
+		t.Errorf("reloaded deny-list error mismatch: have %v, want %v", err, ErrDeniedAddress)
+	}
+}
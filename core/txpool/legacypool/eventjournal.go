@@ -0,0 +1,402 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// errEventJournalClosed is returned if an event is appended to a journal whose
+// file could not be reopened after a compaction.
+var errEventJournalClosed = errors.New("transaction event journal closed")
+
+var (
+	eventJournalAddMeter     = metrics.NewRegisteredMeter("txpool/eventjournal/add", nil)
+	eventJournalDropMeter    = metrics.NewRegisteredMeter("txpool/eventjournal/drop", nil)
+	eventJournalCompactTimer = metrics.NewRegisteredTimer("txpool/eventjournal/compact", nil)
+)
+
+const (
+	journalEventAdd  uint8 = 0 // Transaction entered the pool
+	journalEventDrop uint8 = 1 // Transaction left the pool (included, evicted, replaced)
+)
+
+// journalEvent is a single record in the append-only event journal.
+type journalEvent struct {
+	Kind        uint8
+	Hash        common.Hash
+	Time        uint64 // Arrival time of the transaction in unix nanoseconds
+	Local       bool   // Whether the transaction was tracked as local
+	Tx          []byte // Binary transaction encoding, empty for drops
+	Conditional []byte // JSON encoded transaction conditional, empty if none
+}
+
+// liveEvent is the retained add event of a transaction still in the pool.
+type liveEvent struct {
+	time uint64
+	blob []byte
+}
+
+// eventJournal is an append-only log of transaction pool add and drop events,
+// aimed at sequencers which must not lose or reorder user transactions across
+// restarts. Contrary to the rotating journal, every pooled transaction (local
+// and remote) is tracked along with its arrival time and conditional, and the
+// file is only ever appended to. Superseded records are garbage collected by a
+// background compaction which rewrites the live set without blocking appends.
+//
+// Records are written unbuffered, so the journal survives process crashes. A
+// torn record at the tail (e.g. power loss during a write) is truncated away
+// on the next load.
+type eventJournal struct {
+	path string
+
+	lock    sync.Mutex
+	writer  *os.File                   // Output stream to append new events into, nil while loading
+	loaded  bool                       // Whether the journal was loaded and opened for appends
+	live    map[common.Hash]*liveEvent // Add events of transactions still in the pool
+	garbage int                        // Number of superseded records in the file
+
+	compacting bool     // Whether a background compaction is running
+	backlog    [][]byte // Events appended while a compaction is running
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newEventJournal creates a new transaction event journal at the given path.
+func newEventJournal(path string) *eventJournal {
+	return &eventJournal{
+		path: path,
+		live: make(map[common.Hash]*liveEvent),
+		quit: make(chan struct{}),
+	}
+}
+
+// load parses the event journal from disk, replays the surviving transactions
+// into the pool in their original arrival order and opens the journal for new
+// appends. Any torn record at the end of the file is discarded.
+func (journal *eventJournal) load(add func(txs []*types.Transaction, local bool) []error) error {
+	data, err := os.ReadFile(journal.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Reconstruct the set of transactions that were still pooled on shutdown
+	var (
+		events  = make(map[common.Hash]*journalEvent)
+		records int
+		rest    = data
+	)
+	for len(rest) > 0 {
+		_, tail, err := rlp.SplitList(rest)
+		if err != nil {
+			break
+		}
+		event := new(journalEvent)
+		if err := rlp.DecodeBytes(rest[:len(rest)-len(tail)], event); err != nil {
+			break
+		}
+		switch event.Kind {
+		case journalEventAdd:
+			events[event.Hash] = event
+		case journalEventDrop:
+			delete(events, event.Hash)
+		}
+		records++
+		rest = tail
+	}
+	if len(rest) > 0 {
+		log.Warn("Truncating corrupted transaction event journal", "offset", len(data)-len(rest), "discarded", len(rest))
+	}
+	// Sort the surviving transactions by arrival time and reinject them into
+	// the pool with their original metadata, batching by local flag
+	pending := make([]*journalEvent, 0, len(events))
+	for _, event := range events {
+		pending = append(pending, event)
+	}
+	slices.SortFunc(pending, func(a, b *journalEvent) int {
+		if a.Time != b.Time {
+			if a.Time < b.Time {
+				return -1
+			}
+			return 1
+		}
+		return a.Hash.Cmp(b.Hash)
+	})
+	var (
+		total, dropped int
+		batch          []*types.Transaction
+		local          bool
+	)
+	flush := func() {
+		for _, err := range add(batch, local) {
+			if err != nil {
+				log.Debug("Failed to add journaled transaction", "err", err)
+				dropped++
+			}
+		}
+		batch = batch[:0]
+	}
+	for _, event := range pending {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(event.Tx); err != nil {
+			log.Warn("Failed to decode journaled transaction", "hash", event.Hash, "err", err)
+			dropped++
+			continue
+		}
+		tx.SetTime(time.Unix(0, int64(event.Time)))
+		if len(event.Conditional) > 0 {
+			cond := new(types.TransactionConditional)
+			if err := json.Unmarshal(event.Conditional, cond); err != nil {
+				log.Warn("Failed to decode journaled transaction conditional", "hash", event.Hash, "err", err)
+				dropped++
+				continue
+			}
+			tx.SetConditional(cond)
+		}
+		total++
+		if len(batch) > 0 && (event.Local != local || len(batch) >= 1024) {
+			flush()
+		}
+		local = event.Local
+		batch = append(batch, tx)
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	log.Info("Loaded transaction event journal", "records", records, "transactions", total, "dropped", dropped)
+
+	// Everything accepted by the pool during the replay is now in the live set,
+	// regenerate the journal from it to drop the replayed history.
+	return journal.compact()
+}
+
+// start spins up the background compaction loop.
+func (journal *eventJournal) start(interval time.Duration) {
+	journal.wg.Add(1)
+	go journal.loop(interval)
+}
+
+// loop periodically compacts the journal if it accumulated superseded records.
+func (journal *eventJournal) loop(interval time.Duration) {
+	defer journal.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			journal.lock.Lock()
+			garbage := journal.garbage
+			journal.lock.Unlock()
+
+			if garbage > 0 {
+				if err := journal.compact(); err != nil {
+					log.Warn("Failed to compact transaction event journal", "err", err)
+				}
+			}
+		case <-journal.quit:
+			return
+		}
+	}
+}
+
+// insert appends an add event for the given transaction to the journal.
+func (journal *eventJournal) insert(tx *types.Transaction, local bool) error {
+	event := &journalEvent{
+		Kind:  journalEventAdd,
+		Hash:  tx.Hash(),
+		Time:  uint64(tx.Time().UnixNano()),
+		Local: local,
+	}
+	var err error
+	if event.Tx, err = tx.MarshalBinary(); err != nil {
+		return err
+	}
+	if cond := tx.Conditional(); cond != nil {
+		if event.Conditional, err = json.Marshal(cond); err != nil {
+			return err
+		}
+	}
+	blob, err := rlp.EncodeToBytes(event)
+	if err != nil {
+		return err
+	}
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	if _, ok := journal.live[event.Hash]; ok {
+		journal.garbage++
+	}
+	journal.live[event.Hash] = &liveEvent{time: event.Time, blob: blob}
+	eventJournalAddMeter.Mark(1)
+
+	return journal.append(blob)
+}
+
+// remove appends a drop event for the given transaction to the journal.
+func (journal *eventJournal) remove(hash common.Hash) error {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	if _, ok := journal.live[hash]; !ok {
+		return nil
+	}
+	delete(journal.live, hash)
+	journal.garbage += 2 // both the add and the drop record are now superseded
+	eventJournalDropMeter.Mark(1)
+
+	blob, err := rlp.EncodeToBytes(&journalEvent{Kind: journalEventDrop, Hash: hash})
+	if err != nil {
+		return err
+	}
+	return journal.append(blob)
+}
+
+// append writes an encoded event into the active journal file.
+//
+// Note, this method assumes the journal lock is held!
+func (journal *eventJournal) append(blob []byte) error {
+	if journal.compacting {
+		journal.backlog = append(journal.backlog, blob)
+	}
+	if journal.writer == nil {
+		if journal.loaded {
+			return errEventJournalClosed
+		}
+		// Still loading, the live set is persisted by the compaction afterwards
+		return nil
+	}
+	_, err := journal.writer.Write(blob)
+	return err
+}
+
+// compact regenerates the journal from the set of live transactions. The bulk
+// of the work is done without holding the journal lock, events appended in the
+// meantime are carried over into the new file before it replaces the old one.
+func (journal *eventJournal) compact() error {
+	start := time.Now()
+
+	// Snapshot the live set, ordered by arrival time
+	journal.lock.Lock()
+	if journal.compacting {
+		journal.lock.Unlock()
+		return nil
+	}
+	journal.compacting = true
+	journal.backlog = nil
+
+	snapshot := make([]*liveEvent, 0, len(journal.live))
+	for _, event := range journal.live {
+		snapshot = append(snapshot, event)
+	}
+	journal.lock.Unlock()
+
+	defer func() {
+		journal.lock.Lock()
+		journal.compacting = false
+		journal.backlog = nil
+		journal.lock.Unlock()
+	}()
+	slices.SortStableFunc(snapshot, func(a, b *liveEvent) int {
+		switch {
+		case a.time < b.time:
+			return -1
+		case a.time > b.time:
+			return 1
+		}
+		return 0
+	})
+	// Write the snapshot into a replacement file
+	replacement, err := os.OpenFile(journal.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	for _, event := range snapshot {
+		if _, err := replacement.Write(event.blob); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	// Carry over any events appended during the rewrite and swap the files
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	for _, blob := range journal.backlog {
+		if _, err := replacement.Write(blob); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	if err := replacement.Sync(); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err := replacement.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(journal.path+".new", journal.path); err != nil {
+		return err
+	}
+	if journal.writer != nil {
+		journal.writer.Close()
+		journal.writer = nil
+	}
+	sink, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	journal.writer, journal.loaded = sink, true
+	journal.garbage = len(journal.backlog) // conservative, some might still be live
+	eventJournalCompactTimer.UpdateSince(start)
+
+	log.Debug("Compacted transaction event journal", "transactions", len(journal.live), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// close stops the compaction loop, flushes the journal contents to disk and
+// closes the file.
+func (journal *eventJournal) close() error {
+	close(journal.quit)
+	journal.wg.Wait()
+
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	var err error
+	if journal.writer != nil {
+		if err = journal.writer.Sync(); err == nil {
+			err = journal.writer.Close()
+		} else {
+			journal.writer.Close()
+		}
+		journal.writer = nil
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the event journal replays both local and remote transactions on
+// restart, preserving their arrival time, origin and conditional, and that
+// dropped transactions are not resurrected.
+func TestEventJournaling(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.EventJournal = filepath.Join(t.TempDir(), "events.rlp")
+	config.Rejournal = 100 * time.Millisecond
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Insert transactions with distinct arrival times, the remote one first
+	base := time.Unix(1700000000, 0)
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), remote),
+		pricedTransaction(0, 100000, big.NewInt(1), local),
+		pricedTransaction(1, 100000, big.NewInt(1), local),
+		pricedTransaction(1, 100000, big.NewInt(1), remote),
+	}
+	max := uint64(100)
+	txs[3].SetConditional(&types.TransactionConditional{BlockNumberMax: big.NewInt(1000), TimestampMax: &max})
+	for i, tx := range txs {
+		tx.SetTime(base.Add(time.Duration(i) * time.Second))
+	}
+	if err := pool.addRemoteSync(txs[0]); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.addLocal(txs[1]); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.addLocal(txs[2]); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(txs[3]); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	// Let a compaction run in between to ensure it doesn't lose anything
+	time.Sleep(3 * config.Rejournal)
+	pool.Close()
+
+	// Simulate a torn write at the end of the journal
+	f, err := os.OpenFile(config.EventJournal, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	f.Write([]byte{0xf9, 0x01})
+	f.Close()
+
+	// Restart the pool and ensure everything survived with its metadata
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	if pending, queued := pool.Stats(); pending != 4 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 4, 0)
+	}
+	for i, tx := range txs {
+		have := pool.Get(tx.Hash())
+		if have == nil {
+			t.Fatalf("tx %d: missing after restart", i)
+		}
+		if !have.Time().Equal(tx.Time()) {
+			t.Errorf("tx %d: arrival time mismatch: have %v, want %v", i, have.Time(), tx.Time())
+		}
+	}
+	if pool.all.GetLocal(txs[1].Hash()) == nil || pool.all.GetRemote(txs[0].Hash()) == nil {
+		t.Errorf("transaction origin not preserved")
+	}
+	if cond := pool.Get(txs[3].Hash()).Conditional(); cond == nil || cond.BlockNumberMax.Cmp(big.NewInt(1000)) != 0 || *cond.TimestampMax != max {
+		t.Errorf("transaction conditional not preserved: %v", cond)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Drop the remote account's transactions and ensure they stay dropped
+	statedb.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 2)
+	<-pool.requestReset(nil, nil)
+	pool.Close()
+
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 2, 0)
+	}
+	if len(pool.events.live) != 2 {
+		t.Fatalf("journal live set mismatch: have %d, want %d", len(pool.events.live), 2)
+	}
+}
+
+// Tests that the pool refuses to start if the event journal cannot be loaded or
+// rewritten, instead of running with the journal silently disabled.
+func TestEventJournalInitFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, path := range []string{
+		dir, // unreadable journal
+		filepath.Join(dir, "missing", "events.rlp"), // unwritable journal
+	} {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+		config := testTxPoolConfig
+		config.EventJournal = path
+
+		pool := New(config, blockchain)
+		if err := pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err == nil {
+			pool.Close()
+			t.Fatalf("journal %s: pool initialized", path)
+		}
+	}
+}
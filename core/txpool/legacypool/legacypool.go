@@ -19,6 +19,7 @@ package legacypool
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
@@ -131,6 +132,12 @@ type Config struct {
 	// When true, all transactions loaded from the journal are treated as remote.
 	JournalRemote bool
 
+	// EventJournal is the path of an append-only journal recording every add and
+	// drop of both local and remote transactions, replayed on startup in original
+	// arrival order. Meant for sequencers, it supersedes the rotating Journal when
+	// set. The Rejournal interval is used as its compaction period.
+	EventJournal string
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -219,8 +226,9 @@ type LegacyPool struct {
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals  *accountSet   // Set of local transaction to exempt from eviction rules
+	journal *journal      // Journal of local transaction to back up to disk
+	events  *eventJournal // Append-only journal of all pool events (sequencer mode)
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -276,7 +284,14 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	}
 	pool.priced = newPricedList(pool.all)
 
-	if (!config.NoLocals || config.JournalRemote) && config.Journal != "" {
+	if config.EventJournal != "" {
+		pool.events = newEventJournal(config.EventJournal)
+		pool.all.onRemove = func(hash common.Hash) {
+			if err := pool.events.remove(hash); err != nil {
+				log.Warn("Failed to journal transaction drop", "hash", hash, "err", err)
+			}
+		}
+	} else if (!config.NoLocals || config.JournalRemote) && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
 	return pool
@@ -336,6 +351,21 @@ func (pool *LegacyPool) Init(gasTip uint64, head *types.Header, reserve txpool.A
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If the event journal is enabled, replay it and start the compaction
+	if pool.events != nil {
+		add := func(txs []*types.Transaction, local bool) []error {
+			return pool.Add(txs, local, true)
+		}
+		// Sequencers rely on the journal not to lose transactions, refuse to
+		// run without it instead of silently disabling it.
+		if err := pool.events.load(add); err != nil {
+			close(pool.reorgShutdownCh)
+			pool.wg.Wait()
+			pool.events.close()
+			return fmt.Errorf("failed to load transaction event journal: %w", err)
+		}
+		pool.events.start(pool.config.Rejournal)
+	}
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -420,6 +450,9 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.events != nil {
+		pool.events.close()
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -826,7 +859,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
-		pool.journalTx(from, tx)
+		pool.journalTx(from, tx, isLocal)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
@@ -848,7 +881,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	if isLocal {
 		localGauge.Inc(1)
 	}
-	pool.journalTx(from, tx)
+	pool.journalTx(from, tx, isLocal)
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replaced, nil
@@ -919,8 +952,15 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 }
 
 // journalTx adds the specified transaction to the local disk journal if it is
-// deemed to have been sent from a local account.
-func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
+// deemed to have been sent from a local account. If the event journal is enabled,
+// the transaction is recorded there regardless of its origin.
+func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction, local bool) {
+	if pool.events != nil {
+		if err := pool.events.insert(tx, local); err != nil {
+			log.Warn("Failed to journal transaction", "hash", tx.Hash(), "err", err)
+		}
+		return
+	}
 	// Only journal if it's enabled and the transaction is local
 	if pool.journal == nil || (!pool.config.JournalRemote && !pool.locals.contains(from)) {
 		return
@@ -1869,6 +1909,8 @@ type lookup struct {
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
+
+	onRemove func(hash common.Hash) // Optional callback on transaction removal (event journal)
 }
 
 // newLookup returns a new lookup structure.
@@ -1994,6 +2036,10 @@ func (t *lookup) Remove(hash common.Hash) {
 
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
+
+	if t.onRemove != nil {
+		t.onRemove(hash)
+	}
 }
 
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
@@ -77,6 +77,9 @@ type TxPool struct {
 	term chan struct{}           // Termination channel to detect a closed pool
 
 	sync chan chan error // Testing / simulator channel to block until internal reset is done
+
+	policies   []AdmissionPolicy // Operator supplied admission rules checked before the subpools
+	policyLock sync.RWMutex      // Lock protecting the admission policies
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -319,11 +322,18 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	// so we can piece back the returned errors into the original order.
 	txsets := make([][]*types.Transaction, len(p.subpools))
 	splits := make([]int, len(txs))
+	errs := make([]error, len(txs))
 
 	for i, tx := range txs {
 		// Mark this transaction belonging to no-subpool
 		splits[i] = -1
 
+		// Reject the transaction upfront if an admission policy forbids it
+		if err := p.admit(tx, local); err != nil {
+			errs[i] = err
+			continue
+		}
+
 		// Try to find a subpool that accepts the transaction
 		for j, subpool := range p.subpools {
 			if subpool.Filter(tx) {
@@ -339,11 +349,12 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	for i := 0; i < len(p.subpools); i++ {
 		errsets[i] = p.subpools[i].Add(txsets[i], local, sync)
 	}
-	errs := make([]error, len(txs))
 	for i, split := range splits {
 		// If the transaction was rejected by all subpools, mark it unsupported
 		if split == -1 {
-			errs[i] = core.ErrTxTypeNotSupported
+			if errs[i] == nil {
+				errs[i] = core.ErrTxTypeNotSupported
+			}
 			continue
 		}
 		// Find which subpool handled it and pull in the corresponding error
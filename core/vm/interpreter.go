@@ -40,6 +40,7 @@ type Config struct {
 	ExtraEips               []int // Additional EIPS that are to be enabled
 
 	StatelessSelfValidation bool // Generate execution witnesses and self-check against them (testing purpose)
+	ParallelExecution       bool // Execute the transactions of imported blocks optimistically in parallel
 
 	PrecompileOverrides PrecompileOverrides             // Precompiles can be swapped / changed / wrapped as needed
 	NoMaxCodeSize       bool                            // Ignore Max code size and max init code size limits
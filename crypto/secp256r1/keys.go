@@ -0,0 +1,114 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// SignatureLength is the length of a signature in the r || s format consumed by
+// the P256VERIFY precompile.
+const SignatureLength = 64
+
+// PublicKeyLength is the length of a public key in the x || y format consumed by
+// the P256VERIFY precompile.
+const PublicKeyLength = 64
+
+var (
+	errInvalidPrivateKey = errors.New("invalid secp256r1 private key")
+	errInvalidPublicKey  = errors.New("invalid secp256r1 public key")
+
+	halfOrder = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+)
+
+// GenerateKey generates a new secp256r1 private key.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// ToECDSA creates a secp256r1 private key from its 32 byte big endian scalar.
+func ToECDSA(d []byte) (*ecdsa.PrivateKey, error) {
+	if len(d) != 32 {
+		return nil, errInvalidPrivateKey
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, errInvalidPrivateKey
+	}
+	// The uncompressed encoding of the public key is 0x04 || x || y
+	pub := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, nil
+}
+
+// FromECDSA exports a secp256r1 private key into its 32 byte big endian scalar.
+func FromECDSA(key *ecdsa.PrivateKey) []byte {
+	return math.PaddedBigBytes(key.D, 32)
+}
+
+// MarshalPubkey encodes a secp256r1 public key into the 64 byte x || y format.
+func MarshalPubkey(pub *ecdsa.PublicKey) []byte {
+	out := make([]byte, PublicKeyLength)
+	math.ReadBits(pub.X, out[:32])
+	math.ReadBits(pub.Y, out[32:])
+	return out
+}
+
+// UnmarshalPubkey decodes a secp256r1 public key from the 64 byte x || y format.
+func UnmarshalPubkey(pub []byte) (*ecdsa.PublicKey, error) {
+	if len(pub) != PublicKeyLength {
+		return nil, errInvalidPublicKey
+	}
+	key := newPublicKey(new(big.Int).SetBytes(pub[:32]), new(big.Int).SetBytes(pub[32:]))
+	if key == nil {
+		return nil, errInvalidPublicKey
+	}
+	return key, nil
+}
+
+// Sign calculates a secp256r1 signature of the given hash, returned in the
+// 64 byte r || s format. The s value is normalized into the lower half of the
+// curve order, as most smart account verifiers reject malleable signatures.
+func Sign(hash []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	if key.Curve != elliptic.P256() {
+		return nil, errInvalidPrivateKey
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash)
+	if err != nil {
+		return nil, err
+	}
+	if s.Cmp(halfOrder) > 0 {
+		s.Sub(key.Curve.Params().N, s)
+	}
+	sig := make([]byte, SignatureLength)
+	math.ReadBits(r, sig[:32])
+	math.ReadBits(s, sig[32:])
+	return sig, nil
+}
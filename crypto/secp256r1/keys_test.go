@@ -0,0 +1,46 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that signatures are normalized to the lower half of the curve order.
+func TestSignLowS(t *testing.T) {
+	t.Parallel()
+
+	key, _ := GenerateKey()
+	halfN := new(big.Int).Rsh(key.Curve.Params().N, 1)
+	for i := 0; i < 32; i++ {
+		hash := crypto.Keccak256([]byte{byte(i)})
+		sig, err := Sign(hash, key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if s.Cmp(halfN) > 0 {
+			t.Fatalf("signature %d has high s value", i)
+		}
+		if !Verify(hash, r, s, key.X, key.Y) {
+			t.Fatalf("signature %d does not verify", i)
+		}
+	}
+}
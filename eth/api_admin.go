@@ -17,15 +17,25 @@
 package eth
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -141,3 +151,309 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// txPoolSnapshotEntry is a single pooled transaction along with the metadata
+// needed to recreate it faithfully in another node's pool.
+type txPoolSnapshotEntry struct {
+	Tx          []byte // Binary transaction encoding
+	Time        uint64 // Arrival time of the transaction in unix nanoseconds
+	Local       bool   // Whether the sender is tracked as local
+	Queued      bool   // Whether the transaction was non-executable (informational)
+	Conditional []byte // JSON encoded transaction conditional, empty if none
+}
+
+// txPoolSnapshotter is the subset of the transaction pool needed to export and
+// import its contents.
+type txPoolSnapshotter interface {
+	Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction)
+	Locals() []common.Address
+	Add(txs []*types.Transaction, local bool, sync bool) []error
+}
+
+// TxPoolExportResult summarizes the outcome of a transaction pool export.
+type TxPoolExportResult struct {
+	Exported int `json:"exported"`
+	Skipped  int `json:"skipped"` // Blob transactions, exported without their sidecars by the pool
+}
+
+// TxPoolExportChunk is a part of a transaction pool export streamed over RPC.
+type TxPoolExportChunk struct {
+	TxPoolExportResult
+	Data hexutil.Bytes  `json:"data"`           // RLP stream of pool entries, importable on its own
+	Next *hexutil.Bytes `json:"next,omitempty"` // Cursor of the next chunk, nil if the export is complete
+}
+
+// TxPoolImportResult summarizes the outcome of a transaction pool import.
+type TxPoolImportResult struct {
+	Imported int      `json:"imported"`
+	Known    int      `json:"known"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"` // First few failure reasons
+}
+
+// txPoolCursor is the position of a pool entry in an export, which is ordered by
+// arrival time and transaction hash.
+type txPoolCursor struct {
+	Time uint64
+	Hash common.Hash
+}
+
+// maxTxPoolImportErrors is the maximum number of failure reasons reported back
+// from an import.
+const maxTxPoolImportErrors = 16
+
+// txPoolExportChunkSize is the size of the RLP data after which an export chunk
+// is cut. It keeps the hex encoded chunks within the default HTTP request limit
+// when they are passed on to ImportTxPool.
+var txPoolExportChunkSize = 1024 * 1024
+
+// ExportTxPool streams all pending and queued transactions of the pool, ordered by
+// arrival time, as RLP entries carrying the arrival time, the local flag and any
+// attached conditional. It is meant to prime a standby sequencer from the active
+// one over a private RPC before a failover, by passing the data of every chunk
+// to ImportTxPool on the standby.
+//
+// The export is returned in chunks: the first one is requested without a cursor,
+// the following ones with the cursor returned in the previous chunk, until no
+// cursor is returned. Transactions arriving in the pool meanwhile are included
+// in later chunks. Blob transactions are skipped, as the pool does not hand out
+// their sidecars.
+func (api *AdminAPI) ExportTxPool(cursor *hexutil.Bytes) (*TxPoolExportChunk, error) {
+	var from *txPoolCursor
+	if cursor != nil {
+		from = new(txPoolCursor)
+		if err := rlp.DecodeBytes(*cursor, from); err != nil {
+			return nil, fmt.Errorf("invalid cursor: %v", err)
+		}
+	}
+	var (
+		data   bytes.Buffer
+		signer = types.LatestSigner(api.eth.BlockChain().Config())
+	)
+	result, next, err := exportTxPool(api.eth.TxPool(), signer, &data, from, txPoolExportChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	chunk := &TxPoolExportChunk{TxPoolExportResult: *result, Data: data.Bytes()}
+	if next != nil {
+		enc, err := rlp.EncodeToBytes(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk.Next = (*hexutil.Bytes)(&enc)
+	}
+	return chunk, nil
+}
+
+// ImportTxPool injects the transactions of a chunk of a pool export created by
+// ExportTxPool, preserving their arrival time, conditional and local/remote
+// status.
+func (api *AdminAPI) ImportTxPool(data hexutil.Bytes) (*TxPoolImportResult, error) {
+	return importTxPool(api.eth.TxPool(), bytes.NewReader(data))
+}
+
+// ExportTxPoolFile dumps the complete export of ExportTxPool into a local file,
+// gzip compressed if the name ends in .gz.
+func (api *AdminAPI) ExportTxPoolFile(file string) (*TxPoolExportResult, error) {
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vector,
+		// since the 'file' may point to arbitrary paths on the drive.
+		return nil, errors.New("location would overwrite an existing file")
+	}
+	// Make sure we can create the file to export into
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	var (
+		buffered = bufio.NewWriter(out)
+		writer   = io.Writer(buffered)
+		gz       *gzip.Writer
+	)
+	if strings.HasSuffix(file, ".gz") {
+		gz = gzip.NewWriter(buffered)
+		writer = gz
+	}
+	// Export the transactions, flushing all buffers to the file
+	signer := types.LatestSigner(api.eth.BlockChain().Config())
+	result, _, err := exportTxPool(api.eth.TxPool(), signer, writer, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportTxPoolFile injects the transactions of a pool dump created by
+// ExportTxPoolFile from a local file.
+func (api *AdminAPI) ImportTxPoolFile(file string) (*TxPoolImportResult, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var reader io.Reader = bufio.NewReader(in)
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, err
+		}
+	}
+	return importTxPool(api.eth.TxPool(), reader)
+}
+
+// exportTxPool streams the contents of the pool following the given cursor as
+// RLP entries into w. Only the pooled transactions are held in memory, entries
+// are encoded one by one. If limit is positive, the export stops once that many
+// bytes were written and the cursor to continue from is returned.
+func exportTxPool(pool txPoolSnapshotter, signer types.Signer, w io.Writer, from *txPoolCursor, limit int) (*TxPoolExportResult, *txPoolCursor, error) {
+	locals := make(map[common.Address]struct{})
+	for _, addr := range pool.Locals() {
+		locals[addr] = struct{}{}
+	}
+	pending, queued := pool.Content()
+
+	type pooledTx struct {
+		tx     *types.Transaction
+		pos    txPoolCursor
+		queued bool
+	}
+	var txs []pooledTx
+	collect := func(content map[common.Address][]*types.Transaction, queued bool) {
+		for _, list := range content {
+			for _, tx := range list {
+				pos := txPoolCursor{Time: uint64(tx.Time().UnixNano()), Hash: tx.Hash()}
+				if from != nil && compareTxPoolCursors(pos, *from) <= 0 {
+					continue
+				}
+				txs = append(txs, pooledTx{tx: tx, pos: pos, queued: queued})
+			}
+		}
+	}
+	collect(pending, false)
+	collect(queued, true)
+
+	slices.SortFunc(txs, func(a, b pooledTx) int {
+		return compareTxPoolCursors(a.pos, b.pos)
+	})
+	var (
+		result  = new(TxPoolExportResult)
+		written int
+	)
+	for i, ptx := range txs {
+		if limit > 0 && written >= limit {
+			return result, &txs[i-1].pos, nil
+		}
+		if ptx.tx.Type() == types.BlobTxType {
+			result.Skipped++
+			continue
+		}
+		blob, err := ptx.tx.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		sender, _ := types.Sender(signer, ptx.tx) // already validated by the pool
+		_, local := locals[sender]
+
+		entry := &txPoolSnapshotEntry{
+			Tx:     blob,
+			Time:   ptx.pos.Time,
+			Local:  local,
+			Queued: ptx.queued,
+		}
+		if cond := ptx.tx.Conditional(); cond != nil {
+			if entry.Conditional, err = json.Marshal(cond); err != nil {
+				return nil, nil, err
+			}
+		}
+		enc, err := rlp.EncodeToBytes(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := w.Write(enc); err != nil {
+			return nil, nil, err
+		}
+		written += len(enc)
+		result.Exported++
+	}
+	if result.Skipped > 0 {
+		log.Warn("Skipped blob transactions in pool export", "skipped", result.Skipped)
+	}
+	log.Info("Exported transaction pool", "transactions", result.Exported)
+	return result, nil, nil
+}
+
+// compareTxPoolCursors orders pool entries by arrival time, and by hash if they
+// arrived at the same time.
+func compareTxPoolCursors(a, b txPoolCursor) int {
+	if a.Time != b.Time {
+		return cmp.Compare(a.Time, b.Time)
+	}
+	return a.Hash.Cmp(b.Hash)
+}
+
+// importTxPool parses a pool dump from r and adds its transactions to the pool
+// in their original order, batching consecutive entries with the same origin.
+func importTxPool(pool txPoolSnapshotter, r io.Reader) (*TxPoolImportResult, error) {
+	var (
+		stream = rlp.NewStream(r, 0)
+		result = new(TxPoolImportResult)
+		batch  []*types.Transaction
+		local  bool
+	)
+	flush := func() {
+		for _, err := range pool.Add(batch, local, true) {
+			switch {
+			case err == nil:
+				result.Imported++
+			case errors.Is(err, txpool.ErrAlreadyKnown):
+				result.Known++
+			default:
+				result.Failed++
+				if len(result.Errors) < maxTxPoolImportErrors {
+					result.Errors = append(result.Errors, err.Error())
+				}
+			}
+		}
+		batch = batch[:0]
+	}
+	for index := 0; ; index++ {
+		entry := new(txPoolSnapshotEntry)
+		if err := stream.Decode(entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("entry %d: failed to parse: %v", index, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(entry.Tx); err != nil {
+			return nil, fmt.Errorf("entry %d: failed to decode transaction: %v", index, err)
+		}
+		tx.SetTime(time.Unix(0, int64(entry.Time)))
+		if len(entry.Conditional) > 0 {
+			cond := new(types.TransactionConditional)
+			if err := json.Unmarshal(entry.Conditional, cond); err != nil {
+				return nil, fmt.Errorf("entry %d: failed to decode conditional: %v", index, err)
+			}
+			tx.SetConditional(cond)
+		}
+		if len(batch) > 0 && entry.Local != local {
+			flush()
+		}
+		local = entry.Local
+		batch = append(batch, tx)
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	log.Info("Imported transaction pool", "imported", result.Imported, "known", result.Known, "failed", result.Failed)
+	return result, nil
+}
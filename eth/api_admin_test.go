@@ -0,0 +1,287 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// snapshotTxPool is a mock transaction pool serving fixed contents and recording
+// the transactions added to it along with their origin.
+type snapshotTxPool struct {
+	pending, queued map[common.Address][]*types.Transaction
+	locals          []common.Address
+
+	added  []*types.Transaction
+	origin []bool
+}
+
+func (p *snapshotTxPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return p.pending, p.queued
+}
+
+func (p *snapshotTxPool) Locals() []common.Address { return p.locals }
+
+func (p *snapshotTxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i, tx := range txs {
+		for _, known := range p.added {
+			if known.Hash() == tx.Hash() {
+				errs[i] = txpool.ErrAlreadyKnown
+			}
+		}
+		if errs[i] == nil {
+			p.added = append(p.added, tx)
+			p.origin = append(p.origin, local)
+		}
+	}
+	return errs
+}
+
+// Tests that a transaction pool export can be imported into another pool while
+// preserving arrival order, local status and conditionals.
+func TestTxPoolExportImport(t *testing.T) {
+	var (
+		signer       = types.LatestSigner(params.TestChainConfig)
+		remoteKey, _ = crypto.GenerateKey()
+		remote       = crypto.PubkeyToAddress(remoteKey.PublicKey)
+		base         = time.Unix(1700000000, 0)
+	)
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, at time.Duration) *types.Transaction {
+		tx := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)})
+		tx.SetTime(base.Add(at))
+		return tx
+	}
+	var (
+		local0  = sign(testKey, 0, 2*time.Second)
+		local2  = sign(testKey, 2, 3*time.Second)
+		remote0 = sign(remoteKey, 0, time.Second)
+		remote1 = sign(remoteKey, 1, 4*time.Second)
+	)
+	remote1.SetConditional(&types.TransactionConditional{BlockNumberMin: big.NewInt(7)})
+
+	// Blob transactions lack their sidecars in the pool content, they must be skipped
+	blobTx := types.NewTx(&types.BlobTx{Nonce: 2, Gas: 21000, BlobHashes: []common.Hash{{0x01}}})
+	blobTx.SetTime(base)
+
+	source := &snapshotTxPool{
+		pending: map[common.Address][]*types.Transaction{
+			testAddr: {local0},
+			remote:   {remote0, remote1, blobTx},
+		},
+		queued: map[common.Address][]*types.Transaction{
+			testAddr: {local2},
+		},
+		locals: []common.Address{testAddr},
+	}
+	var dump bytes.Buffer
+	exported, next, err := exportTxPool(source, signer, &dump, nil, 0)
+	if err != nil {
+		t.Fatalf("failed to export pool: %v", err)
+	}
+	if exported.Exported != 4 || exported.Skipped != 1 || next != nil {
+		t.Fatalf("export result mismatch: have %+v, cursor %v", exported, next)
+	}
+	sink := new(snapshotTxPool)
+	sink.Add([]*types.Transaction{local0}, true, true) // pre-existing, should be reported known
+
+	result, err := importTxPool(sink, bytes.NewReader(dump.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to import pool: %v", err)
+	}
+	if result.Imported != 3 || result.Known != 1 || result.Failed != 0 {
+		t.Fatalf("import result mismatch: have %+v", result)
+	}
+	want := []*types.Transaction{local0, remote0, local2, remote1}
+	origin := []bool{true, false, true, false}
+	for i, tx := range sink.added {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("tx %d: order mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+		if !tx.Time().Equal(want[i].Time()) {
+			t.Errorf("tx %d: time mismatch: have %v, want %v", i, tx.Time(), want[i].Time())
+		}
+		if sink.origin[i] != origin[i] {
+			t.Errorf("tx %d: local flag mismatch: have %v, want %v", i, sink.origin[i], origin[i])
+		}
+	}
+	if cond := sink.added[3].Conditional(); cond == nil || cond.BlockNumberMin.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("conditional not preserved: %v", cond)
+	}
+}
+
+// Tests that an export read in chunks, each continuing from the cursor of the
+// previous one, contains every entry exactly once, including transactions that
+// arrive in the pool in between.
+func TestTxPoolExportChunks(t *testing.T) {
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		base   = time.Unix(1700000000, 0)
+		source = &snapshotTxPool{pending: make(map[common.Address][]*types.Transaction)}
+	)
+	add := func(nonce uint64, at time.Time) {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)})
+		tx.SetTime(at)
+		source.pending[testAddr] = append(source.pending[testAddr], tx)
+	}
+	for i := 0; i < 5; i++ {
+		add(uint64(i), base) // same arrival time, ordered by hash
+	}
+	var (
+		sink   = new(snapshotTxPool)
+		cursor *txPoolCursor
+		chunks int
+	)
+	for {
+		var data bytes.Buffer
+		result, next, err := exportTxPool(source, signer, &data, cursor, 1)
+		if err != nil {
+			t.Fatalf("chunk %d: failed to export: %v", chunks, err)
+		}
+		if result.Exported != 1 {
+			t.Fatalf("chunk %d: exported %d entries, want 1", chunks, result.Exported)
+		}
+		if _, err := importTxPool(sink, &data); err != nil {
+			t.Fatalf("chunk %d: failed to import: %v", chunks, err)
+		}
+		if chunks++; chunks == 2 {
+			add(5, base.Add(time.Second)) // arrives during the export
+		}
+		if cursor = next; cursor == nil {
+			break
+		}
+	}
+	if chunks != 6 || len(sink.added) != 6 {
+		t.Fatalf("export mismatch: have %d chunks with %d transactions, want 6", chunks, len(sink.added))
+	}
+	for i, tx := range sink.added[:5] {
+		if tx.Nonce() == 5 || (i > 0 && tx.Hash().Cmp(sink.added[i-1].Hash()) <= 0) {
+			t.Errorf("tx %d: out of order", i)
+		}
+	}
+}
+
+// newTxPoolTestNode starts a node running a full Ethereum service with a
+// funded test account.
+func newTxPoolTestNode(t *testing.T) (*node.Node, *Ethereum) {
+	t.Helper()
+
+	stack, err := node.New(&node.Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+
+	config := ethconfig.Defaults
+	config.Genesis = &core.Genesis{
+		Config:  params.AllEthashProtocolChanges,
+		Alloc:   types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	backend, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	return stack, backend
+}
+
+// Tests that the pool of one node can be moved into another one purely over
+// RPC, in several chunks.
+func TestTxPoolTransferRPC(t *testing.T) {
+	defer func(size int) { txPoolExportChunkSize = size }(txPoolExportChunkSize)
+	txPoolExportChunkSize = 1
+
+	var (
+		srcNode, src = newTxPoolTestNode(t)
+		dstNode, dst = newTxPoolTestNode(t)
+		signer       = types.LatestSigner(src.BlockChain().Config())
+		txs          []*types.Transaction
+	)
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		txs = append(txs, types.MustSignNewTx(testKey, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			Nonce:     nonce,
+			To:        &common.Address{0x01},
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(10 * params.GWei),
+			GasTipCap: big.NewInt(params.GWei),
+		}))
+	}
+	for i, err := range src.TxPool().Add(txs, true, true) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add to source pool: %v", i, err)
+		}
+	}
+	srcClient, dstClient := srcNode.Attach(), dstNode.Attach()
+
+	var (
+		cursor   *hexutil.Bytes
+		imported int
+	)
+	for chunks := 0; ; chunks++ {
+		if chunks > len(txs) {
+			t.Fatalf("export did not terminate")
+		}
+		var chunk TxPoolExportChunk
+		if err := srcClient.Call(&chunk, "admin_exportTxPool", cursor); err != nil {
+			t.Fatalf("chunk %d: failed to export: %v", chunks, err)
+		}
+		var result TxPoolImportResult
+		if err := dstClient.Call(&result, "admin_importTxPool", chunk.Data); err != nil {
+			t.Fatalf("chunk %d: failed to import: %v", chunks, err)
+		}
+		if result.Failed != 0 {
+			t.Fatalf("chunk %d: import failed: %v", chunks, result.Errors)
+		}
+		imported += result.Imported
+		if cursor = chunk.Next; cursor == nil {
+			break
+		}
+	}
+	if imported != len(txs) {
+		t.Fatalf("imported transaction count mismatch: have %d, want %d", imported, len(txs))
+	}
+	pending, _ := dst.TxPool().Content()
+	if len(pending[testAddr]) != len(txs) {
+		t.Fatalf("pending transaction count mismatch: have %d, want %d", len(pending[testAddr]), len(txs))
+	}
+	for i, tx := range pending[testAddr] {
+		if tx.Hash() != txs[i].Hash() {
+			t.Errorf("tx %d: hash mismatch: have %x, want %x", i, tx.Hash(), txs[i].Hash())
+		}
+	}
+	if locals := dst.TxPool().Locals(); len(locals) != 1 || locals[0] != testAddr {
+		t.Errorf("local accounts mismatch: have %v, want [%v]", locals, testAddr)
+	}
+}
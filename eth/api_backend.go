@@ -297,7 +297,9 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 		if err != nil {
 			return err
 		}
+		b.eth.seqForwards.Add(1)
 		if err := b.eth.seqRPCService.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data)); err != nil {
+			b.eth.seqForwardErrors.Add(1)
 			return err
 		}
 		if b.disableTxPool {
@@ -315,6 +317,35 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
+// SequencerForwardStats returns the number of transactions forwarded to the
+// sequencer and the number of failed forwards.
+func (b *EthAPIBackend) SequencerForwardStats() (forwarded uint64, failed uint64) {
+	return b.eth.seqForwards.Load(), b.eth.seqForwardErrors.Load()
+}
+
+// SequencerForwarding reports whether transactions are forwarded to a sequencer.
+func (b *EthAPIBackend) SequencerForwarding() bool {
+	return b.eth.seqRPCService != nil
+}
+
+// TxPoolAdmission reports whether submitted transactions are admitted to the
+// local pool, and the names of the admission policies applied to them.
+func (b *EthAPIBackend) TxPoolAdmission() (bool, []string) {
+	return !b.disableTxPool, b.eth.txPool.AdmissionPolicies()
+}
+
+// ProtocolVersions returns the superchain protocol versions last signaled by
+// the rollup node.
+func (b *EthAPIBackend) ProtocolVersions() (recommended, required params.ProtocolVersion) {
+	return b.eth.ProtocolVersions()
+}
+
+// ProtocolHalted reports whether the node is halting on an incompatible required
+// protocol version.
+func (b *EthAPIBackend) ProtocolHalted() bool {
+	return b.eth.ProtocolHalted()
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(txpool.PendingFilter{})
 	var txs types.Transactions
@@ -17,9 +17,11 @@
 package eth
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -40,7 +42,8 @@ import (
 // DebugAPI is the collection of Ethereum full node APIs for debugging the
 // protocol.
 type DebugAPI struct {
-	eth *Ethereum
+	eth        *Ethereum
+	inspecting atomic.Bool // Whether an online database inspection is running
 }
 
 // NewDebugAPI creates a new DebugAPI instance.
@@ -449,7 +452,29 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
 
-func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*stateless.ExecutionWitness, error) {
+// ExecutionWitnessConfig selects the encoding of the witnesses returned by
+// debug_executionWitness.
+type ExecutionWitnessConfig struct {
+	// Format is the encoding of the witness: "json" for the JSON object keyed by
+	// hashes (default), "rlp" for the consensus RLP encoding or "compact" for the
+	// deduplicated and compressed encoding. Binary formats are returned hex encoded.
+	Format string `json:"format"`
+
+	// Grouped orders the trie nodes of the compact encoding by account, which
+	// compresses better but is slower to produce.
+	Grouped bool `json:"grouped"`
+}
+
+// ExecutionWitness generates the witness of a block, which contains all the
+// state and code needed to execute it statelessly.
+func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *ExecutionWitnessConfig) (interface{}, error) {
+	format := "json"
+	if config != nil && config.Format != "" {
+		format = config.Format
+	}
+	if format != "json" && format != "rlp" && format != "compact" {
+		return nil, fmt.Errorf("unknown witness format %q", format)
+	}
 	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve block: %w", err)
@@ -459,7 +484,19 @@ func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.Blo
 	}
 
 	witness, err := generateWitness(api.eth.blockchain, block)
-	return witness.ToExecutionWitness(), err
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "rlp":
+		blob, err := rlp.EncodeToBytes(witness)
+		return hexutil.Bytes(blob), err
+	case "compact":
+		blob, err := witness.EncodeCompact(config.Grouped)
+		return hexutil.Bytes(blob), err
+	default:
+		return witness.ToExecutionWitness(), nil
+	}
 }
 
 func generateWitness(blockchain *core.BlockChain, block *types.Block) (*stateless.Witness, error) {
@@ -488,3 +525,180 @@ func generateWitness(blockchain *core.BlockChain, block *types.Block) (*stateles
 
 	return witness, nil
 }
+
+// dbInspectRateLimit is the default number of bytes read per second by an online
+// database inspection, leaving most of the disk bandwidth to block processing.
+const dbInspectRateLimit = 64 * 1024 * 1024
+
+// DbInspectConfig holds the optional parameters of an online database inspection.
+type DbInspectConfig struct {
+	Prefix    hexutil.Bytes   `json:"prefix"`
+	Start     hexutil.Bytes   `json:"start"`
+	RateLimit *hexutil.Uint64 `json:"rateLimit"` // Bytes per second, zero for unlimited
+}
+
+// DbInspectResult is a notification of an online database inspection, either
+// reporting progress, or the final statistics once done.
+type DbInspectResult struct {
+	Count uint64               `json:"count"`
+	Key   hexutil.Bytes        `json:"key,omitempty"`
+	Stats *rawdb.DatabaseStats `json:"stats,omitempty"`
+	Error string               `json:"error,omitempty"`
+}
+
+// DbInspect traverses the live database and checks the size of all different
+// categories of data, like `geth db inspect` does on a stopped node. The progress
+// is streamed periodically, followed by the statistics. Only one inspection can
+// run at a time, and it is rate limited to avoid starving block processing.
+func (api *DebugAPI) DbInspect(ctx context.Context, config *DbInspectConfig) (*rpc.Subscription, error) {
+	// Inspecting a database is a **long** operation, only do with subscriptions
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if !api.inspecting.CompareAndSwap(false, true) {
+		return nil, errors.New("database inspection already in progress")
+	}
+	if config == nil {
+		config = new(DbInspectConfig)
+	}
+	limit := dbInspectRateLimit
+	if config.RateLimit != nil {
+		limit = int(*config.RateLimit)
+	}
+	sub := notifier.CreateSubscription()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-sub.Err():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go func() {
+		defer api.inspecting.Store(false)
+		defer cancel()
+
+		stats, err := rawdb.InspectDatabaseStats(ctx, api.eth.ChainDb(), &rawdb.InspectConfig{
+			Prefix:    config.Prefix,
+			Start:     config.Start,
+			RateLimit: limit,
+			Progress: func(count int64, key []byte) {
+				notifier.Notify(sub.ID, &DbInspectResult{Count: uint64(count), Key: common.CopyBytes(key)})
+			},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				notifier.Notify(sub.ID, &DbInspectResult{Error: err.Error()})
+			}
+			return
+		}
+		var count uint64
+		for _, stat := range stats.Stats {
+			count += stat.Count
+		}
+		notifier.Notify(sub.ID, &DbInspectResult{Count: count, Stats: stats})
+	}()
+	return sub, nil
+}
+
+// FreezerStats returns the size and item count of the tables of the chain freezer.
+// The state freezers are held open by the node and are not included.
+func (api *DebugAPI) FreezerStats() ([]rawdb.DatabaseStat, error) {
+	return rawdb.InspectFreezers(api.eth.ChainDb())
+}
+
+// SnapshotAccountLayer is the state of an account in a layer of the snapshot tree.
+type SnapshotAccountLayer struct {
+	Root      common.Hash                   `json:"root"`
+	Disk      bool                          `json:"disk"`
+	Nonce     *hexutil.Uint64               `json:"nonce,omitempty"`
+	Balance   *hexutil.U256                 `json:"balance,omitempty"`
+	StateRoot *common.Hash                  `json:"stateRoot,omitempty"`
+	CodeHash  *common.Hash                  `json:"codeHash,omitempty"`
+	Storage   map[common.Hash]hexutil.Bytes `json:"storage,omitempty"`
+}
+
+// SnapshotAccount looks up an account, given by address or hash, and optionally
+// some of its storage slots across the layers of the live snapshot tree, like
+// `geth snapshot inspect-account` does on a stopped node. The disk layer and the
+// diff layers modifying the account or the slots are returned, topmost first. A
+// layer without account fields means the account doesn't exist in it.
+func (api *DebugAPI) SnapshotAccount(account string, slots []common.Hash) ([]*SnapshotAccountLayer, error) {
+	var hash common.Hash
+	switch len(account) {
+	case 40, 42:
+		hash = crypto.Keccak256Hash(common.HexToAddress(account).Bytes())
+	case 64, 66:
+		hash = common.HexToHash(account)
+	default:
+		return nil, errors.New("malformed address or hash")
+	}
+	snaps := api.eth.BlockChain().Snapshots()
+	if snaps == nil {
+		return nil, errors.New("snapshots not available")
+	}
+	root := api.eth.BlockChain().CurrentBlock().Root
+	layers := snaps.Snapshots(root, -1, false)
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("snapshot of head state %x not available", root)
+	}
+	// Read the account from all layers, which fall through to their parents, and
+	// report the ones differing from their parent from the bottom up.
+	var (
+		results []*SnapshotAccountLayer
+		prev    [][]byte
+	)
+	for i := len(layers) - 1; i >= 0; i-- {
+		blob, err := layers[i].AccountRLP(hash)
+		if err != nil {
+			return nil, err
+		}
+		values := [][]byte{blob}
+		for _, slot := range slots {
+			value, err := layers[i].Storage(hash, slot)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if i < len(layers)-1 && equalBlobs(values, prev) {
+			continue
+		}
+		prev = values
+
+		result := &SnapshotAccountLayer{Root: layers[i].Root(), Disk: i == len(layers)-1}
+		if len(blob) > 0 {
+			acct, err := types.FullAccount(blob)
+			if err != nil {
+				return nil, err
+			}
+			nonce, stateRoot := hexutil.Uint64(acct.Nonce), acct.Root
+			codeHash := common.BytesToHash(acct.CodeHash)
+			result.Nonce, result.Balance = &nonce, (*hexutil.U256)(acct.Balance)
+			result.StateRoot, result.CodeHash = &stateRoot, &codeHash
+		}
+		if len(slots) > 0 {
+			result.Storage = make(map[common.Hash]hexutil.Bytes)
+			for j, slot := range slots {
+				result.Storage[slot] = values[j+1]
+			}
+		}
+		results = append([]*SnapshotAccountLayer{result}, results...)
+	}
+	return results, nil
+}
+
+// equalBlobs reports whether two lists of blobs are equal.
+func equalBlobs(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
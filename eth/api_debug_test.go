@@ -18,12 +18,14 @@ package eth
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 	"reflect"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
@@ -36,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
@@ -257,3 +260,86 @@ func TestExecutionWitness(t *testing.T) {
 	_, _, err = core.ExecuteStateless(params.TestChainConfig, block, witness)
 	require.NoError(t, err)
 }
+
+// Tests that the database can be inspected online, and that accounts can be
+// looked up across the snapshot layers.
+func TestOnlineInspection(t *testing.T) {
+	t.Parallel()
+
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		to    = common.Address{0x01}
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	chain, _ := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, func(i int, b *core.BlockGen) {
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), to, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, testKey)
+			b.AddTx(tx)
+		}
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewDebugAPI(&Ethereum{blockchain: chain, chainDb: db})
+
+	// The recipient is missing from the disk layer, and modified in every other layer
+	layers, err := api.SnapshotAccount(to.Hex(), []common.Hash{{}})
+	if err != nil {
+		t.Fatalf("failed to look up account: %v", err)
+	}
+	if len(layers) != 6 {
+		t.Fatalf("layer count mismatch: have %d, want 6", len(layers))
+	}
+	if last := layers[len(layers)-1]; !last.Disk || last.Balance != nil {
+		t.Fatalf("unexpected disk layer: %+v", last)
+	}
+	if head := layers[0]; head.Root != blocks[8].Root() || (*uint256.Int)(head.Balance).Uint64() != 5 {
+		t.Fatalf("unexpected head layer: %+v", head)
+	}
+	if _, err := api.SnapshotAccount("0x1234", nil); err == nil {
+		t.Fatalf("malformed account accepted")
+	}
+	// The inspection needs a subscription
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", api); err != nil {
+		t.Fatalf("failed to register debug api: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	results := make(chan *DbInspectResult)
+	sub, err := client.Subscribe(context.Background(), "debug", results, "dbInspect", nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case result := <-results:
+		if result.Error != "" || result.Stats == nil {
+			t.Fatalf("inspection failed: %+v", result)
+		}
+		for _, stat := range result.Stats.Stats {
+			if stat.Database == "Key-Value store" && stat.Category == "Headers" && stat.Count != 11 {
+				t.Fatalf("header count mismatch: have %d, want 11", stat.Count)
+			}
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("inspection timed out")
+	}
+}
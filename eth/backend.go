@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -78,6 +79,11 @@ type Ethereum struct {
 
 	seqRPCService        *rpc.Client
 	historicalRPCService *rpc.Client
+	syncRPCService       *rpc.Client
+
+	seqForwards      atomic.Uint64                             // Number of transactions forwarded to the sequencer
+	seqForwardErrors atomic.Uint64                             // Number of transactions the sequencer failed to accept
+	protocolVersions atomic.Pointer[[2]params.ProtocolVersion] // Last recommended and required superchain versions signaled
 
 	// DB interfaces
 	chainDb ethdb.Database // Block chain database
@@ -192,6 +198,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			ParallelExecution:       config.ParallelExecution,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -263,6 +270,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.EventJournal != "" {
+		config.TxPool.EventJournal = stack.ResolvePath(config.TxPool.EventJournal)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	txPools := []txpool.SubPool{legacyPool}
@@ -274,8 +284,20 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(config.TxPoolAdmissionPolicies) > 0 {
+		eth.txPool.SetAdmissionPolicies(config.TxPoolAdmissionPolicies...)
+	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
+	if config.RollupSyncRPC != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		client, err := rpc.DialContext(ctx, config.RollupSyncRPC)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		eth.syncRPCService = client
+	}
 	if eth.handler, err = newHandler(&handlerConfig{
 		NodeID:         eth.p2pServer.Self().ID(),
 		Database:       chainDb,
@@ -287,6 +309,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		EventMux:       eth.eventMux,
 		RequiredBlocks: config.RequiredBlocks,
 		NoTxGossip:     config.RollupDisableTxPoolGossip,
+		SyncRPC:        eth.syncRPCService,
 	}); err != nil {
 		return nil, err
 	}
@@ -483,6 +506,9 @@ func (s *Ethereum) Stop() error {
 	if s.historicalRPCService != nil {
 		s.historicalRPCService.Close()
 	}
+	if s.syncRPCService != nil {
+		s.syncRPCService.Close()
+	}
 
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
@@ -519,9 +545,41 @@ func (s *Ethereum) SyncMode() downloader.SyncMode {
 	return downloader.FullSync
 }
 
+// SetProtocolVersions records the recommended and required superchain protocol
+// versions last signaled by the rollup node.
+func (s *Ethereum) SetProtocolVersions(recommended, required params.ProtocolVersion) {
+	s.protocolVersions.Store(&[2]params.ProtocolVersion{recommended, required})
+}
+
+// ProtocolVersions returns the recommended and required superchain protocol
+// versions last signaled by the rollup node, empty if none was signaled yet.
+func (s *Ethereum) ProtocolVersions() (recommended, required params.ProtocolVersion) {
+	if versions := s.protocolVersions.Load(); versions != nil {
+		return versions[0], versions[1]
+	}
+	return params.ProtocolVersion{}, params.ProtocolVersion{}
+}
+
+// ProtocolHalted reports whether the required protocol version last signaled by
+// the rollup node is incompatible at the level the node opted to halt at.
+func (s *Ethereum) ProtocolHalted() bool {
+	_, required := s.ProtocolVersions()
+	return s.haltRequired(required)
+}
+
 // HandleRequiredProtocolVersion handles the protocol version signal. This implements opt-in halting,
 // the protocol version data is already logged and metered when signaled through the Engine API.
 func (s *Ethereum) HandleRequiredProtocolVersion(required params.ProtocolVersion) error {
+	if s.haltRequired(required) {
+		log.Error("Opted to halt, unprepared for protocol change", "required", required, "local", params.OPStackSupport)
+		return s.nodeCloser()
+	}
+	return nil
+}
+
+// haltRequired reports whether the given required protocol version is incompatible
+// at the granularity the node opted to halt at.
+func (s *Ethereum) haltRequired(required params.ProtocolVersion) bool {
 	var needLevel int
 	switch s.config.RollupHaltOnIncompatibleProtocolVersion {
 	case "major":
@@ -531,7 +589,7 @@ func (s *Ethereum) HandleRequiredProtocolVersion(required params.ProtocolVersion
 	case "patch":
 		needLevel = 1
 	default:
-		return nil // do not consider halting if not configured to
+		return false // do not consider halting if not configured to
 	}
 	haveLevel := 0
 	switch params.OPStackSupport.Compare(required) {
@@ -542,9 +600,5 @@ func (s *Ethereum) HandleRequiredProtocolVersion(required params.ProtocolVersion
 	case params.OutdatedPatch:
 		haveLevel = 1
 	}
-	if haveLevel >= needLevel { // halt if we opted in to do so at this granularity
-		log.Error("Opted to halt, unprepared for protocol change", "required", required, "local", params.OPStackSupport)
-		return s.nodeCloser()
-	}
-	return nil
+	return haveLevel >= needLevel // halt if we opted in to do so at this granularity
 }
@@ -0,0 +1,321 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// rpcPeerTimeout is the maximum time a batch of requests to a trusted RPC peer
+// may take, and the time a response is waited on to be picked up.
+var rpcPeerTimeout = 30 * time.Second
+
+// RPCPeer is a sync peer retrieving chain data from a trusted JSON-RPC endpoint
+// instead of the devp2p network, for chains with few peers serving it.
+//
+// The peer only retrieves headers, bodies and receipts: state ranges served over
+// RPC lack the proofs needed to validate them against the state root. Everything
+// retrieved is validated by the downloader exactly like data from regular peers,
+// against the skeleton anchored at the beacon head.
+type RPCPeer struct {
+	id     string
+	client *rpc.Client
+	logger log.Logger
+}
+
+// NewRPCPeer creates a sync peer retrieving chain data through the given client.
+func NewRPCPeer(id string, client *rpc.Client) *RPCPeer {
+	return &RPCPeer{
+		id:     id,
+		client: client,
+		logger: log.New("peer", id),
+	}
+}
+
+// ID returns the identifier the peer is registered with.
+func (p *RPCPeer) ID() string {
+	return p.id
+}
+
+// rpcHeader is a header as returned by the RPC API, along with the hash the
+// remote side calculated for it.
+type rpcHeader struct {
+	Hash common.Hash `json:"hash"`
+}
+
+// Head retrieves the current head hash of the remote node. The total difficulty
+// is meaningless for beacon sync and is always zero.
+func (p *RPCPeer) Head() (common.Hash, *big.Int) {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcPeerTimeout)
+	defer cancel()
+
+	var head *rpcHeader
+	if err := p.client.CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil || head == nil {
+		p.logger.Debug("Failed to retrieve RPC peer head", "err", err)
+		return common.Hash{}, new(big.Int)
+	}
+	return head.Hash, new(big.Int)
+}
+
+// RequestHeadersByHash retrieves a batch of headers starting at the one with the
+// given hash.
+func (p *RPCPeer) RequestHeadersByHash(origin common.Hash, amount int, skip int, reverse bool, sink chan *eth.Response) (*eth.Request, error) {
+	p.logger.Trace("Fetching batch of headers", "count", amount, "fromhash", origin, "skip", skip, "reverse", reverse)
+	return p.request(sink, func(ctx context.Context) (interface{}, interface{}, error) {
+		var raw json.RawMessage
+		if err := p.client.CallContext(ctx, &raw, "eth_getBlockByHash", origin, false); err != nil {
+			return nil, nil, err
+		}
+		first, err := decodeRPCHeader(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		if first == nil {
+			return emptyHeaders()
+		}
+		return p.fetchHeaders(ctx, first.Number.Uint64(), amount, skip, reverse)
+	}, emptyHeaders)
+}
+
+// RequestHeadersByNumber retrieves a batch of headers starting at the given number.
+func (p *RPCPeer) RequestHeadersByNumber(origin uint64, amount int, skip int, reverse bool, sink chan *eth.Response) (*eth.Request, error) {
+	p.logger.Trace("Fetching batch of headers", "count", amount, "fromnum", origin, "skip", skip, "reverse", reverse)
+	return p.request(sink, func(ctx context.Context) (interface{}, interface{}, error) {
+		return p.fetchHeaders(ctx, origin, amount, skip, reverse)
+	}, emptyHeaders)
+}
+
+// fetchHeaders retrieves the headers of a header query with eth_getBlockByNumber,
+// returning them along with their hashes. The batch is cut at the first header
+// the remote node doesn't have.
+func (p *RPCPeer) fetchHeaders(ctx context.Context, origin uint64, amount int, skip int, reverse bool) (interface{}, interface{}, error) {
+	var (
+		raws  = make([]json.RawMessage, 0, amount)
+		batch = make([]rpc.BatchElem, 0, amount)
+	)
+	for i := 0; i < amount; i++ {
+		step := uint64(i) * uint64(skip+1)
+		if reverse && step > origin {
+			break
+		}
+		number := origin + step
+		if reverse {
+			number = origin - step
+		}
+		raws = append(raws, nil)
+		batch = append(batch, rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.Uint64(number), false},
+			Result: &raws[len(raws)-1],
+		})
+	}
+	if err := p.client.BatchCallContext(ctx, batch); err != nil {
+		return nil, nil, err
+	}
+	var (
+		headers = make([]*types.Header, 0, len(batch))
+		hashes  = make([]common.Hash, 0, len(batch))
+	)
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, nil, elem.Error
+		}
+		header, err := decodeRPCHeader(raws[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+		hashes = append(hashes, header.Hash())
+	}
+	return (*eth.BlockHeadersRequest)(&headers), hashes, nil
+}
+
+// decodeRPCHeader decodes a header returned by the RPC API, ensuring it hashes
+// to the value reported by the remote node. Nil is returned for missing headers.
+func decodeRPCHeader(raw json.RawMessage) (*types.Header, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var (
+		header types.Header
+		meta   rpcHeader
+	)
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, err
+	}
+	if hash := header.Hash(); hash != meta.Hash {
+		return nil, fmt.Errorf("header %d hash mismatch: have %x, remote %x", header.Number, hash, meta.Hash)
+	}
+	return &header, nil
+}
+
+// emptyHeaders returns an empty header response.
+func emptyHeaders() (interface{}, interface{}, error) {
+	return &eth.BlockHeadersRequest{}, []common.Hash{}, nil
+}
+
+// RequestBodies retrieves the bodies of the blocks with the given hashes using
+// debug_getRawBlock.
+func (p *RPCPeer) RequestBodies(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
+	p.logger.Trace("Fetching batch of block bodies", "count", len(hashes))
+	return p.request(sink, func(ctx context.Context) (interface{}, interface{}, error) {
+		var (
+			raws  = make([]hexutil.Bytes, len(hashes))
+			batch = make([]rpc.BatchElem, len(hashes))
+		)
+		for i, hash := range hashes {
+			batch[i] = rpc.BatchElem{Method: "debug_getRawBlock", Args: []interface{}{hash}, Result: &raws[i]}
+		}
+		if err := p.client.BatchCallContext(ctx, batch); err != nil {
+			return nil, nil, err
+		}
+		bodies := make([]*eth.BlockBody, 0, len(batch))
+		for i, elem := range batch {
+			if elem.Error != nil {
+				break // Deliver the bodies retrieved up to the first missing one
+			}
+			var block types.Block
+			if err := rlp.DecodeBytes(raws[i], &block); err != nil {
+				return nil, nil, err
+			}
+			body := block.Body()
+			bodies = append(bodies, &eth.BlockBody{
+				Transactions: body.Transactions,
+				Uncles:       body.Uncles,
+				Withdrawals:  body.Withdrawals,
+				Requests:     body.Requests,
+			})
+		}
+		return (*eth.BlockBodiesResponse)(&bodies), bodyHashes(bodies), nil
+	}, func() (interface{}, interface{}, error) {
+		bodies := eth.BlockBodiesResponse{}
+		return &bodies, bodyHashes(nil), nil
+	})
+}
+
+// bodyHashes calculates the metadata of block bodies delivered to the downloader.
+func bodyHashes(bodies []*eth.BlockBody) [][]common.Hash {
+	var (
+		txsHashes        = make([]common.Hash, len(bodies))
+		uncleHashes      = make([]common.Hash, len(bodies))
+		withdrawalHashes = make([]common.Hash, len(bodies))
+		requestsHashes   = make([]common.Hash, len(bodies))
+	)
+	hasher := trie.NewStackTrie(nil)
+	for i, body := range bodies {
+		txsHashes[i] = types.DeriveSha(types.Transactions(body.Transactions), hasher)
+		uncleHashes[i] = types.CalcUncleHash(body.Uncles)
+		if body.Withdrawals != nil {
+			withdrawalHashes[i] = types.DeriveSha(types.Withdrawals(body.Withdrawals), hasher)
+		}
+		if body.Requests != nil {
+			requestsHashes[i] = types.DeriveSha(types.Requests(body.Requests), hasher)
+		}
+	}
+	return [][]common.Hash{txsHashes, uncleHashes, withdrawalHashes, requestsHashes}
+}
+
+// RequestReceipts retrieves the receipts of the blocks with the given hashes
+// using debug_getRawReceipts.
+func (p *RPCPeer) RequestReceipts(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
+	p.logger.Trace("Fetching batch of receipts", "count", len(hashes))
+	return p.request(sink, func(ctx context.Context) (interface{}, interface{}, error) {
+		var (
+			raws  = make([][]hexutil.Bytes, len(hashes))
+			batch = make([]rpc.BatchElem, len(hashes))
+		)
+		for i, hash := range hashes {
+			batch[i] = rpc.BatchElem{Method: "debug_getRawReceipts", Args: []interface{}{hash}, Result: &raws[i]}
+		}
+		if err := p.client.BatchCallContext(ctx, batch); err != nil {
+			return nil, nil, err
+		}
+		var (
+			receipts = make([][]*types.Receipt, 0, len(batch))
+			roots    = make([]common.Hash, 0, len(batch))
+			hasher   = trie.NewStackTrie(nil)
+		)
+		for i, elem := range batch {
+			if elem.Error != nil {
+				break // Deliver the receipts retrieved up to the first missing ones
+			}
+			block := make([]*types.Receipt, len(raws[i]))
+			for j, raw := range raws[i] {
+				block[j] = new(types.Receipt)
+				if err := block[j].UnmarshalBinary(raw); err != nil {
+					return nil, nil, err
+				}
+			}
+			receipts = append(receipts, block)
+			roots = append(roots, types.DeriveSha(types.Receipts(block), hasher))
+		}
+		return (*eth.ReceiptsResponse)(&receipts), roots, nil
+	}, func() (interface{}, interface{}, error) {
+		return &eth.ReceiptsResponse{}, []common.Hash{}, nil
+	})
+}
+
+// request runs a retrieval in the background and delivers its results to the
+// sink. Failed retrievals deliver an empty response instead, so the downloader
+// reschedules the request instead of waiting on it to time out.
+func (p *RPCPeer) request(sink chan *eth.Response, fetch func(ctx context.Context) (interface{}, interface{}, error), empty func() (interface{}, interface{}, error)) (*eth.Request, error) {
+	req := &eth.Request{
+		Peer: p.id,
+		Sent: time.Now(),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcPeerTimeout)
+		defer cancel()
+
+		res, meta, err := fetch(ctx)
+		if err != nil {
+			p.logger.Warn("Failed to retrieve data from RPC peer", "err", err)
+			res, meta, _ = empty()
+		}
+		select {
+		case sink <- &eth.Response{
+			Req:  req,
+			Res:  res,
+			Meta: meta,
+			Time: time.Since(req.Sent),
+			Done: make(chan error, 1), // Validation failures are logged by the downloader
+		}:
+		case <-time.After(rpcPeerTimeout):
+			p.logger.Debug("Dropped unclaimed RPC peer response")
+		}
+	}()
+	return req, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// rpcTestBackend serves the RPC methods used by RPCPeer from a chain.
+type rpcTestBackend struct {
+	chain *core.BlockChain
+}
+
+func (b *rpcTestBackend) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	header := b.chain.CurrentHeader()
+	if number >= 0 {
+		header = b.chain.GetHeaderByNumber(uint64(number))
+	}
+	if header == nil {
+		return nil, nil
+	}
+	return ethapi.RPCMarshalHeader(header), nil
+}
+
+func (b *rpcTestBackend) GetBlockByHash(hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	header := b.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, nil
+	}
+	return ethapi.RPCMarshalHeader(header), nil
+}
+
+func (b *rpcTestBackend) GetRawBlock(hash common.Hash) (hexutil.Bytes, error) {
+	block := b.chain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	return rlp.EncodeToBytes(block)
+}
+
+func (b *rpcTestBackend) GetRawReceipts(hash common.Hash) ([]hexutil.Bytes, error) {
+	receipts := b.chain.GetReceiptsByHash(hash)
+	if receipts == nil {
+		return nil, errors.New("receipts not found")
+	}
+	result := make([]hexutil.Bytes, len(receipts))
+	for i, receipt := range receipts {
+		blob, err := receipt.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		result[i] = blob
+	}
+	return result, nil
+}
+
+// newRPCTestPeer starts an RPC server serving the given blocks, returning a peer
+// retrieving data from it.
+func newRPCTestPeer(t *testing.T, id string, blocks []*types.Block) *RPCPeer {
+	backend := &rpcTestBackend{chain: newTestBlockchain(blocks)}
+
+	server := rpc.NewServer()
+	server.RegisterName("eth", backend)
+	server.RegisterName("debug", backend)
+	httpsrv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpsrv.Close()
+		server.Stop()
+	})
+	client, err := rpc.Dial(httpsrv.URL)
+	if err != nil {
+		t.Fatalf("failed to dial rpc server: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return NewRPCPeer(id, client)
+}
+
+// Tests that a chain can be beacon synced from a trusted RPC peer alone.
+func TestRPCPeerBeaconSync(t *testing.T) {
+	success := make(chan struct{})
+	tester := newTesterWithNotification(t, func() {
+		close(success)
+	})
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	peer := newRPCTestPeer(t, "rpc", chain.blocks[1:])
+	if err := tester.downloader.RegisterPeer(peer.ID(), eth.ETH68, peer); err != nil {
+		t.Fatalf("failed to register rpc peer: %v", err)
+	}
+	if head, _ := peer.Head(); head != chain.blocks[len(chain.blocks)-1].Hash() {
+		t.Fatalf("rpc peer head mismatch: have %x, want %x", head, chain.blocks[len(chain.blocks)-1].Hash())
+	}
+	if err := tester.downloader.BeaconSync(FullSync, chain.blocks[len(chain.blocks)-1].Header(), nil); err != nil {
+		t.Fatalf("failed to beacon sync chain: %v", err)
+	}
+	select {
+	case <-success:
+		if bs := int(tester.chain.CurrentBlock().Number.Uint64()) + 1; bs != len(chain.blocks) {
+			t.Fatalf("synchronised blocks mismatch: have %v, want %v", bs, len(chain.blocks))
+		}
+	case <-time.NewTimer(time.Second * 10).C:
+		t.Fatalf("failed to sync chain in ten seconds")
+	}
+}
+
+// Tests that header queries are served in all directions and cut at the chain
+// head, and that missing blocks result in partial deliveries.
+func TestRPCPeerRequests(t *testing.T) {
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	peer := newRPCTestPeer(t, "rpc", chain.blocks[1:])
+	last := len(chain.blocks) - 1
+
+	sink := make(chan *eth.Response, 1)
+	check := func(name string, req *eth.Request, err error, want []common.Hash) {
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", name, err)
+		}
+		res := <-sink
+		if res.Req != req {
+			t.Fatalf("%s: response for unknown request", name)
+		}
+		have := res.Meta.([]common.Hash)
+		if len(have) != len(want) {
+			t.Fatalf("%s: header count mismatch: have %d, want %d", name, len(have), len(want))
+		}
+		for i := range want {
+			if have[i] != want[i] {
+				t.Errorf("%s: header %d mismatch: have %x, want %x", name, i, have[i], want[i])
+			}
+		}
+	}
+	req, err := peer.RequestHeadersByNumber(10, 4, 1, false, sink)
+	check("forward", req, err, []common.Hash{chain.blocks[10].Hash(), chain.blocks[12].Hash(), chain.blocks[14].Hash(), chain.blocks[16].Hash()})
+
+	req, err = peer.RequestHeadersByNumber(2, 4, 0, true, sink)
+	check("reverse", req, err, []common.Hash{chain.blocks[2].Hash(), chain.blocks[1].Hash(), chain.blocks[0].Hash()})
+
+	req, err = peer.RequestHeadersByHash(chain.blocks[last-1].Hash(), 4, 0, false, sink)
+	check("by hash", req, err, []common.Hash{chain.blocks[last-1].Hash(), chain.blocks[last].Hash()})
+
+	_, err = peer.RequestBodies([]common.Hash{chain.blocks[1].Hash(), {0x01}, chain.blocks[2].Hash()}, sink)
+	if err != nil {
+		t.Fatalf("bodies request failed: %v", err)
+	}
+	if res := <-sink; len(*res.Res.(*eth.BlockBodiesResponse)) != 1 {
+		t.Fatalf("body count mismatch: have %d, want 1", len(*res.Res.(*eth.BlockBodiesResponse)))
+	}
+	_, err = peer.RequestReceipts([]common.Hash{chain.blocks[1].Hash(), chain.blocks[2].Hash()}, sink)
+	if err != nil {
+		t.Fatalf("receipts request failed: %v", err)
+	}
+	res := <-sink
+	if roots := res.Meta.([]common.Hash); len(roots) != 2 || roots[0] != chain.blocks[1].ReceiptHash() || roots[1] != chain.blocks[2].ReceiptHash() {
+		t.Fatalf("receipt roots mismatch: have %x", roots)
+	}
+}
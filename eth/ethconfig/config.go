@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -139,6 +140,9 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// Enables optimistic parallel execution of the transactions of imported blocks
+	ParallelExecution bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -178,9 +182,14 @@ type Config struct {
 	RollupSequencerTxConditionalCostRateLimit int
 	RollupHistoricalRPC                       string
 	RollupHistoricalRPCTimeout                time.Duration
+	RollupSyncRPC                             string
 	RollupDisableTxPoolGossip                 bool
 	RollupDisableTxPoolAdmission              bool
 	RollupHaltOnIncompatibleProtocolVersion   string
+
+	// TxPoolAdmissionPolicies are operator supplied rules checked for every
+	// transaction entering the pool. Only configurable when embedding the node.
+	TxPoolAdmissionPolicies []txpool.AdmissionPolicy `toml:"-"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -46,6 +47,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		EnablePreimageRecording                   bool
 		VMTrace                                   string
 		VMTraceJsonConfig                         string
+		ParallelExecution                         bool
 		DocRoot                                   string `toml:"-"`
 		RPCGasCap                                 uint64
 		RPCEVMTimeout                             time.Duration
@@ -64,9 +66,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RollupSequencerTxConditionalCostRateLimit int
 		RollupHistoricalRPC                       string
 		RollupHistoricalRPCTimeout                time.Duration
+		RollupSyncRPC                             string
 		RollupDisableTxPoolGossip                 bool
 		RollupDisableTxPoolAdmission              bool
 		RollupHaltOnIncompatibleProtocolVersion   string
+		TxPoolAdmissionPolicies                   []txpool.AdmissionPolicy `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -98,6 +102,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.VMTrace = c.VMTrace
 	enc.VMTraceJsonConfig = c.VMTraceJsonConfig
+	enc.ParallelExecution = c.ParallelExecution
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
@@ -116,9 +121,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RollupSequencerTxConditionalCostRateLimit = c.RollupSequencerTxConditionalCostRateLimit
 	enc.RollupHistoricalRPC = c.RollupHistoricalRPC
 	enc.RollupHistoricalRPCTimeout = c.RollupHistoricalRPCTimeout
+	enc.RollupSyncRPC = c.RollupSyncRPC
 	enc.RollupDisableTxPoolGossip = c.RollupDisableTxPoolGossip
 	enc.RollupDisableTxPoolAdmission = c.RollupDisableTxPoolAdmission
 	enc.RollupHaltOnIncompatibleProtocolVersion = c.RollupHaltOnIncompatibleProtocolVersion
+	enc.TxPoolAdmissionPolicies = c.TxPoolAdmissionPolicies
 	return &enc, nil
 }
 
@@ -154,6 +161,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		EnablePreimageRecording                   *bool
 		VMTrace                                   *string
 		VMTraceJsonConfig                         *string
+		ParallelExecution                         *bool
 		DocRoot                                   *string `toml:"-"`
 		RPCGasCap                                 *uint64
 		RPCEVMTimeout                             *time.Duration
@@ -172,6 +180,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RollupSequencerTxConditionalCostRateLimit *int
 		RollupHistoricalRPC                       *string
 		RollupHistoricalRPCTimeout                *time.Duration
+		RollupSyncRPC                             *string
 		RollupDisableTxPoolGossip                 *bool
 		RollupDisableTxPoolAdmission              *bool
 		RollupHaltOnIncompatibleProtocolVersion   *string
@@ -267,6 +276,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.VMTraceJsonConfig != nil {
 		c.VMTraceJsonConfig = *dec.VMTraceJsonConfig
 	}
+	if dec.ParallelExecution != nil {
+		c.ParallelExecution = *dec.ParallelExecution
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -321,6 +333,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RollupHistoricalRPCTimeout != nil {
 		c.RollupHistoricalRPCTimeout = *dec.RollupHistoricalRPCTimeout
 	}
+	if dec.RollupSyncRPC != nil {
+		c.RollupSyncRPC = *dec.RollupSyncRPC
+	}
 	if dec.RollupDisableTxPoolGossip != nil {
 		c.RollupDisableTxPoolGossip = *dec.RollupDisableTxPoolGossip
 	}
@@ -41,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
 )
 
@@ -53,6 +54,9 @@ const (
 	// All transactions with a higher size will be announced and need to be fetched
 	// by the peer.
 	txMaxBroadcastSize = 4096
+
+	// rpcSyncPeer is the downloader peer ID of the trusted RPC sync endpoint.
+	rpcSyncPeer = "rpc-sync"
 )
 
 var syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
@@ -94,6 +98,7 @@ type handlerConfig struct {
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
 	NoTxGossip     bool                   // Disable P2P transaction gossip
+	SyncRPC        *rpc.Client            // Trusted RPC endpoint to sync chain data from
 }
 
 type handler struct {
@@ -193,6 +198,14 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	// Construct the downloader (long sync)
 	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, h.removePeer, h.enableSyncedFeatures, chainID)
 
+	// Retrieve chain data from the trusted RPC endpoint alongside p2p peers
+	if config.SyncRPC != nil {
+		if err := h.downloader.RegisterPeer(rpcSyncPeer, eth.ETH68, downloader.NewRPCPeer(rpcSyncPeer, config.SyncRPC)); err != nil {
+			return nil, err
+		}
+		log.Info("Enabled sync from trusted RPC endpoint")
+	}
+
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
 		if p == nil {
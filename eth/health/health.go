@@ -0,0 +1,296 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package health implements the HTTP health and readiness endpoints polled by
+// load balancers and orchestrators.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// metricsRefresh is the interval at which the readiness gauges are refreshed
+	// in the absence of polls.
+	metricsRefresh = 5 * time.Second
+
+	// checkTimeout is the maximum time allowed to gather the status of the node.
+	checkTimeout = 5 * time.Second
+)
+
+// Names of the readiness checks, as reported in the JSON details and metrics.
+const (
+	checkHead      = "head"
+	checkSafe      = "safe"
+	checkFinalized = "finalized"
+	checkPeers     = "peers"
+	checkProtocol  = "protocol"
+	checkTxPool    = "txpool"
+)
+
+var (
+	readyGauge  = metrics.NewRegisteredGauge("health/ready", nil)
+	checkGauges = map[string]metrics.Gauge{
+		checkHead:      metrics.NewRegisteredGauge("health/ready/"+checkHead, nil),
+		checkSafe:      metrics.NewRegisteredGauge("health/ready/"+checkSafe, nil),
+		checkFinalized: metrics.NewRegisteredGauge("health/ready/"+checkFinalized, nil),
+		checkPeers:     metrics.NewRegisteredGauge("health/ready/"+checkPeers, nil),
+		checkProtocol:  metrics.NewRegisteredGauge("health/ready/"+checkProtocol, nil),
+		checkTxPool:    metrics.NewRegisteredGauge("health/ready/"+checkTxPool, nil),
+	}
+)
+
+// Config contains the readiness conditions of the node. Zero values disable the
+// respective check.
+type Config struct {
+	Enabled         bool          `toml:",omitempty"`
+	MaxHeadAge      time.Duration `toml:",omitempty"` // Maximum age of the unsafe head
+	MaxSafeLag      uint64        `toml:",omitempty"` // Maximum number of blocks the safe head may trail the unsafe head
+	MaxFinalizedLag uint64        `toml:",omitempty"` // Maximum number of blocks the finalized head may trail the unsafe head
+	MinPeers        int           `toml:",omitempty"` // Minimum number of connected peers
+	TxPoolAdmission bool          `toml:",omitempty"` // Whether submitted transactions must be admitted to the local pool
+}
+
+// DefaultConfig contains the default readiness conditions.
+var DefaultConfig = Config{
+	MaxHeadAge: time.Minute,
+}
+
+// Backend encompasses the functionality necessary to evaluate the health of the
+// node.
+type Backend interface {
+	CurrentHeader() *types.Header
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	TxPoolAdmission() (bool, []string)
+	ProtocolHalted() bool
+}
+
+// Check is the outcome of a single readiness check.
+type Check struct {
+	Ready   bool        `json:"ready"`
+	Value   interface{} `json:"value,omitempty"`
+	Limit   interface{} `json:"limit,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+// Status is the detailed readiness of the node, served as JSON.
+type Status struct {
+	Ready  bool              `json:"ready"`
+	Head   uint64            `json:"head"`
+	Checks map[string]*Check `json:"checks"`
+}
+
+// Service serves the health and readiness endpoints of a node.
+type Service struct {
+	backend Backend
+	peers   func() int // Number of connected peers
+	config  Config
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New registers the health and readiness endpoints on the HTTP-RPC server of the
+// node. The endpoints are only available if the HTTP-RPC server is enabled.
+func New(stack *node.Node, backend Backend, config Config) (*Service, error) {
+	if config.MaxHeadAge < 0 || config.MinPeers < 0 {
+		return nil, fmt.Errorf("invalid readiness conditions: max head age %v, min peers %d", config.MaxHeadAge, config.MinPeers)
+	}
+	s := newService(backend, stack.Server().PeerCount, config)
+
+	stack.RegisterHandler("Health check", "/health", http.HandlerFunc(s.serveHealth))
+	stack.RegisterHandler("Readiness check", "/ready", http.HandlerFunc(s.serveReady))
+	stack.RegisterLifecycle(s)
+	return s, nil
+}
+
+func newService(backend Backend, peers func() int, config Config) *Service {
+	return &Service{
+		backend: backend,
+		peers:   peers,
+		config:  config,
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, refreshing the readiness metrics periodically
+// if metrics collection is enabled.
+func (s *Service) Start() error {
+	if !metrics.Enabled {
+		return nil
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(metricsRefresh)
+		defer ticker.Stop()
+
+		for {
+			s.Status()
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the metrics refresh.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// Status evaluates all enabled readiness checks and updates their metrics.
+func (s *Service) Status() *Status {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	status := &Status{Checks: make(map[string]*Check)}
+
+	head := s.backend.CurrentHeader()
+	if head != nil {
+		status.Head = head.Number.Uint64()
+	}
+	if s.config.MaxHeadAge > 0 {
+		check := &Check{Limit: s.config.MaxHeadAge.String()}
+		if head == nil {
+			check.Message = "no head block"
+		} else {
+			age := time.Since(time.Unix(int64(head.Time), 0)).Truncate(time.Second)
+			if age < 0 {
+				age = 0
+			}
+			check.Value = age.String()
+			check.Ready = age <= s.config.MaxHeadAge
+			if !check.Ready {
+				check.Message = "head block too old"
+			}
+		}
+		status.Checks[checkHead] = check
+	}
+	lag := func(number rpc.BlockNumber, limit uint64) *Check {
+		check := &Check{Limit: limit}
+		header, err := s.backend.HeaderByNumber(ctx, number)
+		switch {
+		case err != nil:
+			check.Message = err.Error()
+		case header == nil || head == nil:
+			check.Message = fmt.Sprintf("no %s block", number)
+		default:
+			var lag uint64
+			if header.Number.Uint64() < head.Number.Uint64() {
+				lag = head.Number.Uint64() - header.Number.Uint64()
+			}
+			check.Value = lag
+			check.Ready = lag <= limit
+			if !check.Ready {
+				check.Message = fmt.Sprintf("%s block too far behind", number)
+			}
+		}
+		return check
+	}
+	if s.config.MaxSafeLag > 0 {
+		status.Checks[checkSafe] = lag(rpc.SafeBlockNumber, s.config.MaxSafeLag)
+	}
+	if s.config.MaxFinalizedLag > 0 {
+		status.Checks[checkFinalized] = lag(rpc.FinalizedBlockNumber, s.config.MaxFinalizedLag)
+	}
+	if s.config.MinPeers > 0 {
+		peers := s.peers()
+		check := &Check{Value: peers, Limit: s.config.MinPeers, Ready: peers >= s.config.MinPeers}
+		if !check.Ready {
+			check.Message = "not enough peers"
+		}
+		status.Checks[checkPeers] = check
+	}
+	// The protocol version check is always enabled, it only fails if the node
+	// opted in to halt on incompatible protocol versions.
+	if halted := s.backend.ProtocolHalted(); halted {
+		status.Checks[checkProtocol] = &Check{Message: "halted on incompatible protocol version"}
+	} else {
+		status.Checks[checkProtocol] = &Check{Ready: true}
+	}
+	if s.config.TxPoolAdmission {
+		admission, _ := s.backend.TxPoolAdmission()
+		check := &Check{Ready: admission}
+		if !admission {
+			check.Message = "transactions not admitted to the pool"
+		}
+		status.Checks[checkTxPool] = check
+	}
+	// Aggregate the checks and update the metrics
+	status.Ready = true
+	for name, check := range status.Checks {
+		if check.Ready {
+			checkGauges[name].Update(1)
+		} else {
+			checkGauges[name].Update(0)
+			status.Ready = false
+		}
+	}
+	if status.Ready {
+		readyGauge.Update(1)
+	} else {
+		readyGauge.Update(0)
+	}
+	return status
+}
+
+// serveHealth responds to liveness probes, succeeding as long as the node is
+// serving requests and has a head block.
+func (s *Service) serveHealth(w http.ResponseWriter, r *http.Request) {
+	head := s.backend.CurrentHeader()
+	if head == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"healthy": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"healthy": true, "head": head.Number.Uint64()})
+}
+
+// serveReady responds to readiness probes with the outcome of all enabled checks,
+// failing with 503 if any of them did not pass.
+func (s *Service) serveReady(w http.ResponseWriter, r *http.Request) {
+	status := s.Status()
+	if status.Ready {
+		writeJSON(w, http.StatusOK, status)
+	} else {
+		writeJSON(w, http.StatusServiceUnavailable, status)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debug("Failed to write health response", "err", err)
+	}
+}
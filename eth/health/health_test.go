@@ -0,0 +1,163 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testBackend struct {
+	head, safe, finalized *types.Header
+	admission             bool
+	halted                bool
+}
+
+func (b *testBackend) CurrentHeader() *types.Header { return b.head }
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	switch number {
+	case rpc.SafeBlockNumber:
+		return b.safe, nil
+	case rpc.FinalizedBlockNumber:
+		return b.finalized, nil
+	}
+	return b.head, nil
+}
+func (b *testBackend) TxPoolAdmission() (bool, []string) { return b.admission, nil }
+func (b *testBackend) ProtocolHalted() bool              { return b.halted }
+
+func testHeader(number uint64, age time.Duration) *types.Header {
+	return &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   uint64(time.Now().Add(-age).Unix()),
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	config := Config{
+		MaxHeadAge:      time.Minute,
+		MaxSafeLag:      10,
+		MaxFinalizedLag: 100,
+		MinPeers:        2,
+		TxPoolAdmission: true,
+	}
+	healthy := func() *testBackend {
+		return &testBackend{
+			head:      testHeader(200, 0),
+			safe:      testHeader(195, 10*time.Second),
+			finalized: testHeader(100, 200*time.Second),
+			admission: true,
+		}
+	}
+	tests := []struct {
+		config Config
+		modify func(b *testBackend)
+		peers  int
+		failed string
+	}{
+		{config: config, modify: func(b *testBackend) {}, peers: 2},
+		{config: config, modify: func(b *testBackend) { b.head = testHeader(200, 2*time.Minute) }, peers: 2, failed: checkHead},
+		{config: config, modify: func(b *testBackend) { b.safe = testHeader(189, 0) }, peers: 2, failed: checkSafe},
+		{config: config, modify: func(b *testBackend) { b.safe = nil }, peers: 2, failed: checkSafe},
+		{config: config, modify: func(b *testBackend) { b.finalized = testHeader(99, 0) }, peers: 2, failed: checkFinalized},
+		{config: config, modify: func(b *testBackend) {}, peers: 1, failed: checkPeers},
+		{config: config, modify: func(b *testBackend) { b.halted = true }, peers: 2, failed: checkProtocol},
+		{config: config, modify: func(b *testBackend) { b.admission = false }, peers: 2, failed: checkTxPool},
+
+		// Disabled checks don't fail
+		{config: Config{}, modify: func(b *testBackend) {
+			b.head, b.safe, b.finalized, b.admission = testHeader(200, time.Hour), nil, nil, false
+		}, peers: 0},
+	}
+	for i, tt := range tests {
+		backend := healthy()
+		tt.modify(backend)
+		peers := tt.peers
+		s := newService(backend, func() int { return peers }, tt.config)
+
+		rec := httptest.NewRecorder()
+		s.serveReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		var status Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("test %d: failed to decode status: %v", i, err)
+		}
+		if status.Head != 200 {
+			t.Errorf("test %d: head mismatch: have %d, want 200", i, status.Head)
+		}
+		if tt.failed == "" {
+			if rec.Code != http.StatusOK || !status.Ready {
+				t.Errorf("test %d: node not ready: %d %s", i, rec.Code, rec.Body)
+			}
+			continue
+		}
+		if rec.Code != http.StatusServiceUnavailable || status.Ready {
+			t.Errorf("test %d: node ready: %d %s", i, rec.Code, rec.Body)
+		}
+		for name, check := range status.Checks {
+			if failed := name == tt.failed; check.Ready == failed {
+				t.Errorf("test %d: check %s ready mismatch: have %v, want %v", i, name, check.Ready, !failed)
+			}
+		}
+		if check := status.Checks[tt.failed]; check == nil || check.Message == "" {
+			t.Errorf("test %d: failed check %s without details: %v", i, tt.failed, check)
+		}
+	}
+}
+
+// Tests that the endpoints are served on the HTTP-RPC server.
+func TestEndpoints(t *testing.T) {
+	stack, err := node.New(&node.Config{HTTPHost: "127.0.0.1", HTTPPort: 0})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	backend := &testBackend{head: testHeader(5, 0), halted: true}
+	if _, err := New(stack, backend, Config{MaxHeadAge: time.Minute}); err != nil {
+		t.Fatalf("failed to register health service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	for path, code := range map[string]int{"/health": http.StatusOK, "/ready": http.StatusServiceUnavailable} {
+		resp, err := http.Get(fmt.Sprintf("%s%s", stack.HTTPEndpoint(), path))
+		if err != nil {
+			t.Fatalf("failed to query %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != code {
+			t.Errorf("%s status mismatch: have %d, want %d", path, resp.StatusCode, code)
+		}
+		if typ := resp.Header.Get("Content-Type"); typ != "application/json" {
+			t.Errorf("%s content type mismatch: have %s", path, typ)
+		}
+	}
+	if _, err := New(stack, backend, Config{MinPeers: -1}); err == nil {
+		t.Fatalf("invalid readiness conditions accepted")
+	}
+}
@@ -15,21 +15,64 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 // Package remotedb implements the key-value database layer based on a remote geth
-// node. Under the hood, it utilises the `debug_dbGet` method to implement a
-// read-only database.
+// node. Under the hood, it utilises the `debug_db*` methods to implement a
+// read-only database, with paginated iteration, ancient range reads, and an LRU
+// cache of the retrieved values that can't change on the remote node.
 // There really are no guarantees in this database, since the local geth does not
 // exclusive access, but it can be used for basic diagnostics of a remote node.
 package remotedb
 
 import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// Database is a key-value lookup for a remote database via debug_dbGet.
+const (
+	// cacheSize is the maximum size of the values and ancient items cached
+	// locally, each.
+	cacheSize = 32 * 1024 * 1024
+
+	// maxBatchItems is the maximum number of entries requested at once, matching
+	// the limit enforced by the remote node.
+	maxBatchItems = 1024
+)
+
+// errNotSupported is returned for operations a read-only remote database can't
+// serve.
+var errNotSupported = errors.New("this operation is not supported")
+
+// Database is a read-only key-value and ancient store backed by a remote node
+// via the debug_db* methods.
 type Database struct {
-	remote *rpc.Client
+	remote   *rpc.Client
+	cache    *lru.SizeConstrainedCache[string, []byte]     // Values of immutable keys
+	ancients *lru.SizeConstrainedCache[ancientKey, []byte] // Frozen items
+}
+
+// ancientKey identifies an item in the freezer.
+type ancientKey struct {
+	kind   string
+	number uint64
+}
+
+// immutableKey reports whether the value of a key can't change once written, as
+// the key is the hash of the value. Only the values of such keys are cached, as
+// the remote node keeps on modifying the rest of the database.
+func immutableKey(key []byte) bool {
+	if len(key) == common.HashLength {
+		return true // Hash-scheme trie node
+	}
+	if ok, _ := rawdb.IsCodeKey(key); ok {
+		return true
+	}
+	return len(key) == len(rawdb.PreimagePrefix)+common.HashLength && bytes.HasPrefix(key, rawdb.PreimagePrefix)
 }
 
 func (db *Database) Has(key []byte) (bool, error) {
@@ -40,11 +83,17 @@ func (db *Database) Has(key []byte) (bool, error) {
 }
 
 func (db *Database) Get(key []byte) ([]byte, error) {
+	if blob, ok := db.cache.Get(string(key)); ok {
+		return common.CopyBytes(blob), nil
+	}
 	var resp hexutil.Bytes
 	err := db.remote.Call(&resp, "debug_dbGet", hexutil.Bytes(key))
 	if err != nil {
 		return nil, err
 	}
+	if immutableKey(key) {
+		db.cache.Add(string(key), common.CopyBytes(resp))
+	}
 	return resp, nil
 }
 
@@ -56,16 +105,30 @@ func (db *Database) HasAncient(kind string, number uint64) (bool, error) {
 }
 
 func (db *Database) Ancient(kind string, number uint64) ([]byte, error) {
+	if blob, ok := db.ancients.Get(ancientKey{kind, number}); ok {
+		return common.CopyBytes(blob), nil
+	}
 	var resp hexutil.Bytes
 	err := db.remote.Call(&resp, "debug_dbAncient", kind, number)
 	if err != nil {
 		return nil, err
 	}
+	db.ancients.Add(ancientKey{kind, number}, common.CopyBytes(resp))
 	return resp, nil
 }
 
 func (db *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
-	panic("not supported")
+	var resp []hexutil.Bytes
+	err := db.remote.Call(&resp, "debug_dbAncientRange", kind, start, count, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([][]byte, len(resp))
+	for i, blob := range resp {
+		blobs[i] = blob
+		db.ancients.Add(ancientKey{kind, start + uint64(i)}, common.CopyBytes(blob))
+	}
+	return blobs, nil
 }
 
 func (db *Database) Ancients() (uint64, error) {
@@ -75,11 +138,15 @@ func (db *Database) Ancients() (uint64, error) {
 }
 
 func (db *Database) Tail() (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.remote.Call(&resp, "debug_dbTail")
+	return resp, err
 }
 
 func (db *Database) AncientSize(kind string) (uint64, error) {
-	panic("not supported")
+	var resp uint64
+	err := db.remote.Call(&resp, "debug_dbAncientSize", kind)
+	return resp, err
 }
 
 func (db *Database) ReadAncients(fn func(op ethdb.AncientReaderOp) error) (err error) {
@@ -119,7 +186,12 @@ func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
 }
 
 func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
-	panic("not supported")
+	return &iterator{
+		db:     db,
+		prefix: common.CopyBytes(prefix),
+		next:   common.CopyBytes(start),
+		index:  -1,
+	}
 }
 
 func (db *Database) Stat() (string, error) {
@@ -127,7 +199,7 @@ func (db *Database) Stat() (string, error) {
 }
 
 func (db *Database) AncientDatadir() (string, error) {
-	panic("not supported")
+	return "", errNotSupported
 }
 
 func (db *Database) Compact(start []byte, limit []byte) error {
@@ -141,6 +213,82 @@ func (db *Database) Close() error {
 
 func New(client *rpc.Client) ethdb.Database {
 	return &Database{
-		remote: client,
+		remote:   client,
+		cache:    lru.NewSizeConstrainedCache[string, []byte](cacheSize),
+		ancients: lru.NewSizeConstrainedCache[ancientKey, []byte](cacheSize),
+	}
+}
+
+// rangeResult is a page of entries returned by debug_dbRange.
+type rangeResult struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	Next   hexutil.Bytes   `json:"next"`
+}
+
+// iterator iterates the remote database by retrieving pages of entries via
+// debug_dbRange.
+type iterator struct {
+	db     *Database
+	prefix []byte
+	next   []byte // Start of the next page, nil if exhausted
+	done   bool   // Whether the last page has been retrieved
+
+	keys   []hexutil.Bytes
+	values []hexutil.Bytes
+	index  int
+	err    error
+}
+
+// Next moves the iterator to the next key/value pair, retrieving the next page
+// from the remote node if the current one is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
 	}
+	for it.index+1 >= len(it.keys) {
+		if it.done {
+			it.index = len(it.keys)
+			return false
+		}
+		var resp rangeResult
+		if err := it.db.remote.Call(&resp, "debug_dbRange", hexutil.Bytes(it.prefix), hexutil.Bytes(it.next), maxBatchItems); err != nil {
+			it.err = err
+			return false
+		}
+		if len(resp.Keys) != len(resp.Values) {
+			it.err = errors.New("invalid range response")
+			return false
+		}
+		it.keys, it.values, it.index = resp.Keys, resp.Values, -1
+		it.next, it.done = resp.Next, len(resp.Next) == 0
+	}
+	it.index++
+	return true
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.index]
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return it.values[it.index]
+}
+
+// Release releases the retrieved page and stops the iteration.
+func (it *iterator) Release() {
+	it.keys, it.values, it.done = nil, nil, true
 }
@@ -93,6 +93,10 @@ type Service struct {
 	pongCh chan struct{} // Pong notifications are fed into this channel
 	histCh chan []uint64 // History request block numbers are fed into this channel
 
+	opStats           bool   // Whether to report OP stack specific stats
+	lastForwarded     uint64 // Number of forwarded transactions at the last OP stats report
+	lastForwardFailed uint64 // Number of failed forwards at the last OP stats report
+
 	headSub event.Subscription
 	txSub   event.Subscription
 }
@@ -171,12 +175,27 @@ func parseEthstatsURL(url string) (parts []string, err error) {
 	return []string{nodename, pass, host}, nil
 }
 
+// Config contains the settings of the monitoring service.
+type Config struct {
+	URL     string // Reporting URL of the ethstats service (nodename:secret@host:port)
+	OPStats bool   // Whether to report OP stack specific stats too
+}
+
 // New returns a monitoring service ready for stats reporting.
 func New(node *node.Node, backend backend, engine consensus.Engine, url string) error {
-	parts, err := parseEthstatsURL(url)
+	return NewWithConfig(node, backend, engine, Config{URL: url})
+}
+
+// NewWithConfig returns a monitoring service ready for stats reporting, configured
+// with the given settings.
+func NewWithConfig(node *node.Node, backend backend, engine consensus.Engine, config Config) error {
+	parts, err := parseEthstatsURL(config.URL)
 	if err != nil {
 		return err
 	}
+	if _, ok := backend.(opBackend); config.OPStats && !ok {
+		return errors.New("OP stack stats are only available on full nodes")
+	}
 	ethstats := &Service{
 		backend: backend,
 		engine:  engine,
@@ -186,6 +205,7 @@ func New(node *node.Node, backend backend, engine consensus.Engine, url string)
 		host:    parts[2],
 		pongCh:  make(chan struct{}),
 		histCh:  make(chan []uint64, 1),
+		opStats: config.OPStats,
 	}
 
 	node.RegisterLifecycle(ethstats)
@@ -337,6 +357,9 @@ func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Post-block transaction stats report failed", "err", err)
 					}
+					if err = s.reportOPStats(conn, head); err != nil {
+						log.Warn("Post-block OP stats report failed", "err", err)
+					}
 				case <-txCh:
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Transaction stats report failed", "err", err)
@@ -455,6 +478,7 @@ type nodeInfo struct {
 	OsVer    string `json:"os_v"`
 	Client   string `json:"client"`
 	History  bool   `json:"canUpdateHistory"`
+	OPStats  int    `json:"opStats,omitempty"` // Schema version of the OP stack stats, if reported
 }
 
 // authMsg is the authentication infos needed to login to a monitoring server.
@@ -495,6 +519,9 @@ func (s *Service) login(conn *connWrapper) error {
 		},
 		Secret: s.pass,
 	}
+	if s.opStats {
+		auth.Info.OPStats = opStatsVersion
+	}
 	login := map[string][]interface{}{
 		"emit": {"hello", auth},
 	}
@@ -525,6 +552,9 @@ func (s *Service) report(conn *connWrapper) error {
 	if err := s.reportStats(conn); err != nil {
 		return err
 	}
+	if err := s.reportOPStats(conn, nil); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,260 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// opStatsVersion is the version of the schema of the OP stack stats messages.
+// It is advertised on login and included in every report, and must be bumped
+// on incompatible changes of the schema.
+const opStatsVersion = 1
+
+// opBackend encompasses the functionality necessary for reporting OP stack
+// specific stats to ethstats
+type opBackend interface {
+	fullNodeBackend
+	SequencerForwarding() bool
+	SequencerForwardStats() (forwarded uint64, failed uint64)
+	TxPoolAdmission() (bool, []string)
+	ProtocolVersions() (recommended, required params.ProtocolVersion)
+}
+
+// isthmusL1InfoSelector is the selector of the L1 attributes deposit transaction
+// after the Isthmus upgrade, extending the Ecotone layout.
+var isthmusL1InfoSelector = []byte{0x09, 0x89, 0x99, 0xbe}
+
+// opStats is the OP stack specific information to report about the local node.
+type opStats struct {
+	Version             int              `json:"version"`
+	Head                uint64           `json:"head"`
+	Safe                *headLagStats    `json:"safe"`
+	Finalized           *headLagStats    `json:"finalized"`
+	L1Origin            *l1OriginStats   `json:"l1Origin"`
+	SequencerForwarding *forwardingStats `json:"sequencerForwarding"`
+	TxPool              txPoolStats      `json:"txpool"`
+	ProtocolVersion     protocolStats    `json:"protocolVersion"`
+}
+
+// headLagStats is the information to report about the safe and finalized heads.
+type headLagStats struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	Lag        uint64      `json:"lag"`        // Number of blocks behind the unsafe head
+	LagSeconds uint64      `json:"lagSeconds"` // Timestamp difference to the unsafe head
+}
+
+// l1OriginStats is the L1 block the latest block was derived from.
+type l1OriginStats struct {
+	Number         uint64      `json:"number"`
+	Hash           common.Hash `json:"hash"`
+	Timestamp      uint64      `json:"timestamp"`
+	SequenceNumber uint64      `json:"sequenceNumber"` // Number of the block within the epoch
+}
+
+// forwardingStats is the information to report about the transactions forwarded
+// to the sequencer. The error rate covers the period since the previous report.
+type forwardingStats struct {
+	Forwarded uint64  `json:"forwarded"`
+	Failed    uint64  `json:"failed"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// txPoolStats is the information to report about the admission of transactions
+// into the local pool.
+type txPoolStats struct {
+	Admission bool     `json:"admission"`
+	Policies  []string `json:"policies"`
+	Pending   int      `json:"pending"`
+	Queued    int      `json:"queued"`
+}
+
+// protocolStats is the information to report about the superchain protocol
+// versions signaled by the rollup node.
+type protocolStats struct {
+	Local             string `json:"local"`
+	Recommended       string `json:"recommended,omitempty"`
+	Required          string `json:"required,omitempty"`
+	RecommendedStatus string `json:"recommendedStatus"`
+	RequiredStatus    string `json:"requiredStatus"`
+}
+
+// reportOPStats retrieves the OP stack specific stats of the given block and
+// reports them to the stats server. If block is nil, the current head is used.
+func (s *Service) reportOPStats(conn *connWrapper, block *types.Block) error {
+	backend, ok := s.backend.(opBackend)
+	if !ok || !s.opStats {
+		return nil
+	}
+	if block == nil {
+		head := backend.CurrentBlock()
+		block, _ = backend.BlockByNumber(context.Background(), rpc.BlockNumber(head.Number.Uint64()))
+	}
+	// Short circuit if no block is available. It might happen when
+	// the blockchain is reorging.
+	if block == nil {
+		return nil
+	}
+	stats := s.assembleOPStats(backend, block.Header())
+	if origin, err := decodeL1Origin(block); err != nil {
+		log.Debug("Failed to decode L1 origin", "number", block.Number(), "err", err)
+	} else {
+		stats.L1Origin = origin
+	}
+	// Assemble the OP stats and send it to the server
+	log.Trace("Sending OP stack stats to ethstats", "head", stats.Head)
+
+	report := map[string][]interface{}{
+		"emit": {"op-stats", map[string]interface{}{
+			"id":    s.node,
+			"stats": stats,
+		}},
+	}
+	return conn.WriteJSON(report)
+}
+
+// assembleOPStats gathers the OP stack specific stats relative to the given head.
+func (s *Service) assembleOPStats(backend opBackend, head *types.Header) *opStats {
+	stats := &opStats{
+		Version: opStatsVersion,
+		Head:    head.Number.Uint64(),
+	}
+	lag := func(number rpc.BlockNumber) *headLagStats {
+		header, _ := backend.HeaderByNumber(context.Background(), number)
+		if header == nil {
+			return nil
+		}
+		stats := &headLagStats{
+			Number: header.Number.Uint64(),
+			Hash:   header.Hash(),
+		}
+		if stats.Number < head.Number.Uint64() {
+			stats.Lag = head.Number.Uint64() - stats.Number
+		}
+		if header.Time < head.Time {
+			stats.LagSeconds = head.Time - header.Time
+		}
+		return stats
+	}
+	stats.Safe = lag(rpc.SafeBlockNumber)
+	stats.Finalized = lag(rpc.FinalizedBlockNumber)
+
+	if backend.SequencerForwarding() {
+		forwarded, failed := backend.SequencerForwardStats()
+		stats.SequencerForwarding = &forwardingStats{Forwarded: forwarded, Failed: failed}
+		if attempts := forwarded - s.lastForwarded; attempts > 0 {
+			stats.SequencerForwarding.ErrorRate = float64(failed-s.lastForwardFailed) / float64(attempts)
+		}
+		s.lastForwarded, s.lastForwardFailed = forwarded, failed
+	}
+	stats.TxPool.Admission, stats.TxPool.Policies = backend.TxPoolAdmission()
+	if stats.TxPool.Policies == nil {
+		stats.TxPool.Policies = []string{}
+	}
+	stats.TxPool.Pending, stats.TxPool.Queued = backend.Stats()
+
+	recommended, required := backend.ProtocolVersions()
+	stats.ProtocolVersion = protocolStats{
+		Local:             params.OPStackSupport.String(),
+		RecommendedStatus: protocolStatus(recommended),
+		RequiredStatus:    protocolStatus(required),
+	}
+	if recommended != (params.ProtocolVersion{}) {
+		stats.ProtocolVersion.Recommended = recommended.String()
+	}
+	if required != (params.ProtocolVersion{}) {
+		stats.ProtocolVersion.Required = required.String()
+	}
+	return stats
+}
+
+// protocolStatus describes how the locally supported protocol version relates
+// to a signaled one.
+func protocolStatus(signaled params.ProtocolVersion) string {
+	switch params.OPStackSupport.Compare(signaled) {
+	case params.EmptyVersion:
+		return "unknown"
+	case params.Matching, params.AheadMajor, params.AheadMinor, params.AheadPatch, params.AheadPrerelease:
+		return "supported"
+	case params.OutdatedMajor:
+		return "outdated-major"
+	case params.OutdatedMinor:
+		return "outdated-minor"
+	case params.OutdatedPatch:
+		return "outdated-patch"
+	case params.OutdatedPrerelease:
+		return "outdated-prerelease"
+	case params.DiffBuild:
+		return "different-build"
+	default:
+		return "invalid"
+	}
+}
+
+// decodeL1Origin decodes the L1 origin of a block from its L1 attributes deposit
+// transaction, which is the first transaction of every OP block.
+func decodeL1Origin(block *types.Block) (*l1OriginStats, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 || !txs[0].IsDepositTx() {
+		return nil, errors.New("no L1 attributes deposit")
+	}
+	data := txs[0].Data()
+	if len(data) < 4 {
+		return nil, fmt.Errorf("L1 attributes too short: %d bytes", len(data))
+	}
+	switch selector := data[:4]; {
+	case bytes.Equal(selector, types.BedrockL1AttributesSelector):
+		// ABI encoded number, timestamp, basefee, hash, sequence number, ...
+		if len(data) < 4+32*5 {
+			return nil, fmt.Errorf("bedrock L1 attributes too short: %d bytes", len(data))
+		}
+		data = data[4:]
+		return &l1OriginStats{
+			Number:         new(big.Int).SetBytes(data[:32]).Uint64(),
+			Timestamp:      new(big.Int).SetBytes(data[32:64]).Uint64(),
+			Hash:           common.BytesToHash(data[96:128]),
+			SequenceNumber: new(big.Int).SetBytes(data[128:160]).Uint64(),
+		}, nil
+
+	case bytes.Equal(selector, types.EcotoneL1AttributesSelector), bytes.Equal(selector, isthmusL1InfoSelector):
+		// Packed scalars, sequence number, timestamp, number, basefees, hash, ...
+		if len(data) < 164 {
+			return nil, fmt.Errorf("ecotone L1 attributes too short: %d bytes", len(data))
+		}
+		return &l1OriginStats{
+			SequenceNumber: binary.BigEndian.Uint64(data[12:20]),
+			Timestamp:      binary.BigEndian.Uint64(data[20:28]),
+			Number:         binary.BigEndian.Uint64(data[28:36]),
+			Hash:           common.BytesToHash(data[100:132]),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown L1 attributes selector %x", selector)
+	}
+}
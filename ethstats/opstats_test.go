@@ -0,0 +1,358 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethproto "github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// testOPBackend is a fixed chain of blocks with OP stack specific status.
+type testOPBackend struct {
+	blocks    map[uint64]*types.Block
+	head      uint64
+	safe      uint64
+	finalized uint64
+
+	headFeed event.Feed
+	txFeed   event.Feed
+
+	forwarded, failed uint64
+	required          params.ProtocolVersion
+}
+
+func (b *testOPBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.headFeed.Subscribe(ch)
+}
+func (b *testOPBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.txFeed.Subscribe(ch)
+}
+func (b *testOPBackend) CurrentHeader() *types.Header { return b.blocks[b.head].Header() }
+func (b *testOPBackend) CurrentBlock() *types.Header  { return b.blocks[b.head].Header() }
+func (b *testOPBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	switch number {
+	case rpc.SafeBlockNumber:
+		number = rpc.BlockNumber(b.safe)
+	case rpc.FinalizedBlockNumber:
+		number = rpc.BlockNumber(b.finalized)
+	}
+	if block := b.blocks[uint64(number)]; block != nil {
+		return block.Header(), nil
+	}
+	return nil, nil
+}
+func (b *testOPBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.blocks[uint64(number)], nil
+}
+func (b *testOPBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int { return common.Big0 }
+func (b *testOPBackend) Stats() (int, int)                                    { return 3, 1 }
+func (b *testOPBackend) SyncProgress() ethereum.SyncProgress                  { return ethereum.SyncProgress{} }
+func (b *testOPBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+func (b *testOPBackend) SequencerForwarding() bool { return true }
+func (b *testOPBackend) SequencerForwardStats() (uint64, uint64) {
+	return b.forwarded, b.failed
+}
+func (b *testOPBackend) TxPoolAdmission() (bool, []string) { return true, []string{"deny-list"} }
+func (b *testOPBackend) ProtocolVersions() (params.ProtocolVersion, params.ProtocolVersion) {
+	return params.ProtocolVersion{}, b.required
+}
+
+// ecotoneL1Info encodes the L1 attributes deposit of the Ecotone upgrade.
+func ecotoneL1Info(number, timestamp, sequence uint64, hash common.Hash) []byte {
+	data := make([]byte, 164)
+	copy(data, types.EcotoneL1AttributesSelector)
+	binary.BigEndian.PutUint64(data[12:], sequence)
+	binary.BigEndian.PutUint64(data[20:], timestamp)
+	binary.BigEndian.PutUint64(data[28:], number)
+	copy(data[100:], hash[:])
+	return data
+}
+
+// bedrockL1Info encodes the L1 attributes deposit of the Bedrock upgrade.
+func bedrockL1Info(number, timestamp, sequence uint64, hash common.Hash) []byte {
+	data := make([]byte, 4+32*8)
+	copy(data, types.BedrockL1AttributesSelector)
+	binary.BigEndian.PutUint64(data[4+24:], number)
+	binary.BigEndian.PutUint64(data[4+32+24:], timestamp)
+	copy(data[4+32*3:], hash[:])
+	binary.BigEndian.PutUint64(data[4+32*4+24:], sequence)
+	return data
+}
+
+func newTestOPBlock(number uint64, l1info []byte) *types.Block {
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Time:       1000 + 2*number,
+		Difficulty: common.Big0,
+		BaseFee:    big.NewInt(7),
+	}
+	deposit := types.NewTx(&types.DepositTx{To: &types.L1BlockAddr, Data: l1info})
+	return types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{deposit}})
+}
+
+func TestDecodeL1Origin(t *testing.T) {
+	hash := common.HexToHash("0x01")
+	for _, l1info := range [][]byte{
+		ecotoneL1Info(500, 6000, 3, hash),
+		append(ecotoneL1Info(500, 6000, 3, hash)[:164:164], make([]byte, 12)...), // Isthmus operator fees
+		bedrockL1Info(500, 6000, 3, hash),
+	} {
+		if len(l1info) == 176 {
+			copy(l1info, isthmusL1InfoSelector)
+		}
+		origin, err := decodeL1Origin(newTestOPBlock(1, l1info))
+		if err != nil {
+			t.Fatalf("failed to decode L1 origin: %v", err)
+		}
+		if want := (l1OriginStats{Number: 500, Hash: hash, Timestamp: 6000, SequenceNumber: 3}); *origin != want {
+			t.Fatalf("L1 origin mismatch: have %+v, want %+v", *origin, want)
+		}
+	}
+	for _, l1info := range [][]byte{nil, {0x01, 0x02, 0x03, 0x04}, ecotoneL1Info(1, 1, 1, hash)[:100]} {
+		if _, err := decodeL1Origin(newTestOPBlock(1, l1info)); err == nil {
+			t.Fatalf("invalid L1 attributes decoded: %x", l1info)
+		}
+	}
+	if _, err := decodeL1Origin(types.NewBlockWithHeader(&types.Header{Number: common.Big1})); err == nil {
+		t.Fatalf("L1 origin decoded from block without deposit")
+	}
+}
+
+// statsStandIn is a minimal ethstats server, acknowledging logins and pings and
+// forwarding all other messages.
+type statsStandIn struct {
+	hello  chan map[string]interface{}
+	emits  chan []json.RawMessage
+	server *httptest.Server
+}
+
+func newStatsStandIn(t *testing.T) *statsStandIn {
+	s := &statsStandIn{
+		hello: make(chan map[string]interface{}, 1),
+		emits: make(chan []json.RawMessage, 64),
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var msg map[string][]json.RawMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			var command string
+			json.Unmarshal(msg["emit"][0], &command)
+			switch command {
+			case "hello":
+				var auth map[string]interface{}
+				json.Unmarshal(msg["emit"][1], &auth)
+				s.hello <- auth
+				conn.WriteJSON(map[string][]string{"emit": {"ready"}})
+			case "node-ping":
+				conn.WriteJSON(map[string][]interface{}{"emit": {"node-pong", map[string]string{}}})
+			default:
+				select {
+				case s.emits <- msg["emit"]:
+				default:
+				}
+			}
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// next waits for the next message of the given type.
+func (s *statsStandIn) next(t *testing.T, command string) json.RawMessage {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case emit := <-s.emits:
+			var have string
+			json.Unmarshal(emit[0], &have)
+			if have == command {
+				return emit[1]
+			}
+		case <-timeout:
+			t.Fatalf("no %s message received", command)
+		}
+	}
+}
+
+// Tests that the OP stack specific stats are reported to a stats server.
+// Tests that OP stack stats can only be enabled on backends providing them, and
+// that New keeps them disabled.
+func TestNewWithConfig(t *testing.T) {
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	var (
+		full  = &testOPBackend{}
+		light = struct{ backend }{full}
+		url   = "test:secret@ws://localhost:3000"
+	)
+	tests := []struct {
+		backend backend
+		config  Config
+		fail    bool
+	}{
+		{backend: light, config: Config{URL: url}},
+		{backend: full, config: Config{URL: url, OPStats: true}},
+		{backend: light, config: Config{URL: url, OPStats: true}, fail: true},
+		{backend: full, config: Config{URL: "invalid"}, fail: true},
+	}
+	for i, tt := range tests {
+		if err := NewWithConfig(stack, tt.backend, ethash.NewFaker(), tt.config); (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+	}
+	if err := New(stack, light, ethash.NewFaker(), url); err != nil {
+		t.Errorf("failed to create service without OP stats: %v", err)
+	}
+}
+
+func TestReportOPStats(t *testing.T) {
+	backend := &testOPBackend{
+		blocks:    make(map[uint64]*types.Block),
+		head:      10,
+		safe:      7,
+		finalized: 2,
+		forwarded: 10,
+		failed:    1,
+		required:  params.ProtocolVersionV0{Major: 99}.Encode(),
+	}
+	for i := uint64(0); i <= 11; i++ {
+		backend.blocks[i] = newTestOPBlock(i, ecotoneL1Info(100+i/4, 5000+i, i%4, common.BigToHash(new(big.Int).SetUint64(100+i/4))))
+	}
+	key, _ := crypto.GenerateKey()
+	server := &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		MaxPeers:    1,
+		NoDiscovery: true,
+		Protocols: []p2p.Protocol{{
+			Name:     "eth",
+			Version:  68,
+			NodeInfo: func() interface{} { return &ethproto.NodeInfo{Network: 10} },
+		}},
+	}}
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start p2p server: %v", err)
+	}
+	defer server.Stop()
+
+	standIn := newStatsStandIn(t)
+	service := &Service{
+		server:  server,
+		backend: backend,
+		engine:  ethash.NewFaker(),
+		node:    "test",
+		host:    strings.Replace(standIn.server.URL, "http://", "ws://", 1),
+		pongCh:  make(chan struct{}),
+		histCh:  make(chan []uint64, 1),
+		opStats: true,
+	}
+	if err := service.Start(); err != nil {
+		t.Fatalf("failed to start stats service: %v", err)
+	}
+	defer service.Stop()
+
+	// The schema version is advertised on login
+	select {
+	case auth := <-standIn.hello:
+		if info, _ := auth["info"].(map[string]interface{}); info["opStats"] != float64(opStatsVersion) {
+			t.Fatalf("OP stats version not advertised: %v", auth["info"])
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("no login received")
+	}
+	// The initial report includes the OP stats of the head
+	var report struct {
+		ID    string  `json:"id"`
+		Stats opStats `json:"stats"`
+	}
+	if err := json.Unmarshal(standIn.next(t, "op-stats"), &report); err != nil {
+		t.Fatalf("failed to decode OP stats: %v", err)
+	}
+	stats := report.Stats
+	if report.ID != "test" || stats.Version != opStatsVersion || stats.Head != 10 {
+		t.Fatalf("invalid report: %+v", report)
+	}
+	if stats.Safe == nil || stats.Safe.Number != 7 || stats.Safe.Lag != 3 || stats.Safe.LagSeconds != 6 {
+		t.Fatalf("safe lag mismatch: %+v", stats.Safe)
+	}
+	if stats.Finalized == nil || stats.Finalized.Number != 2 || stats.Finalized.Lag != 8 || stats.Finalized.Hash != backend.blocks[2].Hash() {
+		t.Fatalf("finalized lag mismatch: %+v", stats.Finalized)
+	}
+	if origin := stats.L1Origin; origin == nil || origin.Number != 102 || origin.SequenceNumber != 2 || origin.Timestamp != 5010 {
+		t.Fatalf("L1 origin mismatch: %+v", origin)
+	}
+	if fwd := stats.SequencerForwarding; fwd == nil || fwd.Forwarded != 10 || fwd.Failed != 1 || fwd.ErrorRate != 0.1 {
+		t.Fatalf("forwarding stats mismatch: %+v", fwd)
+	}
+	if pool := stats.TxPool; !pool.Admission || len(pool.Policies) != 1 || pool.Pending != 3 || pool.Queued != 1 {
+		t.Fatalf("txpool stats mismatch: %+v", pool)
+	}
+	if proto := stats.ProtocolVersion; proto.RequiredStatus != "outdated-major" || proto.RecommendedStatus != "unknown" || proto.Local != params.OPStackSupport.String() {
+		t.Fatalf("protocol version stats mismatch: %+v", proto)
+	}
+	// New blocks are reported along with their OP stats, error rates relative
+	// to the previous report
+	backend.forwarded, backend.failed = 20, 6
+	backend.headFeed.Send(core.ChainHeadEvent{Block: backend.blocks[11]})
+
+	if err := json.Unmarshal(standIn.next(t, "op-stats"), &report); err != nil {
+		t.Fatalf("failed to decode OP stats: %v", err)
+	}
+	if stats := report.Stats; stats.Head != 11 || stats.L1Origin.SequenceNumber != 3 || stats.SequencerForwarding.ErrorRate != 0.5 {
+		t.Fatalf("invalid block report: %+v", stats)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package execdb
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// Builder is used to create post-merge execution history archives of block data.
+//
+// The archives follow the structure of Era1 files, without the total difficulty
+// and accumulator entries, which are meaningless after the merge. Instead, they
+// index the blocks by hash and commit to their own contents with a checksum:
+//
+//	erae := Version | block-tuple* | BlockHashes | Checksum | BlockIndex
+//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts
+//
+// Each basic element is its own entry:
+//
+//	Version            = { type: [0x65, 0x32], data: nil }
+//	CompressedHeader   = { type: [0x03, 0x00], data: snappyFramed(rlp(header)) }
+//	CompressedBody     = { type: [0x04, 0x00], data: snappyFramed(rlp(body)) }
+//	CompressedReceipts = { type: [0x08, 0x00], data: snappyFramed(rlp(archive-receipts)) }
+//	BlockHashes        = { type: [0x32, 0x67], data: block-hash | block-hash ... }
+//	Checksum           = { type: [0x32, 0x68], data: sha256(preceding-entries) }
+//	BlockIndex         = { type: [0x32, 0x66], data: block-index }
+//
+// Receipts are stored with their consensus fields minus the bloom, along with
+// the deposit nonce and receipt version of deposit receipts and the L1 fee
+// fields of OP receipts, so they can be served without the chain configuration.
+//
+// BlockIndex has the same format as in Era1 files:
+//
+//	block-index := starting-number | index | index | index ... | count
+type Builder struct {
+	w        *e2store.Writer
+	h        hash.Hash
+	startNum *uint64
+	indexes  []uint64
+	hashes   []common.Hash
+	written  int
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+// NewBuilder returns a new Builder instance.
+func NewBuilder(w io.Writer) *Builder {
+	var (
+		buf = bytes.NewBuffer(nil)
+		h   = sha256.New()
+	)
+	return &Builder{
+		w:      e2store.NewWriter(io.MultiWriter(w, h)),
+		h:      h,
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add writes the compressed block and receipts entries to the underlying
+// e2store file.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts) error {
+	eh, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	eb, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	er, err := encodeReceipts(receipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(eh, eb, er, block.NumberU64(), block.Hash())
+}
+
+// AddRLP writes the compressed block and receipts entries to the underlying
+// e2store file. The receipts must be in the archive encoding.
+func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash common.Hash) error {
+	// Write version entry before first block.
+	if b.startNum == nil {
+		n, err := b.w.Write(TypeVersion, nil)
+		if err != nil {
+			return err
+		}
+		startNum := number
+		b.startNum = &startNum
+		b.written += n
+	}
+	if len(b.indexes) >= MaxSize {
+		return fmt.Errorf("exceeds maximum batch size of %d", MaxSize)
+	}
+	if want := *b.startNum + uint64(len(b.indexes)); number != want {
+		return fmt.Errorf("non-contiguous block %d, want %d", number, want)
+	}
+	b.indexes = append(b.indexes, uint64(b.written))
+	b.hashes = append(b.hashes, hash)
+
+	// Write block data.
+	if err := b.snappyWrite(TypeCompressedHeader, header); err != nil {
+		return err
+	}
+	if err := b.snappyWrite(TypeCompressedBody, body); err != nil {
+		return err
+	}
+	return b.snappyWrite(TypeCompressedReceipts, receipts)
+}
+
+// Finalize writes the block hash, checksum and block index entries, returning
+// the checksum of the archive.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.startNum == nil {
+		return common.Hash{}, errors.New("finalize called on empty builder")
+	}
+	hashes := make([]byte, 0, len(b.hashes)*common.HashLength)
+	for _, hash := range b.hashes {
+		hashes = append(hashes, hash[:]...)
+	}
+	n, err := b.w.Write(TypeBlockHashes, hashes)
+	b.written += n
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error writing block hashes: %w", err)
+	}
+	// The checksum covers everything written so far.
+	sum := common.BytesToHash(b.h.Sum(nil))
+	n, err = b.w.Write(TypeChecksum, sum[:])
+	b.written += n
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error writing checksum: %w", err)
+	}
+	// Construct block index, with offsets relative to the beginning of the
+	// index entry: "start | index | index | ... | count"
+	var (
+		base  = int64(b.written)
+		count = len(b.indexes)
+		index = make([]byte, 16+count*8)
+	)
+	binary.LittleEndian.PutUint64(index, *b.startNum)
+	for i, offset := range b.indexes {
+		relative := int64(offset) - base
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(relative))
+	}
+	binary.LittleEndian.PutUint64(index[8+count*8:], uint64(count))
+
+	if _, err := b.w.Write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, fmt.Errorf("unable to write block index: %w", err)
+	}
+	return sum, nil
+}
+
+// snappyWrite is a small helper to take care snappy encoding and writing an e2store entry.
+func (b *Builder) snappyWrite(typ uint16, in []byte) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if _, err := b.snappy.Write(in); err != nil {
+		return fmt.Errorf("error snappy encoding: %w", err)
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return fmt.Errorf("error flushing snappy encoding: %w", err)
+	}
+	n, err := b.w.Write(typ, b.buf.Bytes())
+	b.written += n
+	if err != nil {
+		return fmt.Errorf("error writing e2store entry: %w", err)
+	}
+	return nil
+}
+
+// encodeReceipts encodes the receipts of a block in the archive encoding.
+func encodeReceipts(receipts types.Receipts) ([]byte, error) {
+	encs := make([]*receiptRLP, len(receipts))
+	for i, receipt := range receipts {
+		enc, err := newReceiptRLP(receipt)
+		if err != nil {
+			return nil, err
+		}
+		encs[i] = enc
+	}
+	return rlp.EncodeToBytes(encs)
+}
@@ -0,0 +1,427 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package execdb implements post-merge execution history archives, an era-style
+// format for blocks and receipts without total difficulty, able to hold the
+// deposit transactions and receipts of OP chains.
+package execdb
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/snappy"
+)
+
+var (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x08
+	TypeBlockIndex         uint16 = 0x3266
+	TypeBlockHashes        uint16 = 0x3267
+	TypeChecksum           uint16 = 0x3268
+
+	MaxSize = 8192
+)
+
+// Filename returns a recognizable file name for the archive of the specified
+// epoch and network.
+func Filename(network string, epoch int, checksum common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.erae", network, epoch, checksum.Hex()[2:10])
+}
+
+// ReadDir reads all the archives in a directory for a given network. As post-merge
+// history doesn't have to start at genesis, the epochs must be contiguous but
+// may start at any number.
+// Format: <network>-<epoch>-<hexchecksum>.erae
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+	type archive struct {
+		epoch uint64
+		name  string
+	}
+	var archives []archive
+	for _, entry := range entries {
+		if path.Ext(entry.Name()) != ".erae" {
+			continue
+		}
+		// Network names may contain dashes, parse from the end
+		parts := strings.Split(strings.TrimSuffix(entry.Name(), ".erae"), "-")
+		if len(parts) < 3 || strings.Join(parts[:len(parts)-2], "-") != network {
+			continue
+		}
+		epoch, err := strconv.ParseUint(parts[len(parts)-2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed erae filename: %s", entry.Name())
+		}
+		archives = append(archives, archive{epoch, entry.Name()})
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i].epoch < archives[j].epoch })
+
+	names := make([]string, len(archives))
+	for i, archive := range archives {
+		if i > 0 && archive.epoch != archives[i-1].epoch+1 {
+			return nil, fmt.Errorf("missing epoch %d", archives[i-1].epoch+1)
+		}
+		names[i] = archive.name
+	}
+	return names, nil
+}
+
+type ReadAtSeekCloser interface {
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// Era reads a post-merge execution history archive.
+type Era struct {
+	f   ReadAtSeekCloser // backing archive file
+	s   *e2store.Reader  // e2store reader over f
+	m   metadata         // start, count, length info
+	mu  *sync.Mutex      // lock for buf
+	buf [8]byte          // buffer reading entry offsets
+}
+
+// From returns an Era backed by f.
+func From(f ReadAtSeekCloser) (*Era, error) {
+	m, err := readMetadata(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Era{
+		f:  f,
+		s:  e2store.NewReader(f),
+		m:  m,
+		mu: new(sync.Mutex),
+	}, nil
+}
+
+// Open returns an Era backed by the given filename.
+func Open(filename string) (*Era, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return From(f)
+}
+
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// GetBlockByNumber returns the block with the given number.
+func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	r, n, err := newSnappyReader(e.s, TypeCompressedHeader, off)
+	if err != nil {
+		return nil, err
+	}
+	var header types.Header
+	if err := rlp.Decode(r, &header); err != nil {
+		return nil, err
+	}
+	off += n
+	r, _, err = newSnappyReader(e.s, TypeCompressedBody, off)
+	if err != nil {
+		return nil, err
+	}
+	var body types.Body
+	if err := rlp.Decode(r, &body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(&header).WithBody(body), nil
+}
+
+// GetBlockByHash returns the block with the given hash, or nil if the archive
+// doesn't contain it.
+func (e *Era) GetBlockByHash(hash common.Hash) (*types.Block, error) {
+	hashes, err := e.Hashes()
+	if err != nil {
+		return nil, err
+	}
+	for i, h := range hashes {
+		if h == hash {
+			return e.GetBlockByNumber(e.m.start + uint64(i))
+		}
+	}
+	return nil, nil
+}
+
+// GetReceiptsByNumber returns the receipts of the block with the given number.
+// Only the consensus fields and the OP specific fields stored in the archive
+// are set, the ones derived from the block are not.
+func (e *Era) GetReceiptsByNumber(num uint64) (types.Receipts, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	// Skip over the header and body entries.
+	for i := 0; i < 2; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := newSnappyReader(e.s, TypeCompressedReceipts, off)
+	if err != nil {
+		return nil, err
+	}
+	return decodeReceipts(r)
+}
+
+// Hashes returns the hashes of all the blocks in the archive.
+func (e *Era) Hashes() ([]common.Hash, error) {
+	r, _, err := e.s.ReaderAt(TypeBlockHashes, e.hashesOffset())
+	if err != nil {
+		return nil, err
+	}
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(blob)) != e.m.count*common.HashLength {
+		return nil, fmt.Errorf("invalid block hashes length: have %d, want %d", len(blob), e.m.count*common.HashLength)
+	}
+	hashes := make([]common.Hash, e.m.count)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash(blob[i*common.HashLength : (i+1)*common.HashLength])
+	}
+	return hashes, nil
+}
+
+// Checksum returns the checksum stored in the archive.
+func (e *Era) Checksum() (common.Hash, error) {
+	r, _, err := e.s.ReaderAt(TypeChecksum, e.checksumOffset())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(blob) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid checksum length: %d", len(blob))
+	}
+	return common.BytesToHash(blob), nil
+}
+
+// Verify checks the integrity of the archive: the checksum over its contents,
+// and that every block matches the hash index, links to its parent, and that
+// its transactions, uncles, withdrawals and receipts match its header.
+func (e *Era) Verify() error {
+	want, err := e.Checksum()
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(e.f, 0, e.checksumOffset())); err != nil {
+		return fmt.Errorf("unable to calculate checksum: %w", err)
+	}
+	if have := common.BytesToHash(h.Sum(nil)); have != want {
+		return fmt.Errorf("checksum mismatch: have %x, want %x", have, want)
+	}
+	hashes, err := e.Hashes()
+	if err != nil {
+		return err
+	}
+	it, err := NewIterator(e)
+	if err != nil {
+		return err
+	}
+	var parent common.Hash
+	for it.Next() {
+		block, receipts, err := it.BlockAndReceipts()
+		if err != nil {
+			return fmt.Errorf("error reading block %d: %w", it.Number(), err)
+		}
+		if err := verifyBlock(block, receipts); err != nil {
+			return fmt.Errorf("invalid block %d: %w", it.Number(), err)
+		}
+		i := it.Number() - e.m.start
+		if block.NumberU64() != it.Number() {
+			return fmt.Errorf("block number mismatch: have %d, want %d", block.NumberU64(), it.Number())
+		}
+		if block.Hash() != hashes[i] {
+			return fmt.Errorf("block %d hash mismatch: have %x, index %x", it.Number(), block.Hash(), hashes[i])
+		}
+		if i > 0 && block.ParentHash() != parent {
+			return fmt.Errorf("block %d parent mismatch: have %x, want %x", it.Number(), block.ParentHash(), parent)
+		}
+		parent = block.Hash()
+	}
+	return it.Error()
+}
+
+// verifyBlock checks that the contents of a block and its receipts match the
+// commitments in its header.
+func verifyBlock(block *types.Block, receipts types.Receipts) error {
+	header := block.Header()
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return fmt.Errorf("transaction root mismatch: have %x, want %x", hash, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("uncle root mismatch: have %x, want %x", hash, header.UncleHash)
+	}
+	// From Isthmus, OP blocks commit to the withdrawal contract storage instead
+	// of the (always empty) withdrawal list, which can't be checked here.
+	if withdrawals := block.Withdrawals(); len(withdrawals) > 0 {
+		if header.WithdrawalsHash == nil {
+			return errors.New("unexpected withdrawals")
+		}
+		if hash := types.DeriveSha(withdrawals, trie.NewStackTrie(nil)); hash != *header.WithdrawalsHash {
+			return fmt.Errorf("withdrawal root mismatch: have %x, want %x", hash, *header.WithdrawalsHash)
+		}
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+		return fmt.Errorf("receipt root mismatch: have %x, want %x", hash, header.ReceiptHash)
+	}
+	if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+		return errors.New("bloom mismatch")
+	}
+	return nil
+}
+
+// Start returns the listed start block.
+func (e *Era) Start() uint64 {
+	return e.m.start
+}
+
+// Count returns the total number of blocks in the archive.
+func (e *Era) Count() uint64 {
+	return e.m.count
+}
+
+// indexOffset returns the offset of the block index entry.
+func (e *Era) indexOffset() int64 {
+	return e.m.length - 24 - int64(e.m.count)*8 // skips start, count, and header
+}
+
+// checksumOffset returns the offset of the checksum entry, which directly
+// precedes the block index.
+func (e *Era) checksumOffset() int64 {
+	return e.indexOffset() - 8 - common.HashLength
+}
+
+// hashesOffset returns the offset of the block hashes entry, which directly
+// precedes the checksum.
+func (e *Era) hashesOffset() int64 {
+	return e.checksumOffset() - 8 - int64(e.m.count)*common.HashLength
+}
+
+// readOffset reads a specific block's offset from the block index. The value n
+// is the absolute block number desired.
+func (e *Era) readOffset(n uint64) (int64, error) {
+	var (
+		blockIndexRecordOffset = e.indexOffset()
+		firstIndex             = blockIndexRecordOffset + 16 // first index after header / start-num
+		indexOffset            = int64(n-e.m.start) * 8      // desired index * size of indexes
+		offOffset              = firstIndex + indexOffset    // offset of block offset
+	)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.buf[:])
+	if _, err := e.f.ReadAt(e.buf[:], offOffset); err != nil {
+		return 0, err
+	}
+	// The block offset is relative to the start of the block index record.
+	return blockIndexRecordOffset + int64(binary.LittleEndian.Uint64(e.buf[:])), nil
+}
+
+// newSnappyReader returns a snappy.Reader for the e2store entry value at off.
+func newSnappyReader(e *e2store.Reader, expectedType uint16, off int64) (io.Reader, int64, error) {
+	r, n, err := e.ReaderAt(expectedType, off)
+	if err != nil {
+		return nil, 0, err
+	}
+	return snappy.NewReader(r), int64(n), err
+}
+
+// decodeReceipts decodes the archive encoding of the receipts of a block.
+func decodeReceipts(r io.Reader) (types.Receipts, error) {
+	var encs []*receiptRLP
+	if err := rlp.Decode(r, &encs); err != nil {
+		return nil, err
+	}
+	receipts := make(types.Receipts, len(encs))
+	for i, enc := range encs {
+		receipt, err := enc.receipt()
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = receipt
+	}
+	return receipts, nil
+}
+
+// metadata wraps the metadata in the block index.
+type metadata struct {
+	start  uint64
+	count  uint64
+	length int64
+}
+
+// readMetadata reads the metadata stored in an archive's block index.
+func readMetadata(f ReadAtSeekCloser) (m metadata, err error) {
+	// Determine length of reader.
+	if m.length, err = f.Seek(0, io.SeekEnd); err != nil {
+		return
+	}
+	b := make([]byte, 16)
+	// Read count. It's the last 8 bytes of the file.
+	if _, err = f.ReadAt(b[:8], m.length-8); err != nil {
+		return
+	}
+	m.count = binary.LittleEndian.Uint64(b)
+	// Read start. It's at the offset -sizeof(m.count) -
+	// count*sizeof(indexEntry) - sizeof(m.start)
+	if _, err = f.ReadAt(b[8:], m.length-16-int64(m.count*8)); err != nil {
+		return
+	}
+	m.start = binary.LittleEndian.Uint64(b[8:])
+	return
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package execdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		// logger emits a log with the call value
+		logger = common.HexToAddress("0x10")
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr:   {Balance: big.NewInt(params.Ether)},
+				logger: {Code: common.FromHex("0x3460005260206000a000")},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 16, func(i int, b *core.BlockGen) {
+		for j := 0; j < i%4; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), logger, big.NewInt(int64(j)), 50000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	var (
+		dir      = t.TempDir()
+		filename = filepath.Join(dir, Filename("test", 1, common.Hash{}))
+	)
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("error creating file: %v", err)
+	}
+	builder := NewBuilder(f)
+	for i, block := range blocks {
+		if err := builder.Add(block, receipts[i]); err != nil {
+			t.Fatalf("error adding block %d: %v", block.NumberU64(), err)
+		}
+	}
+	checksum, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("error finalizing archive: %v", err)
+	}
+	f.Close()
+
+	e, err := Open(filename)
+	if err != nil {
+		t.Fatalf("error opening archive: %v", err)
+	}
+	defer e.Close()
+
+	if e.Start() != 1 || e.Count() != uint64(len(blocks)) {
+		t.Fatalf("metadata mismatch: have start %d count %d, want start 1 count %d", e.Start(), e.Count(), len(blocks))
+	}
+	if have, err := e.Checksum(); err != nil || have != checksum {
+		t.Fatalf("checksum mismatch: have %x (%v), want %x", have, err, checksum)
+	}
+	if err := e.Verify(); err != nil {
+		t.Fatalf("failed to verify archive: %v", err)
+	}
+	for i, want := range blocks {
+		block, err := e.GetBlockByNumber(want.NumberU64())
+		if err != nil {
+			t.Fatalf("error reading block %d: %v", want.NumberU64(), err)
+		}
+		if block.Hash() != want.Hash() {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", want.NumberU64(), block.Hash(), want.Hash())
+		}
+		if block, err := e.GetBlockByHash(want.Hash()); err != nil || block == nil || block.NumberU64() != want.NumberU64() {
+			t.Errorf("block %d: lookup by hash failed: %v", want.NumberU64(), err)
+		}
+		have, err := e.GetReceiptsByNumber(want.NumberU64())
+		if err != nil {
+			t.Fatalf("error reading receipts %d: %v", want.NumberU64(), err)
+		}
+		if hash, want := types.DeriveSha(have, trie.NewStackTrie(nil)), types.DeriveSha(receipts[i], trie.NewStackTrie(nil)); hash != want {
+			t.Errorf("block %d: receipts root mismatch: have %x, want %x", i, hash, want)
+		}
+	}
+	if block, err := e.GetBlockByHash(common.Hash{0x01}); err != nil || block != nil {
+		t.Errorf("unexpected block for unknown hash: %v, %v", block, err)
+	}
+	// Corrupt a block and ensure verification fails
+	blob, _ := os.ReadFile(filename)
+	blob[64] ^= 0xff
+	corrupt := filepath.Join(dir, "corrupt.erae")
+	os.WriteFile(corrupt, blob, 0644)
+
+	c, err := Open(corrupt)
+	if err != nil {
+		t.Fatalf("error opening corrupt archive: %v", err)
+	}
+	defer c.Close()
+	if err := c.Verify(); err == nil {
+		t.Fatalf("corrupt archive verified")
+	}
+	// Look the archive up in its directory
+	if names, err := ReadDir(dir, "test"); err != nil || len(names) != 1 {
+		t.Fatalf("failed to read directory: %v %v", names, err)
+	}
+}
+
+// Tests that the OP specific receipt fields survive the archive encoding.
+func TestReceiptEncoding(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nonce, version = uint64(7), uint64(1)
+		baseScalar     = uint64(1368)
+		blobScalar     = uint64(810949)
+		log            = &types.Log{Address: common.Address{0x01}, Topics: []common.Hash{{0x02}}, Data: []byte{0x03}}
+	)
+	receipts := types.Receipts{
+		// Regolith and Canyon deposits
+		{Type: types.DepositTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}, DepositNonce: &nonce},
+		{Type: types.DepositTxType, Status: types.ReceiptStatusFailed, CumulativeGasUsed: 42000, Logs: []*types.Log{log}, DepositNonce: &nonce, DepositReceiptVersion: &version},
+		// Bedrock and Ecotone transactions
+		{
+			Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 63000, Logs: []*types.Log{},
+			L1GasPrice: big.NewInt(100), L1GasUsed: big.NewInt(1600), L1Fee: big.NewInt(109600), FeeScalar: big.NewFloat(0.684),
+		},
+		{
+			Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 84000, Logs: []*types.Log{log},
+			L1GasPrice: big.NewInt(100), L1GasUsed: big.NewInt(1600), L1Fee: big.NewInt(0), L1BlobBaseFee: big.NewInt(1),
+			L1BaseFeeScalar: &baseScalar, L1BlobBaseFeeScalar: &blobScalar,
+		},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+	blob, err := encodeReceipts(receipts)
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	have, err := decodeReceipts(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	haveJSON, _ := json.Marshal(have)
+	wantJSON, _ := json.Marshal(receipts)
+	if !bytes.Equal(haveJSON, wantJSON) {
+		t.Fatalf("receipts mismatch:\nhave %s\nwant %s", haveJSON, wantJSON)
+	}
+}
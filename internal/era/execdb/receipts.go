@@ -0,0 +1,147 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package execdb
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	receiptStatusFailedRLP     = []byte{}
+	receiptStatusSuccessfulRLP = []byte{0x01}
+)
+
+// receiptRLP is the archive encoding of a receipt. Unlike the consensus encoding
+// it omits the bloom, which can be recomputed from the logs, and unlike the
+// storage encoding it retains the L1 fee fields of OP receipts, which can only
+// be derived from the chain configuration and the L1 attributes of the block.
+type receiptRLP struct {
+	Type              uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*types.Log
+	Deposit           *depositRLP `rlp:"nil"`
+	L1Fee             *l1FeeRLP   `rlp:"nil"`
+}
+
+// depositRLP holds the fields of Regolith deposit receipts.
+type depositRLP struct {
+	Nonce          uint64
+	ReceiptVersion *uint64 `rlp:"optional"` // Set from Canyon
+}
+
+// l1FeeRLP holds the L1 fee fields of post-Bedrock non-deposit receipts.
+type l1FeeRLP struct {
+	GasPrice  *big.Int
+	GasUsed   *big.Int
+	Fee       *big.Int
+	FeeScalar []byte         // Gob encoded, empty from Ecotone
+	Ecotone   *ecotoneFeeRLP `rlp:"nil"`
+}
+
+// ecotoneFeeRLP holds the L1 fee fields introduced in Ecotone.
+type ecotoneFeeRLP struct {
+	BlobBaseFee       *big.Int
+	BaseFeeScalar     uint64
+	BlobBaseFeeScalar uint64
+}
+
+// newReceiptRLP converts a receipt to its archive encoding.
+func newReceiptRLP(r *types.Receipt) (*receiptRLP, error) {
+	enc := &receiptRLP{
+		Type:              r.Type,
+		PostStateOrStatus: r.PostState,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              r.Logs,
+	}
+	if len(r.PostState) == 0 {
+		enc.PostStateOrStatus = receiptStatusSuccessfulRLP
+		if r.Status == types.ReceiptStatusFailed {
+			enc.PostStateOrStatus = receiptStatusFailedRLP
+		}
+	}
+	if r.DepositNonce != nil {
+		enc.Deposit = &depositRLP{Nonce: *r.DepositNonce, ReceiptVersion: r.DepositReceiptVersion}
+	}
+	if r.L1GasPrice != nil {
+		enc.L1Fee = &l1FeeRLP{GasPrice: r.L1GasPrice, GasUsed: r.L1GasUsed, Fee: r.L1Fee}
+		if r.FeeScalar != nil {
+			scalar, err := r.FeeScalar.GobEncode()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode fee scalar: %w", err)
+			}
+			enc.L1Fee.FeeScalar = scalar
+		}
+		if r.L1BaseFeeScalar != nil && r.L1BlobBaseFeeScalar != nil {
+			enc.L1Fee.Ecotone = &ecotoneFeeRLP{
+				BlobBaseFee:       r.L1BlobBaseFee,
+				BaseFeeScalar:     *r.L1BaseFeeScalar,
+				BlobBaseFeeScalar: *r.L1BlobBaseFeeScalar,
+			}
+		}
+	}
+	return enc, nil
+}
+
+// receipt converts the archive encoding of a receipt back into a receipt, with
+// the consensus and OP fields set. The fields derived from the block are left
+// unset.
+func (enc *receiptRLP) receipt() (*types.Receipt, error) {
+	r := &types.Receipt{
+		Type:              enc.Type,
+		CumulativeGasUsed: enc.CumulativeGasUsed,
+		Logs:              enc.Logs,
+	}
+	switch {
+	case bytes.Equal(enc.PostStateOrStatus, receiptStatusSuccessfulRLP):
+		r.Status = types.ReceiptStatusSuccessful
+	case bytes.Equal(enc.PostStateOrStatus, receiptStatusFailedRLP):
+		r.Status = types.ReceiptStatusFailed
+	case len(enc.PostStateOrStatus) == common.HashLength:
+		r.PostState = enc.PostStateOrStatus
+	default:
+		return nil, fmt.Errorf("invalid receipt status %x", enc.PostStateOrStatus)
+	}
+	r.Bloom = types.CreateBloom(types.Receipts{r})
+
+	if enc.Deposit != nil {
+		nonce := enc.Deposit.Nonce
+		r.DepositNonce = &nonce
+		r.DepositReceiptVersion = enc.Deposit.ReceiptVersion
+	}
+	if fee := enc.L1Fee; fee != nil {
+		r.L1GasPrice, r.L1GasUsed, r.L1Fee = fee.GasPrice, fee.GasUsed, fee.Fee
+		if len(fee.FeeScalar) > 0 {
+			r.FeeScalar = new(big.Float)
+			if err := r.FeeScalar.GobDecode(fee.FeeScalar); err != nil {
+				return nil, fmt.Errorf("invalid fee scalar: %w", err)
+			}
+		}
+		if fee.Ecotone != nil {
+			baseFeeScalar, blobBaseFeeScalar := fee.Ecotone.BaseFeeScalar, fee.Ecotone.BlobBaseFeeScalar
+			r.L1BlobBaseFee = fee.Ecotone.BlobBaseFee
+			r.L1BaseFeeScalar = &baseFeeScalar
+			r.L1BlobBaseFeeScalar = &blobBaseFeeScalar
+		}
+	}
+	return r, nil
+}
@@ -538,7 +538,7 @@ func (api *PersonalAccountAPI) SignTransaction(ctx context.Context, args Transac
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, signed}, nil
+	return &SignTransactionResult{Raw: data, Tx: signed}, nil
 }
 
 // Sign calculates an Ethereum ECDSA signature for:
@@ -2195,7 +2195,7 @@ func (api *TransactionAPI) FillTransaction(ctx context.Context, args Transaction
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, tx}, nil
+	return &SignTransactionResult{Raw: data, Tx: tx}, nil
 }
 
 // SendRawTransaction will add the signed transaction to the transaction pool.
@@ -2237,6 +2237,10 @@ func (api *TransactionAPI) Sign(addr common.Address, data hexutil.Bytes) (hexuti
 type SignTransactionResult struct {
 	Raw hexutil.Bytes      `json:"raw"`
 	Tx  *types.Transaction `json:"tx"`
+
+	// Conditional is the validated conditional to submit the transaction with
+	// through eth_sendRawTransactionConditional, if one was requested.
+	Conditional *types.TransactionConditional `json:"conditional,omitempty"`
 }
 
 // SignTransaction will sign the given transaction with the from account.
@@ -2283,7 +2287,7 @@ func (api *TransactionAPI) SignTransaction(ctx context.Context, args Transaction
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, signed}, nil
+	return &SignTransactionResult{Raw: data, Tx: signed}, nil
 }
 
 // PendingTransactions returns the transactions that are in the transaction pool
@@ -17,6 +17,8 @@
 package ethapi
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
@@ -41,3 +43,99 @@ func (api *DebugAPI) DbAncient(kind string, number uint64) (hexutil.Bytes, error
 func (api *DebugAPI) DbAncients() (uint64, error) {
 	return api.b.ChainDb().Ancients()
 }
+
+const (
+	// dbMaxItems is the maximum number of keys or ancient items served by a
+	// single batched database request.
+	dbMaxItems = 1024
+
+	// dbMaxBytes is the soft limit of the data size served by a single batched
+	// database request.
+	dbMaxBytes = 4 * 1024 * 1024
+)
+
+// DbGetMany returns the raw values of a batch of keys stored in the database,
+// with nil for the missing ones.
+func (api *DebugAPI) DbGetMany(keys []string) ([]*hexutil.Bytes, error) {
+	if len(keys) > dbMaxItems {
+		return nil, fmt.Errorf("too many keys requested: %d > %d", len(keys), dbMaxItems)
+	}
+	db := api.b.ChainDb()
+	values := make([]*hexutil.Bytes, len(keys))
+	for i, key := range keys {
+		blob, err := common.ParseHexOrString(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, err := db.Get(blob); err == nil {
+			values[i] = (*hexutil.Bytes)(&value)
+		}
+	}
+	return values, nil
+}
+
+// DbRangeResult is a page of database entries returned by DbRange.
+type DbRangeResult struct {
+	Keys   []hexutil.Bytes `json:"keys"`
+	Values []hexutil.Bytes `json:"values"`
+	Next   hexutil.Bytes   `json:"next,omitempty"` // Start of the next page relative to the prefix, empty at the end
+}
+
+// DbRange iterates the database entries with the given prefix, from the given
+// start relative to the prefix, returning at most limit entries. It is a paginated
+// mapping to the `KeyValueStore.NewIterator` method.
+func (api *DebugAPI) DbRange(prefix hexutil.Bytes, start hexutil.Bytes, limit int) (*DbRangeResult, error) {
+	if limit <= 0 || limit > dbMaxItems {
+		limit = dbMaxItems
+	}
+	it := api.b.ChainDb().NewIterator(prefix, start)
+	defer it.Release()
+
+	var (
+		result = &DbRangeResult{Keys: []hexutil.Bytes{}, Values: []hexutil.Bytes{}}
+		size   int
+	)
+	for it.Next() {
+		if len(result.Keys) >= limit || size >= dbMaxBytes {
+			result.Next = common.CopyBytes(it.Key()[len(prefix):])
+			break
+		}
+		result.Keys = append(result.Keys, common.CopyBytes(it.Key()))
+		result.Values = append(result.Values, common.CopyBytes(it.Value()))
+		size += len(it.Key()) + len(it.Value())
+	}
+	return result, it.Error()
+}
+
+// DbAncientRange retrieves a batch of consecutive ancient binary blobs from the
+// append-only immutable files. It is a mapping to the `AncientReaderOp.AncientRange`
+// method, with the count and size capped by the server.
+func (api *DebugAPI) DbAncientRange(kind string, start, count, maxBytes uint64) ([]hexutil.Bytes, error) {
+	if count > dbMaxItems {
+		count = dbMaxItems
+	}
+	if maxBytes == 0 || maxBytes > dbMaxBytes {
+		maxBytes = dbMaxBytes
+	}
+	blobs, err := api.b.ChainDb().AncientRange(kind, start, count, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]hexutil.Bytes, len(blobs))
+	for i, blob := range blobs {
+		result[i] = blob
+	}
+	return result, nil
+}
+
+// DbAncientSize returns the size of the given ancient table.
+// It is a mapping to the `AncientReaderOp.AncientSize` method
+func (api *DebugAPI) DbAncientSize(kind string) (uint64, error) {
+	return api.b.ChainDb().AncientSize(kind)
+}
+
+// DbTail returns the number of the first stored item in the ancient store.
+// It is a mapping to the `AncientReaderOp.Tail` method
+func (api *DebugAPI) DbTail() (uint64, error) {
+	return api.b.ChainDb().Tail()
+}
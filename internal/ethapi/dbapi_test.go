@@ -0,0 +1,168 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/remotedb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that a remote database served by the debug API behaves like the local
+// database it is backed by.
+func TestRemoteDatabase(t *testing.T) {
+	t.Parallel()
+
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Fill the key-value store with enough entries to span several pages
+	for i := 0; i < 2500; i++ {
+		db.Put([]byte(fmt.Sprintf("a-%05d", i)), []byte(fmt.Sprintf("value-%d", i)))
+	}
+	db.Put([]byte("b-0"), []byte("other"))
+
+	var blocks []*types.Block
+	for i := 0; i < 8; i++ {
+		blocks = append(blocks, types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), Extra: []byte{byte(i)}}))
+	}
+	receipts := make([]types.Receipts, len(blocks))
+	if _, err := rawdb.WriteAncientBlocks(db, blocks, receipts, big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", NewDebugAPI(&testBackend{db: db})); err != nil {
+		t.Fatalf("failed to register debug api: %v", err)
+	}
+	remote := remotedb.New(rpc.DialInProc(server))
+	defer remote.Close()
+
+	// Single and batched lookups
+	if value, err := remote.Get([]byte("a-00042")); err != nil || string(value) != "value-42" {
+		t.Fatalf("get mismatch: have %q, %v", value, err)
+	}
+	if ok, _ := remote.Has([]byte("missing")); ok {
+		t.Fatalf("missing key reported as present")
+	}
+	keys := [][]byte{[]byte("a-00001"), []byte("missing"), []byte("a-02000")}
+	for i := 0; i < 1500; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("a-%05d", i)))
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	req := make([]hexutil.Bytes, len(keys))
+	for i, key := range keys {
+		req[i] = key
+	}
+	var values []*hexutil.Bytes
+	if err := client.Call(&values, "debug_dbGetMany", req[:dbMaxItems]); err != nil {
+		t.Fatalf("failed to get many: %v", err)
+	}
+	for i, key := range keys[:dbMaxItems] {
+		want, _ := db.Get(key)
+		if have := values[i]; (have == nil) != (want == nil) || (have != nil && !bytes.Equal(*have, want)) {
+			t.Fatalf("value %d mismatch: have %v, want %q", i, have, want)
+		}
+	}
+	if err := client.Call(&values, "debug_dbGetMany", req); err == nil {
+		t.Fatalf("oversized batch accepted")
+	}
+	// Values of mutable keys must not be cached, unlike the ones of immutable keys
+	db.Put([]byte("a-00042"), []byte("changed"))
+	if value, err := remote.Get([]byte("a-00042")); err != nil || string(value) != "changed" {
+		t.Fatalf("stale value of mutable key: have %q, %v", value, err)
+	}
+	code := append(common.CopyBytes(rawdb.CodePrefix), common.Hash{0x01}.Bytes()...)
+	db.Put(code, []byte("code"))
+	if value, err := remote.Get(code); err != nil || string(value) != "code" {
+		t.Fatalf("code mismatch: have %q, %v", value, err)
+	}
+	db.Delete(code)
+	if value, err := remote.Get(code); err != nil || string(value) != "code" {
+		t.Fatalf("code not cached: have %q, %v", value, err)
+	}
+	// Iteration across pages, with a prefix and a start
+	for _, tt := range []struct {
+		prefix, start string
+		count         int
+	}{
+		{"a-", "", 2500},
+		{"a-", "01000", 1500},
+		{"b-", "", 1},
+		{"", "", 2501},
+		{"c-", "", 0},
+	} {
+		var (
+			local  = db.NewIterator([]byte(tt.prefix), []byte(tt.start))
+			it     = remote.NewIterator([]byte(tt.prefix), []byte(tt.start))
+			number int
+		)
+		for it.Next() {
+			if !local.Next() {
+				t.Fatalf("prefix %q start %q: remote iterator longer than local", tt.prefix, tt.start)
+			}
+			if !bytes.Equal(it.Key(), local.Key()) || !bytes.Equal(it.Value(), local.Value()) {
+				t.Fatalf("prefix %q start %q: entry %d mismatch: have %q, want %q", tt.prefix, tt.start, number, it.Key(), local.Key())
+			}
+			number++
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("prefix %q start %q: iteration failed: %v", tt.prefix, tt.start, err)
+		}
+		if local.Next() {
+			t.Fatalf("prefix %q start %q: remote iterator shorter than local", tt.prefix, tt.start)
+		}
+		it.Release()
+		local.Release()
+
+		if number < tt.count {
+			t.Fatalf("prefix %q start %q: entry count mismatch: have %d, want %d", tt.prefix, tt.start, number, tt.count)
+		}
+	}
+	// Ancient reads
+	if frozen, err := remote.Ancients(); err != nil || frozen != 8 {
+		t.Fatalf("ancients mismatch: have %d, %v", frozen, err)
+	}
+	if tail, err := remote.Tail(); err != nil || tail != 0 {
+		t.Fatalf("tail mismatch: have %d, %v", tail, err)
+	}
+	blobs, err := remote.AncientRange(rawdb.ChainFreezerHashTable, 2, 4, 0)
+	if err != nil || len(blobs) != 4 {
+		t.Fatalf("ancient range mismatch: have %d items, %v", len(blobs), err)
+	}
+	for i, blob := range blobs {
+		if !bytes.Equal(blob, blocks[2+i].Hash().Bytes()) {
+			t.Fatalf("ancient hash %d mismatch: have %x, want %x", 2+i, blob, blocks[2+i].Hash())
+		}
+	}
+	if size, err := remote.AncientSize(rawdb.ChainFreezerHashTable); err != nil || size == 0 {
+		t.Fatalf("ancient size mismatch: have %d, %v", size, err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RPCTracer instruments the JSON-RPC server, creating a server span for every
+// served method call.
+type RPCTracer struct{}
+
+// ExtractHTTP implements rpc.Tracer, continuing the trace of callers sending W3C
+// trace context headers.
+func (RPCTracer) ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return Extract(ctx, header)
+}
+
+// StartCall implements rpc.Tracer, recording the error code of failed calls.
+func (RPCTracer) StartCall(ctx context.Context, method string) (context.Context, func(error)) {
+	ctx, span := StartServerSpan(ctx, method,
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", method),
+	)
+	return ctx, func(err error) {
+		if ec, ok := err.(rpc.Error); ok {
+			span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", ec.ErrorCode()))
+		}
+		EndSpan(span, err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testService struct{}
+
+func (testService) Echo(s string) string { return s }
+func (testService) Fail() error          { return errors.New("failed") }
+
+// Tests that the RPC tracer creates a span for every served call, continuing
+// the trace of callers sending W3C trace context headers.
+func TestRPCTracer(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	s := rpc.NewServer()
+	defer s.Stop()
+	s.SetTracer(RPCTracer{})
+	if err := s.RegisterName("test", testService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := rpc.Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetHeader("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if err := c.Call(nil, "test_echo", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call(nil, "test_fail"); err == nil {
+		t.Fatal("expected call to fail")
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("wrong number of spans: have %d, want 2", len(spans))
+	}
+	if spans[0].Name() != "test_echo" || spans[1].Name() != "test_fail" {
+		t.Errorf("wrong span names %q, %q", spans[0].Name(), spans[1].Name())
+	}
+	for _, span := range spans {
+		if have := span.Parent().TraceID().String(); have != "0af7651916cd43dd8448eb211c80319c" {
+			t.Errorf("span %s: remote parent not honored: trace id %s", span.Name(), have)
+		}
+	}
+	var code attribute.Value
+	for _, attr := range spans[1].Attributes() {
+		if attr.Key == "rpc.jsonrpc.error_code" {
+			code = attr.Value
+		}
+	}
+	if code.AsInt64() != -32000 {
+		t.Errorf("wrong error code of failed call: %v", code.Emit())
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package telemetry implements optional OpenTelemetry trace export and the span
+// helpers used to instrument the Engine API, block building, block import and
+// the JSON-RPC server.
+//
+// Until Setup is called, all spans are created by the no-op global tracer
+// provider and cost next to nothing.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer all spans are created with.
+const instrumentationName = "github.com/ethereum/go-ethereum"
+
+// Supported OTLP transport protocols.
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Config contains the settings of the OTLP trace exporter.
+type Config struct {
+	Endpoint    string  // Collector address (host:port), or a URL for the HTTP protocol
+	Protocol    string  // Transport protocol of the collector, "http" or "grpc"
+	Insecure    bool    // Whether to connect to the collector without TLS, implied for loopback addresses
+	SampleRatio float64 // Fraction of root traces to sample, remote parents decide on their own
+
+	ServiceName    string // Service name reported to the collector
+	ServiceVersion string // Service version reported to the collector
+}
+
+// DefaultConfig is the default telemetry configuration, exporting to a collector
+// running locally.
+var DefaultConfig = Config{
+	Endpoint:    "localhost:4318",
+	Protocol:    ProtocolHTTP,
+	SampleRatio: 1,
+	ServiceName: "geth",
+}
+
+// Setup configures the global tracer provider to export spans to an OTLP
+// collector and installs the W3C trace context propagator. The returned function
+// flushes pending spans and shuts the exporter down.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch config.Protocol {
+	case ProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpointURL(config))}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ProtocolGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+		if config.insecure() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.ServiceVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// endpointURL converts the configured HTTP endpoint into a URL, defaulting the
+// scheme according to the TLS setting if a bare host:port was given.
+func endpointURL(config Config) string {
+	if strings.HasPrefix(config.Endpoint, "http://") || strings.HasPrefix(config.Endpoint, "https://") {
+		return config.Endpoint
+	}
+	if config.insecure() {
+		return "http://" + config.Endpoint
+	}
+	return "https://" + config.Endpoint
+}
+
+// insecure reports whether to connect to the collector without TLS. Collectors
+// on loopback addresses, like the default one, are not expected to serve TLS.
+func (c *Config) insecure() bool {
+	if c.Insecure {
+		return true
+	}
+	host, _, err := net.SplitHostPort(c.Endpoint)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Tracer returns the tracer used to instrument geth.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts an internal span as a child of the span in ctx, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServerSpan starts a span for serving a remote request. The parent span is
+// taken from ctx, where it is placed by Extract for remote callers.
+func StartServerSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// EndSpan ends a span, marking it as failed if err is non-nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns a copy of ctx carrying the remote span context found in the
+// W3C trace context headers (traceparent, tracestate) of an HTTP request.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Validate checks the configuration for errors.
+func (c *Config) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return errors.New("sample ratio must be within [0, 1]")
+	}
+	if c.Protocol != ProtocolHTTP && c.Protocol != ProtocolGRPC {
+		return fmt.Errorf("unsupported OTLP protocol %q", c.Protocol)
+	}
+	return nil
+}
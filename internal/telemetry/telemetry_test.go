@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Tests that spans are exported to an OTLP/HTTP collector, and that remote
+// parents passed in W3C trace context headers are honored.
+func TestExportHTTP(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		spans = make(map[string]string) // span name -> trace id
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected export path %q", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid export request: %v", err)
+		}
+		lock.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spans[span.Name] = string(span.TraceId)
+				}
+			}
+		}
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(nil)
+	}))
+	defer collector.Close()
+
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+
+	config := DefaultConfig
+	config.Endpoint = collector.URL
+	shutdown, err := Setup(context.Background(), config)
+	if err != nil {
+		t.Fatalf("failed to set up telemetry: %v", err)
+	}
+	// Continue a remote trace and create a child span in it
+	header := http.Header{}
+	header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	ctx, parent := StartServerSpan(Extract(context.Background(), header), "engine_newPayloadV3")
+	_, child := StartSpan(ctx, "BlockChain.insertChain")
+	EndSpan(child, errors.New("bad block"))
+	EndSpan(parent, nil)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to flush spans: %v", err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	want := string([]byte{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c})
+	for _, name := range []string{"engine_newPayloadV3", "BlockChain.insertChain"} {
+		id, ok := spans[name]
+		if !ok {
+			t.Errorf("span %q not exported", name)
+			continue
+		}
+		if id != want {
+			t.Errorf("span %q: trace id mismatch: have %x, want %x", name, id, want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for i, config := range []Config{
+		{Protocol: "udp"},
+		{Protocol: ProtocolGRPC, SampleRatio: 1.5},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("config %d: expected validation failure", i)
+		}
+	}
+	if err := DefaultConfig.Validate(); err != nil {
+		t.Errorf("default config invalid: %v", err)
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		config Config
+		want   string
+	}{
+		{DefaultConfig, "http://localhost:4318"},
+		{Config{Endpoint: "127.0.0.1:4318"}, "http://127.0.0.1:4318"},
+		{Config{Endpoint: "[::1]:4318"}, "http://[::1]:4318"},
+		{Config{Endpoint: "collector:4318"}, "https://collector:4318"},
+		{Config{Endpoint: "collector:4318", Insecure: true}, "http://collector:4318"},
+		{Config{Endpoint: "https://localhost:4318/v1/traces"}, "https://localhost:4318/v1/traces"},
+	}
+	for i, tt := range tests {
+		if have := endpointURL(tt.config); have != tt.want {
+			t.Errorf("test %d: endpoint mismatch: have %s, want %s", i, have, tt.want)
+		}
+	}
+}
@@ -153,6 +153,27 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'exportTxPool',
+			call: 'admin_exportTxPool',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'importTxPool',
+			call: 'admin_importTxPool',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'exportTxPoolFile',
+			call: 'admin_exportTxPoolFile',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importTxPoolFile',
+			call: 'admin_importTxPoolFile',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -490,6 +511,42 @@ web3._extend({
 			call: 'debug_dbAncients',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'dbGetMany',
+			call: 'debug_dbGetMany',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'dbRange',
+			call: 'debug_dbRange',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'dbAncientRange',
+			call: 'debug_dbAncientRange',
+			params: 4
+		}),
+		new web3._extend.Method({
+			name: 'dbAncientSize',
+			call: 'debug_dbAncientSize',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'dbTail',
+			call: 'debug_dbTail',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'freezerStats',
+			call: 'debug_freezerStats',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'snapshotAccount',
+			call: 'debug_snapshotAccount',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'setTrieFlushInterval',
 			call: 'debug_setTrieFlushInterval',
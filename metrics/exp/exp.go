@@ -185,6 +185,11 @@ func (exp *exp) publishResettingTimer(name string, metric metrics.ResettingTimer
 	exp.getFloat(name + ".99-percentile").Set(ps[3])
 }
 
+func (exp *exp) publishNativeHistogram(name string, metric metrics.NativeHistogramSnapshot) {
+	exp.getInt(name + ".count").Set(int64(metric.Count()))
+	exp.getFloat(name + ".sum").Set(metric.Sum())
+}
+
 func (exp *exp) syncToExpvar() {
 	exp.registry.Each(func(name string, i interface{}) {
 		switch i := i.(type) {
@@ -206,6 +211,10 @@ func (exp *exp) syncToExpvar() {
 			exp.publishTimer(name, i)
 		case metrics.ResettingTimer:
 			exp.publishResettingTimer(name, i)
+		case metrics.NativeHistogram:
+			exp.publishNativeHistogram(name, i.Snapshot())
+		case metrics.Family:
+			// Labeled families are only exported to Prometheus
 		default:
 			panic(fmt.Sprintf("unsupported type for '%s': %T", name, i))
 		}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Family is a collection of metrics of the same type, distinguished by the
+// values of a fixed set of labels, e.g. the serving times of the RPC methods.
+// Exporters without label support skip families.
+type Family interface {
+	LabelNames() []string
+	Each(func(values []string, metric interface{}))
+}
+
+// GetOrRegisterFamily returns an existing Family or constructs and registers a
+// new StandardFamily creating its members with newMetric.
+func GetOrRegisterFamily[T any](name string, r Registry, labels []string, newMetric func() T) *StandardFamily[T] {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, func() Family { return NewFamily(labels, newMetric) }).(*StandardFamily[T])
+}
+
+// NewFamily constructs a new StandardFamily with the given label names, creating
+// its members with newMetric.
+func NewFamily[T any](labels []string, newMetric func() T) *StandardFamily[T] {
+	return &StandardFamily[T]{
+		labels:    labels,
+		newMetric: newMetric,
+		members:   make(map[string]*familyMember[T]),
+	}
+}
+
+// NewRegisteredFamily constructs and registers a new StandardFamily.
+func NewRegisteredFamily[T any](name string, r Registry, labels []string, newMetric func() T) *StandardFamily[T] {
+	f := NewFamily(labels, newMetric)
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, f)
+	return f
+}
+
+// StandardFamily is the standard implementation of a Family, holding members
+// of type T.
+type StandardFamily[T any] struct {
+	labels    []string
+	newMetric func() T
+	members   map[string]*familyMember[T]
+	lock      sync.RWMutex
+}
+
+type familyMember[T any] struct {
+	values []string
+	metric T
+}
+
+// LabelNames returns the names of the labels distinguishing the members.
+func (f *StandardFamily[T]) LabelNames() []string {
+	return f.labels
+}
+
+// With returns the member with the given label values, creating it if needed.
+// The values must be given in the order of the label names.
+func (f *StandardFamily[T]) With(values ...string) T {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metric family with labels %v got %d values", f.labels, len(values)))
+	}
+	// Don't retain members if metrics are disabled, they are no-ops anyway
+	if !Enabled {
+		return f.newMetric()
+	}
+	key := strings.Join(values, "\xff")
+
+	f.lock.RLock()
+	member, ok := f.members[key]
+	f.lock.RUnlock()
+	if ok {
+		return member.metric
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if member, ok := f.members[key]; ok {
+		return member.metric
+	}
+	member = &familyMember[T]{values: append([]string(nil), values...), metric: f.newMetric()}
+	f.members[key] = member
+	return member.metric
+}
+
+// Each calls fn for every member, in the order of their label values.
+func (f *StandardFamily[T]) Each(fn func(values []string, metric interface{})) {
+	f.lock.RLock()
+	members := make([]*familyMember[T], 0, len(f.members))
+	for _, member := range f.members {
+		members = append(members, member)
+	}
+	f.lock.RUnlock()
+
+	sort.Slice(members, func(i, j int) bool {
+		return strings.Join(members[i].values, "\xff") < strings.Join(members[j].values, "\xff")
+	})
+	for _, member := range members {
+		fn(member.values, member.metric)
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFamily(t *testing.T) {
+	f := NewFamily([]string{"method", "status"}, NewCounter)
+	f.With("eth_call", "success").Inc(2)
+	f.With("eth_call", "success").Inc(3)
+	f.With("eth_call", "failure").Inc(1)
+	f.With("debug_traceCall", "success").Inc(7)
+
+	var (
+		values [][]string
+		counts []int64
+	)
+	f.Each(func(v []string, metric interface{}) {
+		values = append(values, v)
+		counts = append(counts, metric.(Counter).Snapshot().Count())
+	})
+	want := [][]string{{"debug_traceCall", "success"}, {"eth_call", "failure"}, {"eth_call", "success"}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("members mismatch: have %v, want %v", values, want)
+	}
+	if !reflect.DeepEqual(counts, []int64{7, 1, 5}) {
+		t.Errorf("counts mismatch: have %v", counts)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic on label count mismatch")
+		}
+	}()
+	f.With("eth_call")
+}
+
+func TestGetOrRegisterFamily(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredFamily("foo", r, []string{"peer"}, NewGauge).With("a").Update(47)
+	f := GetOrRegisterFamily("foo", r, []string{"peer"}, NewGauge)
+	if v := f.With("a").Snapshot().Value(); v != 47 {
+		t.Fatalf("registered family mismatch: have %d, want 47", v)
+	}
+	if _, ok := r.Get("foo").(Family); !ok {
+		t.Fatalf("family not registered")
+	}
+}
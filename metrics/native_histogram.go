@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultNativeHistogramSchema is the default resolution of native histograms,
+	// with bucket boundaries growing by a factor of 2^(2^-3), roughly 9%.
+	DefaultNativeHistogramSchema = 3
+
+	// nativeHistogramZeroThreshold is the width of the zero bucket of native
+	// histograms, matching the Prometheus client default.
+	nativeHistogramZeroThreshold = 2.938735877055719e-39 // 2^-128
+)
+
+// NativeBucket is a populated bucket of a native histogram. The bucket with
+// index i counts the observations in (base^(i-1), base^i] for positive and
+// [-base^i, -base^(i-1)) for negative values, where base = 2^(2^-schema).
+type NativeBucket struct {
+	Index int32
+	Count uint64
+}
+
+// NativeHistogramSnapshot is a read-only copy of a NativeHistogram.
+type NativeHistogramSnapshot interface {
+	Count() uint64
+	Sum() float64
+	Schema() int32
+	ZeroThreshold() float64
+	ZeroCount() uint64
+	PositiveBuckets() []NativeBucket
+	NegativeBuckets() []NativeBucket
+}
+
+// NativeHistogram tracks the distribution of float64 values in sparse buckets
+// of exponentially growing width, as defined by Prometheus native histograms.
+// Unlike Histogram it does not sample, retaining the exact bucket counts.
+type NativeHistogram interface {
+	Observe(float64)
+	Snapshot() NativeHistogramSnapshot
+}
+
+// GetOrRegisterNativeHistogram returns an existing NativeHistogram or constructs
+// and registers a new StandardNativeHistogram.
+func GetOrRegisterNativeHistogram(name string, r Registry, schema int32) NativeHistogram {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, func() NativeHistogram { return NewNativeHistogram(schema) }).(NativeHistogram)
+}
+
+// NewNativeHistogram constructs a new StandardNativeHistogram with the given
+// schema, clamped to the [-4, 8] range supported by Prometheus.
+func NewNativeHistogram(schema int32) NativeHistogram {
+	if !Enabled {
+		return NilNativeHistogram{}
+	}
+	if schema < -4 {
+		schema = -4
+	}
+	if schema > 8 {
+		schema = 8
+	}
+	return &StandardNativeHistogram{
+		schema:   schema,
+		positive: make(map[int32]uint64),
+		negative: make(map[int32]uint64),
+	}
+}
+
+// NewRegisteredNativeHistogram constructs and registers a new StandardNativeHistogram.
+func NewRegisteredNativeHistogram(name string, r Registry, schema int32) NativeHistogram {
+	c := NewNativeHistogram(schema)
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// NilNativeHistogram is a no-op NativeHistogram.
+type NilNativeHistogram struct{}
+
+func (NilNativeHistogram) Observe(v float64) {}
+func (NilNativeHistogram) Snapshot() NativeHistogramSnapshot {
+	return &nativeHistogramSnapshot{schema: DefaultNativeHistogramSchema, zeroThreshold: nativeHistogramZeroThreshold}
+}
+
+// StandardNativeHistogram is the standard implementation of a NativeHistogram.
+type StandardNativeHistogram struct {
+	schema    int32
+	count     uint64
+	sum       float64
+	zeroCount uint64
+	positive  map[int32]uint64
+	negative  map[int32]uint64
+	mutex     sync.Mutex
+}
+
+// Observe adds a value to the histogram. NaN values are dropped.
+func (h *StandardNativeHistogram) Observe(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.count++
+	h.sum += v
+	switch {
+	case math.Abs(v) <= nativeHistogramZeroThreshold:
+		h.zeroCount++
+	case v > 0:
+		h.positive[nativeBucketIndex(v, h.schema)]++
+	default:
+		h.negative[nativeBucketIndex(-v, h.schema)]++
+	}
+}
+
+// Snapshot returns a read-only copy of the histogram.
+func (h *StandardNativeHistogram) Snapshot() NativeHistogramSnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return &nativeHistogramSnapshot{
+		count:         h.count,
+		sum:           h.sum,
+		schema:        h.schema,
+		zeroThreshold: nativeHistogramZeroThreshold,
+		zeroCount:     h.zeroCount,
+		positive:      sortedNativeBuckets(h.positive),
+		negative:      sortedNativeBuckets(h.negative),
+	}
+}
+
+// nativeBucketIndex returns the index of the bucket the positive value v falls
+// into, i.e. ceil(log2(v) * 2^schema).
+func nativeBucketIndex(v float64, schema int32) int32 {
+	// Split v into frac * 2^exp with frac in [0.5, 1), to keep the boundaries
+	// at powers of two exact.
+	frac, exp := math.Frexp(v)
+	if schema > 0 {
+		return int32(exp)<<schema + int32(math.Ceil(math.Log2(frac)*float64(int32(1)<<schema)))
+	}
+	// With schema <= 0 the boundaries are powers of two, the bucket is that
+	// of ceil(log2(v)) divided by 2^-schema, rounding up.
+	if frac == 0.5 {
+		exp--
+	}
+	return (int32(exp) + int32(1)<<-schema - 1) >> -schema
+}
+
+func sortedNativeBuckets(buckets map[int32]uint64) []NativeBucket {
+	sorted := make([]NativeBucket, 0, len(buckets))
+	for index, count := range buckets {
+		sorted = append(sorted, NativeBucket{Index: index, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	return sorted
+}
+
+// NativeBucketUpperBound returns the upper bound of the positive bucket with the
+// given index at the given schema.
+func NativeBucketUpperBound(index int32, schema int32) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(schema)))
+}
+
+// nativeHistogramSnapshot is a read-only copy of a StandardNativeHistogram.
+type nativeHistogramSnapshot struct {
+	count         uint64
+	sum           float64
+	schema        int32
+	zeroThreshold float64
+	zeroCount     uint64
+	positive      []NativeBucket
+	negative      []NativeBucket
+}
+
+func (s *nativeHistogramSnapshot) Count() uint64                   { return s.count }
+func (s *nativeHistogramSnapshot) Sum() float64                    { return s.sum }
+func (s *nativeHistogramSnapshot) Schema() int32                   { return s.schema }
+func (s *nativeHistogramSnapshot) ZeroThreshold() float64          { return s.zeroThreshold }
+func (s *nativeHistogramSnapshot) ZeroCount() uint64               { return s.zeroCount }
+func (s *nativeHistogramSnapshot) PositiveBuckets() []NativeBucket { return s.positive }
+func (s *nativeHistogramSnapshot) NegativeBuckets() []NativeBucket { return s.negative }
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNativeBucketIndex(t *testing.T) {
+	tests := []struct {
+		value  float64
+		schema int32
+		index  int32
+	}{
+		// Boundaries at powers of two are inclusive upper bounds
+		{1, 0, 0}, {1.5, 0, 1}, {2, 0, 1}, {2.1, 0, 2}, {0.5, 0, -1}, {0.3, 0, -1},
+		{1, 3, 0}, {2, 3, 8}, {4, 3, 16}, {0.5, 3, -8}, {1.05, 3, 1}, {1.1, 3, 2},
+		{1, -1, 0}, {2, -1, 1}, {3, -1, 1}, {4, -1, 1}, {5, -1, 2}, {0.5, -1, 0}, {0.25, -1, -1},
+	}
+	for _, tt := range tests {
+		if index := nativeBucketIndex(tt.value, tt.schema); index != tt.index {
+			t.Errorf("value %v, schema %d: index mismatch: have %d, want %d", tt.value, tt.schema, index, tt.index)
+		}
+		// The value must fall within the bounds of its bucket
+		upper := NativeBucketUpperBound(tt.index, tt.schema)
+		lower := NativeBucketUpperBound(tt.index-1, tt.schema)
+		if tt.value > upper*(1+1e-12) || tt.value <= lower {
+			t.Errorf("value %v, schema %d: outside bucket (%v, %v]", tt.value, tt.schema, lower, upper)
+		}
+	}
+}
+
+func TestNativeHistogram(t *testing.T) {
+	h := NewNativeHistogram(0)
+	for _, v := range []float64{0, 1, 1, 3, 4, 100, -2, math.NaN()} {
+		h.Observe(v)
+	}
+	snapshot := h.Snapshot()
+	h.Observe(1) // snapshots are not affected by later observations
+
+	if snapshot.Count() != 7 || snapshot.Sum() != 107 || snapshot.ZeroCount() != 1 || snapshot.Schema() != 0 {
+		t.Fatalf("snapshot mismatch: count %d, sum %v, zero count %d, schema %d", snapshot.Count(), snapshot.Sum(), snapshot.ZeroCount(), snapshot.Schema())
+	}
+	if have, want := snapshot.PositiveBuckets(), []NativeBucket{{0, 2}, {2, 2}, {7, 1}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("positive buckets mismatch: have %v, want %v", have, want)
+	}
+	if have, want := snapshot.NegativeBuckets(), []NativeBucket{{1, 1}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("negative buckets mismatch: have %v, want %v", have, want)
+	}
+	if h.Snapshot().Count() != 8 {
+		t.Errorf("observation after snapshot lost")
+	}
+	if schema := NewNativeHistogram(20).Snapshot().Schema(); schema != 8 {
+		t.Errorf("schema not clamped: %d", schema)
+	}
+}
+
+func TestGetOrRegisterNativeHistogram(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredNativeHistogram("foo", r, 3).Observe(47)
+	if h := GetOrRegisterNativeHistogram("foo", r, 3).Snapshot(); h.Count() != 1 || h.Sum() != 47 {
+		t.Fatalf("registered histogram mismatch: count %d, sum %v", h.Count(), h.Sum())
+	}
+}
@@ -30,6 +30,7 @@ var (
 	typeGaugeTpl           = "# TYPE %s gauge\n"
 	typeCounterTpl         = "# TYPE %s counter\n"
 	typeSummaryTpl         = "# TYPE %s summary\n"
+	typeHistogramTpl       = "# TYPE %s histogram\n"
 	keyValueTpl            = "%s %v\n\n"
 	keyQuantileTagValueTpl = "%s {quantile=\"%s\"} %v\n"
 )
@@ -69,6 +70,10 @@ func (c *collector) Add(name string, i any) error {
 		c.addTimer(name, m.Snapshot())
 	case metrics.ResettingTimer:
 		c.addResettingTimer(name, m.Snapshot())
+	case metrics.NativeHistogram:
+		c.addNativeHistogram(name, m.Snapshot())
+	case metrics.Family:
+		c.addFamily(name, m)
 	default:
 		return fmt.Errorf("unknown prometheus metric type %T", i)
 	}
@@ -135,6 +140,121 @@ func (c *collector) addResettingTimer(name string, m metrics.ResettingTimerSnaps
 	c.buff.WriteRune('\n')
 }
 
+func (c *collector) addNativeHistogram(name string, m metrics.NativeHistogramSnapshot) {
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeHistogramTpl, name))
+	c.writeHistogramBuckets(name, "", m)
+	c.buff.WriteRune('\n')
+}
+
+// addFamily adds the members of a labeled metric family. Counters, gauges and
+// meters are exported as gauges like their unlabeled counterparts, sampled
+// histograms and timers as summaries and native histograms as histograms with
+// their sparse buckets.
+func (c *collector) addFamily(name string, f metrics.Family) {
+	name = mutateKey(name)
+
+	var typ string
+	f.Each(func(values []string, i interface{}) {
+		if typ == "" {
+			if typ = labeledType(i); typ == "" {
+				return
+			}
+			c.buff.WriteString(fmt.Sprintf("# TYPE %s %s\n", name, typ))
+		}
+		if labeledType(i) != typ {
+			return
+		}
+		labels := formatLabels(f.LabelNames(), values)
+		switch m := i.(type) {
+		case metrics.Counter:
+			c.writeLabeledValue(name, labels, m.Snapshot().Count())
+		case metrics.CounterFloat64:
+			c.writeLabeledValue(name, labels, m.Snapshot().Count())
+		case metrics.Gauge:
+			c.writeLabeledValue(name, labels, m.Snapshot().Value())
+		case metrics.GaugeFloat64:
+			c.writeLabeledValue(name, labels, m.Snapshot().Value())
+		case metrics.Meter:
+			c.writeLabeledValue(name, labels, m.Snapshot().Count())
+		case metrics.Histogram:
+			ms := m.Snapshot()
+			c.writeLabeledSummary(name, labels, ms.Count(), ms.Sum(), ms.Percentiles)
+		case metrics.Timer:
+			ms := m.Snapshot()
+			c.writeLabeledSummary(name, labels, ms.Count(), ms.Sum(), ms.Percentiles)
+		case metrics.ResettingTimer:
+			ms := m.Snapshot()
+			c.writeLabeledSummary(name, labels, ms.Count(), ms.Mean()*float64(ms.Count()), ms.Percentiles)
+		case metrics.NativeHistogram:
+			c.writeHistogramBuckets(name, labels, m.Snapshot())
+		}
+	})
+	if typ != "" {
+		c.buff.WriteRune('\n')
+	}
+}
+
+// labeledType returns the Prometheus type labeled members of the given metric
+// are exported as, or an empty string if unsupported.
+func labeledType(i interface{}) string {
+	switch i.(type) {
+	case metrics.Counter, metrics.CounterFloat64, metrics.Gauge, metrics.GaugeFloat64, metrics.Meter:
+		return "gauge"
+	case metrics.Histogram, metrics.Timer, metrics.ResettingTimer:
+		return "summary"
+	case metrics.NativeHistogram:
+		return "histogram"
+	default:
+		return ""
+	}
+}
+
+func (c *collector) writeLabeledValue(name, labels string, value interface{}) {
+	c.buff.WriteString(fmt.Sprintf("%s{%s} %v\n", name, labels, value))
+}
+
+func (c *collector) writeLabeledSummary(name, labels string, count interface{}, sum interface{}, percentiles func([]float64) []float64) {
+	pv := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+	ps := percentiles(pv)
+	for i := range pv {
+		c.buff.WriteString(fmt.Sprintf("%s{%s,quantile=\"%s\"} %v\n", name, labels, strconv.FormatFloat(pv[i], 'f', -1, 64), ps[i]))
+	}
+	c.buff.WriteString(fmt.Sprintf("%s_sum{%s} %v\n", name, labels, sum))
+	c.buff.WriteString(fmt.Sprintf("%s_count{%s} %v\n", name, labels, count))
+}
+
+// writeHistogramBuckets writes the populated buckets of a native histogram as
+// classic cumulative buckets, as the text format cannot carry native ones.
+func (c *collector) writeHistogramBuckets(name, labels string, m metrics.NativeHistogramSnapshot) {
+	if labels != "" {
+		labels += ","
+	}
+	var cumulative uint64
+	bucket := func(le string, count uint64) {
+		cumulative += count
+		c.buff.WriteString(fmt.Sprintf("%s_bucket{%sle=\"%s\"} %d\n", name, labels, le, cumulative))
+	}
+	negative := m.NegativeBuckets()
+	for i := len(negative) - 1; i >= 0; i-- {
+		bucket(formatBound(-metrics.NativeBucketUpperBound(negative[i].Index-1, m.Schema())), negative[i].Count)
+	}
+	if m.ZeroCount() > 0 {
+		bucket(formatBound(m.ZeroThreshold()), m.ZeroCount())
+	}
+	for _, b := range m.PositiveBuckets() {
+		bucket(formatBound(metrics.NativeBucketUpperBound(b.Index, m.Schema())), b.Count)
+	}
+	c.buff.WriteString(fmt.Sprintf("%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, m.Count()))
+
+	labels = strings.TrimSuffix(labels, ",")
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	c.buff.WriteString(fmt.Sprintf("%s_sum%s %v\n", name, labels, m.Sum()))
+	c.buff.WriteString(fmt.Sprintf("%s_count%s %d\n", name, labels, m.Count()))
+}
+
 func (c *collector) writeGaugeInfo(name string, value metrics.GaugeInfoValue) {
 	name = mutateKey(name)
 	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
@@ -168,3 +288,19 @@ func (c *collector) writeSummaryPercentile(name, p string, value interface{}) {
 func mutateKey(key string) string {
 	return strings.ReplaceAll(key, "/", "_")
 }
+
+// labelValueEscaper escapes label values as required by the text format.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats the label pairs of a family member.
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i := range names {
+		pairs[i] = fmt.Sprintf("%s=\"%s\"", names[i], labelValueEscaper.Replace(values[i]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
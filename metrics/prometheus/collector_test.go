@@ -63,3 +63,81 @@ func findFirstDiffPos(a, b string) string {
 	}
 	return ""
 }
+
+// labeledMetrics returns a registry populated with labeled metric families and
+// native histograms.
+func labeledMetrics() metrics.Registry {
+	registry := metrics.NewOrderedRegistry()
+
+	gauges := metrics.NewRegisteredFamily("test/peers", registry, []string{"protocol"}, metrics.NewGauge)
+	gauges.With("eth").Update(5)
+	gauges.With(`sn"ap`).Update(2)
+
+	timers := metrics.NewRegisteredFamily("test/method_timer", registry, []string{"method"}, metrics.NewTimer)
+	timers.With("eth_call").Update(20)
+	timers.With("eth_call").Update(40)
+
+	durations := metrics.NewRegisteredFamily("test/duration_seconds", registry, []string{"method", "status"}, func() metrics.NativeHistogram {
+		return metrics.NewNativeHistogram(0)
+	})
+	for _, v := range []float64{0.1, 0.2, 3, 3, 100} {
+		durations.With("eth_call", "success").Observe(v)
+	}
+	durations.With("eth_call", "failure")
+
+	native := metrics.NewRegisteredNativeHistogram("test/native", registry, 0)
+	for _, v := range []float64{-3, 0, 1, 2} {
+		native.Observe(v)
+	}
+	return registry
+}
+
+func TestCollectorLabeled(t *testing.T) {
+	c := newCollector()
+	labeledMetrics().Each(func(name string, i interface{}) {
+		if err := c.Add(name, i); err != nil {
+			t.Fatal(err)
+		}
+	})
+	want := `# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="eth_call",status="failure",le="+Inf"} 0
+test_duration_seconds_sum{method="eth_call",status="failure"} 0
+test_duration_seconds_count{method="eth_call",status="failure"} 0
+test_duration_seconds_bucket{method="eth_call",status="success",le="0.125"} 1
+test_duration_seconds_bucket{method="eth_call",status="success",le="0.25"} 2
+test_duration_seconds_bucket{method="eth_call",status="success",le="4"} 4
+test_duration_seconds_bucket{method="eth_call",status="success",le="128"} 5
+test_duration_seconds_bucket{method="eth_call",status="success",le="+Inf"} 5
+test_duration_seconds_sum{method="eth_call",status="success"} 106.3
+test_duration_seconds_count{method="eth_call",status="success"} 5
+
+# TYPE test_method_timer summary
+test_method_timer{method="eth_call",quantile="0.5"} 30
+test_method_timer{method="eth_call",quantile="0.75"} 40
+test_method_timer{method="eth_call",quantile="0.95"} 40
+test_method_timer{method="eth_call",quantile="0.99"} 40
+test_method_timer{method="eth_call",quantile="0.999"} 40
+test_method_timer{method="eth_call",quantile="0.9999"} 40
+test_method_timer_sum{method="eth_call"} 60
+test_method_timer_count{method="eth_call"} 2
+
+# TYPE test_native histogram
+test_native_bucket{le="-2"} 1
+test_native_bucket{le="2.938735877055719e-39"} 2
+test_native_bucket{le="1"} 3
+test_native_bucket{le="2"} 4
+test_native_bucket{le="+Inf"} 4
+test_native_sum 0
+test_native_count 4
+
+# TYPE test_peers gauge
+test_peers{protocol="eth"} 5
+test_peers{protocol="sn\"ap"} 2
+
+`
+	if have := c.buff.String(); have != want {
+		t.Logf("have\n%v", have)
+		t.Logf("have vs want:\n%v", findFirstDiffPos(have, want))
+		t.Fatalf("unexpected collector output")
+	}
+}
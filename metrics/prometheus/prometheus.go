@@ -18,15 +18,19 @@
 package prometheus
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
 
-// Handler returns an HTTP handler which dump metrics in Prometheus format.
+// Handler returns an HTTP handler which dump metrics in Prometheus format. The
+// text format is served by default, the delimited protobuf format with native
+// histograms if the scraper accepts it.
 func Handler(reg metrics.Registry) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Gather and pre-sort the metrics to avoid random listings
@@ -37,16 +41,52 @@ func Handler(reg metrics.Registry) http.Handler {
 		sort.Strings(names)
 
 		// Aggregate all the metrics into a Prometheus collector
-		c := newCollector()
-
+		var (
+			c interface {
+				Add(name string, i any) error
+			}
+			buff        *bytes.Buffer
+			contentType string
+		)
+		if acceptsProtobuf(r.Header.Get("Accept")) {
+			pc := newProtoCollector()
+			c, buff, contentType = pc, pc.buff, protoContentType
+		} else {
+			tc := newCollector()
+			c, buff, contentType = tc, tc.buff, "text/plain"
+		}
 		for _, name := range names {
 			i := reg.Get(name)
 			if err := c.Add(name, i); err != nil {
 				log.Warn("Unknown Prometheus metric type", "type", fmt.Sprintf("%T", i))
 			}
 		}
-		w.Header().Add("Content-Type", "text/plain")
-		w.Header().Add("Content-Length", fmt.Sprint(c.buff.Len()))
-		w.Write(c.buff.Bytes())
+		w.Header().Add("Content-Type", contentType)
+		w.Header().Add("Content-Length", fmt.Sprint(buff.Len()))
+		w.Write(buff.Bytes())
 	})
 }
+
+// acceptsProtobuf reports whether the Accept header of a scrape request allows
+// the delimited protobuf exposition format.
+func acceptsProtobuf(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		if strings.TrimSpace(params[0]) != "application/vnd.google.protobuf" {
+			continue
+		}
+		var proto, encoding bool
+		for _, param := range params[1:] {
+			switch strings.ReplaceAll(strings.TrimSpace(param), " ", "") {
+			case "proto=io.prometheus.client.MetricFamily":
+				proto = true
+			case "encoding=delimited":
+				encoding = true
+			}
+		}
+		if proto && encoding {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoContentType is the content type of the delimited protobuf exposition
+// format, the only one able to carry native histograms.
+const protoContentType = "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited"
+
+// metricType is the io.prometheus.client.MetricType of a metric family.
+type metricType uint64
+
+const (
+	metricTypeGauge     metricType = 1
+	metricTypeSummary   metricType = 2
+	metricTypeHistogram metricType = 4
+)
+
+// Field numbers of the io.prometheus.client messages.
+const (
+	familyName   = 1
+	familyType   = 3
+	familyMetric = 4
+
+	metricLabel     = 1
+	metricGauge     = 2
+	metricSummary   = 4
+	metricHistogram = 7
+
+	labelName  = 1
+	labelValue = 2
+
+	gaugeValue = 1
+
+	summaryCount    = 1
+	summarySum      = 2
+	summaryQuantile = 3
+	quantileRank    = 1
+	quantileValue   = 2
+
+	histogramCount         = 1
+	histogramSum           = 2
+	histogramSchema        = 5
+	histogramZeroThreshold = 6
+	histogramZeroCount     = 7
+	histogramNegativeSpan  = 9
+	histogramNegativeDelta = 10
+	histogramPositiveSpan  = 12
+	histogramPositiveDelta = 13
+	spanOffset             = 1
+	spanLength             = 2
+)
+
+// protoCollector aggregates metrics into length delimited MetricFamily protobuf
+// messages. Unlabeled metrics keep the names and types of the text format, the
+// count of summaries being part of the summary itself.
+type protoCollector struct {
+	buff *bytes.Buffer
+}
+
+// newProtoCollector creates a new Prometheus protobuf metric aggregator.
+func newProtoCollector() *protoCollector {
+	return &protoCollector{
+		buff: &bytes.Buffer{},
+	}
+}
+
+// Add adds the metric i to the collector. This method returns an error if the
+// metric type is not supported/known.
+func (c *protoCollector) Add(name string, i any) error {
+	name = mutateKey(name)
+
+	if f, ok := i.(metrics.Family); ok {
+		var (
+			typ     metricType
+			members [][]byte
+		)
+		f.Each(func(values []string, i interface{}) {
+			var labels []byte
+			for j, name := range f.LabelNames() {
+				labels = appendLabel(labels, name, values[j])
+			}
+			mtyp, metric, ok := encodeMetric(labels, i)
+			if !ok || (len(members) > 0 && mtyp != typ) {
+				return
+			}
+			typ, members = mtyp, append(members, metric)
+		})
+		if len(members) > 0 {
+			c.writeFamily(name, typ, members...)
+		}
+		return nil
+	}
+	typ, metric, ok := encodeMetric(nil, i)
+	if !ok {
+		if _, ok := i.(metrics.ResettingTimer); ok {
+			return nil // empty timers are omitted, as in the text format
+		}
+		return fmt.Errorf("unknown prometheus metric type %T", i)
+	}
+	c.writeFamily(name, typ, metric)
+	return nil
+}
+
+// writeFamily writes a length delimited MetricFamily message.
+func (c *protoCollector) writeFamily(name string, typ metricType, metrics ...[]byte) {
+	var msg []byte
+	msg = protowire.AppendTag(msg, familyName, protowire.BytesType)
+	msg = protowire.AppendString(msg, name)
+	msg = protowire.AppendTag(msg, familyType, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(typ))
+	for _, metric := range metrics {
+		msg = protowire.AppendTag(msg, familyMetric, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, metric)
+	}
+	c.buff.Write(protowire.AppendVarint(nil, uint64(len(msg))))
+	c.buff.Write(msg)
+}
+
+// encodeMetric encodes the metric i as a Metric message with the given encoded
+// labels, returning false for unsupported or empty metrics.
+func encodeMetric(labels []byte, i interface{}) (metricType, []byte, bool) {
+	switch m := i.(type) {
+	case metrics.Counter:
+		return metricTypeGauge, appendGauge(labels, float64(m.Snapshot().Count())), true
+	case metrics.CounterFloat64:
+		return metricTypeGauge, appendGauge(labels, m.Snapshot().Count()), true
+	case metrics.Gauge:
+		return metricTypeGauge, appendGauge(labels, float64(m.Snapshot().Value())), true
+	case metrics.GaugeFloat64:
+		return metricTypeGauge, appendGauge(labels, m.Snapshot().Value()), true
+	case metrics.GaugeInfo:
+		value := m.Snapshot().Value()
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			labels = appendLabel(labels, k, value[k])
+		}
+		return metricTypeGauge, appendGauge(labels, 1), true
+	case metrics.Meter:
+		return metricTypeGauge, appendGauge(labels, float64(m.Snapshot().Count())), true
+	case metrics.Histogram:
+		ms := m.Snapshot()
+		return metricTypeSummary, appendSummary(labels, uint64(ms.Count()), float64(ms.Sum()), ms.Percentiles), true
+	case metrics.Timer:
+		ms := m.Snapshot()
+		return metricTypeSummary, appendSummary(labels, uint64(ms.Count()), float64(ms.Sum()), ms.Percentiles), true
+	case metrics.ResettingTimer:
+		ms := m.Snapshot()
+		if ms.Count() <= 0 {
+			return 0, nil, false
+		}
+		return metricTypeSummary, appendSummary(labels, uint64(ms.Count()), ms.Mean()*float64(ms.Count()), ms.Percentiles), true
+	case metrics.NativeHistogram:
+		return metricTypeHistogram, appendNativeHistogram(labels, m.Snapshot()), true
+	default:
+		return 0, nil, false
+	}
+}
+
+// appendLabel appends a LabelPair field to a Metric message.
+func appendLabel(b []byte, name, value string) []byte {
+	var pair []byte
+	pair = protowire.AppendTag(pair, labelName, protowire.BytesType)
+	pair = protowire.AppendString(pair, name)
+	pair = protowire.AppendTag(pair, labelValue, protowire.BytesType)
+	pair = protowire.AppendString(pair, value)
+
+	b = protowire.AppendTag(b, metricLabel, protowire.BytesType)
+	return protowire.AppendBytes(b, pair)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendUint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendGauge(labels []byte, v float64) []byte {
+	b := append([]byte(nil), labels...)
+	b = protowire.AppendTag(b, metricGauge, protowire.BytesType)
+	return protowire.AppendBytes(b, appendDouble(nil, gaugeValue, v))
+}
+
+func appendSummary(labels []byte, count uint64, sum float64, percentiles func([]float64) []float64) []byte {
+	var summary []byte
+	summary = appendUint(summary, summaryCount, count)
+	summary = appendDouble(summary, summarySum, sum)
+
+	pv := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+	for i, v := range percentiles(pv) {
+		var quantile []byte
+		quantile = appendDouble(quantile, quantileRank, pv[i])
+		quantile = appendDouble(quantile, quantileValue, v)
+
+		summary = protowire.AppendTag(summary, summaryQuantile, protowire.BytesType)
+		summary = protowire.AppendBytes(summary, quantile)
+	}
+	b := append([]byte(nil), labels...)
+	b = protowire.AppendTag(b, metricSummary, protowire.BytesType)
+	return protowire.AppendBytes(b, summary)
+}
+
+func appendNativeHistogram(labels []byte, m metrics.NativeHistogramSnapshot) []byte {
+	var hist []byte
+	hist = appendUint(hist, histogramCount, m.Count())
+	hist = appendDouble(hist, histogramSum, m.Sum())
+	hist = protowire.AppendTag(hist, histogramSchema, protowire.VarintType)
+	hist = protowire.AppendVarint(hist, protowire.EncodeZigZag(int64(m.Schema())))
+	hist = appendDouble(hist, histogramZeroThreshold, m.ZeroThreshold())
+	hist = appendUint(hist, histogramZeroCount, m.ZeroCount())
+
+	negative, positive := m.NegativeBuckets(), m.PositiveBuckets()
+	hist = appendBuckets(hist, histogramNegativeSpan, histogramNegativeDelta, negative)
+	if len(positive) == 0 && len(negative) == 0 {
+		// Mark empty histograms as native with a no-op span
+		hist = protowire.AppendTag(hist, histogramPositiveSpan, protowire.BytesType)
+		hist = protowire.AppendBytes(hist, appendUint(nil, spanLength, 0))
+	}
+	hist = appendBuckets(hist, histogramPositiveSpan, histogramPositiveDelta, positive)
+
+	b := append([]byte(nil), labels...)
+	b = protowire.AppendTag(b, metricHistogram, protowire.BytesType)
+	return protowire.AppendBytes(b, hist)
+}
+
+// appendBuckets appends the spans of consecutive populated buckets and the
+// deltas between their counts.
+func appendBuckets(b []byte, spanNum, deltaNum protowire.Number, buckets []metrics.NativeBucket) []byte {
+	if len(buckets) == 0 {
+		return b
+	}
+	var (
+		deltas []byte
+		prev   int64
+		start  int
+	)
+	appendSpan := func(offset int32, length int) {
+		var span []byte
+		span = protowire.AppendTag(span, spanOffset, protowire.VarintType)
+		span = protowire.AppendVarint(span, protowire.EncodeZigZag(int64(offset)))
+		span = appendUint(span, spanLength, uint64(length))
+
+		b = protowire.AppendTag(b, spanNum, protowire.BytesType)
+		b = protowire.AppendBytes(b, span)
+	}
+	offset := buckets[0].Index
+	for i, bucket := range buckets {
+		if i > 0 && bucket.Index != buckets[i-1].Index+1 {
+			appendSpan(offset, i-start)
+			offset, start = bucket.Index-buckets[i-1].Index-1, i
+		}
+		deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(int64(bucket.Count)-prev))
+		prev = int64(bucket.Count)
+	}
+	appendSpan(offset, len(buckets)-start)
+
+	b = protowire.AppendTag(b, deltaNum, protowire.BytesType)
+	return protowire.AppendBytes(b, deltas)
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics/internal"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoMessage is a decoded protobuf message, mapping field numbers to the raw
+// values of their occurrences.
+type protoMessage map[protowire.Number][]interface{}
+
+func decodeProto(t *testing.T, b []byte) protoMessage {
+	t.Helper()
+
+	msg := make(protoMessage)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			var bits uint64
+			bits, n = protowire.ConsumeFixed64(b)
+			v = math.Float64frombits(bits)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatalf("invalid field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		msg[num] = append(msg[num], v)
+	}
+	return msg
+}
+
+func (m protoMessage) message(t *testing.T, num protowire.Number) protoMessage {
+	t.Helper()
+	if len(m[num]) != 1 {
+		t.Fatalf("field %d occurs %d times", num, len(m[num]))
+	}
+	return decodeProto(t, m[num][0].([]byte))
+}
+
+// decodeFamilies decodes length delimited MetricFamily messages by name.
+func decodeFamilies(t *testing.T, b []byte) map[string]protoMessage {
+	families := make(map[string]protoMessage)
+	for len(b) > 0 {
+		size, n := protowire.ConsumeVarint(b)
+		if n < 0 || uint64(len(b[n:])) < size {
+			t.Fatalf("invalid delimiter")
+		}
+		family := decodeProto(t, b[n:n+int(size)])
+		families[string(family[familyName][0].([]byte))] = family
+		b = b[n+int(size):]
+	}
+	return families
+}
+
+func TestProtobufHandler(t *testing.T) {
+	registry := labeledMetrics()
+	internal.ExampleMetrics().Each(func(name string, i interface{}) {
+		registry.Register(name, i)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/debug/metrics/prometheus", nil)
+	req.Header.Set("Accept", "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.5,text/plain;version=0.0.4;q=0.3")
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, req)
+
+	if typ := rec.Header().Get("Content-Type"); typ != protoContentType {
+		t.Fatalf("content type mismatch: have %s", typ)
+	}
+	families := decodeFamilies(t, rec.Body.Bytes())
+
+	// Unlabeled metrics keep their names
+	for name, typ := range map[string]metricType{"test_counter": metricTypeGauge, "test_gauge_info": metricTypeGauge, "test_timer": metricTypeSummary, "test_native": metricTypeHistogram} {
+		family, ok := families[name]
+		if !ok {
+			t.Fatalf("family %s missing", name)
+		}
+		if have := metricType(family[familyType][0].(uint64)); have != typ {
+			t.Errorf("family %s type mismatch: have %d, want %d", name, have, typ)
+		}
+	}
+	if _, ok := families["test_empty_resetting_timer"]; ok {
+		t.Errorf("empty resetting timer exported")
+	}
+	if v := decodeProto(t, families["test_counter"][familyMetric][0].([]byte)).message(t, metricGauge)[gaugeValue][0]; v != 12345.0 {
+		t.Errorf("counter value mismatch: have %v", v)
+	}
+	summary := decodeProto(t, families["test_resetting_timer"][familyMetric][0].([]byte)).message(t, metricSummary)
+	if count := summary[summaryCount][0]; count != uint64(6) || len(summary[summaryQuantile]) != 6 {
+		t.Errorf("summary mismatch: count %v, %d quantiles", count, len(summary[summaryQuantile]))
+	}
+	// Labeled native histograms are exported with their sparse buckets
+	durations := families["test_duration_seconds"]
+	if typ := metricType(durations[familyType][0].(uint64)); typ != metricTypeHistogram || len(durations[familyMetric]) != 2 {
+		t.Fatalf("family mismatch: type %d, %d metrics", typ, len(durations[familyMetric]))
+	}
+	failure := decodeProto(t, durations[familyMetric][0].([]byte))
+	success := decodeProto(t, durations[familyMetric][1].([]byte))
+
+	var labels [][2]string
+	for _, pair := range success[metricLabel] {
+		label := decodeProto(t, pair.([]byte))
+		labels = append(labels, [2]string{string(label[labelName][0].([]byte)), string(label[labelValue][0].([]byte))})
+	}
+	if want := [][2]string{{"method", "eth_call"}, {"status", "success"}}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels mismatch: have %v, want %v", labels, want)
+	}
+	hist := success.message(t, metricHistogram)
+	if hist[histogramCount][0] != uint64(5) || hist[histogramSum][0] != 106.3 || hist[histogramSchema][0] != uint64(0) {
+		t.Errorf("histogram mismatch: count %v, sum %v, schema %v", hist[histogramCount], hist[histogramSum], hist[histogramSchema])
+	}
+	// Buckets -3, -2, 2 and 7 are populated with 1, 1, 2 and 1 observations
+	var spans [][2]int64
+	for _, raw := range hist[histogramPositiveSpan] {
+		span := decodeProto(t, raw.([]byte))
+		spans = append(spans, [2]int64{protowire.DecodeZigZag(span[spanOffset][0].(uint64)), int64(span[spanLength][0].(uint64))})
+	}
+	if want := [][2]int64{{-3, 2}, {3, 1}, {4, 1}}; !reflect.DeepEqual(spans, want) {
+		t.Errorf("spans mismatch: have %v, want %v", spans, want)
+	}
+	var deltas []int64
+	for packed := hist[histogramPositiveDelta][0].([]byte); len(packed) > 0; {
+		v, n := protowire.ConsumeVarint(packed)
+		deltas, packed = append(deltas, protowire.DecodeZigZag(v)), packed[n:]
+	}
+	if want := []int64{1, 0, 1, -1}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas mismatch: have %v, want %v", deltas, want)
+	}
+	// Empty histograms are marked native with a no-op span
+	if empty := failure.message(t, metricHistogram); len(empty[histogramPositiveSpan]) != 1 {
+		t.Errorf("empty histogram without no-op span")
+	}
+}
+
+func TestAcceptsProtobuf(t *testing.T) {
+	tests := map[string]bool{
+		"":                         false,
+		"text/plain;version=0.0.4": false,
+		"application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3":         true,
+		"application/openmetrics-text;version=1.0.0,application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited": true,
+		"application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=text":                                                   false,
+	}
+	for accept, want := range tests {
+		if have := acceptsProtobuf(accept); have != want {
+			t.Errorf("accept %q: have %v, want %v", accept, have, want)
+		}
+	}
+}
@@ -152,9 +152,11 @@ func (miner *Miner) SetGasTip(tip *big.Int) error {
 	return nil
 }
 
-// BuildPayload builds the payload according to the provided parameters.
-func (miner *Miner) BuildPayload(args *BuildPayloadArgs, witness bool) (*Payload, error) {
-	return miner.buildPayload(args, witness)
+// BuildPayload builds the payload according to the provided parameters. The
+// context only carries the tracing span the payload building belongs to, it
+// does not cancel the background building process.
+func (miner *Miner) BuildPayload(ctx context.Context, args *BuildPayloadArgs, witness bool) (*Payload, error) {
+	return miner.buildPayload(ctx, args, witness)
 }
 
 // getPending retrieves the pending block based on the current head block.
@@ -174,7 +176,7 @@ func (miner *Miner) getPending() *newPayloadResult {
 	if miner.chainConfig.IsShanghai(new(big.Int).Add(header.Number, big.NewInt(1)), timestamp) {
 		withdrawal = []*types.Withdrawal{}
 	}
-	ret := miner.generateWork(&generateParams{
+	ret := miner.generateWork(context.Background(), &generateParams{
 		timestamp:   timestamp,
 		forceTime:   false,
 		parentHash:  header.Hash(),
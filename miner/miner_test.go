@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"context"
 	"math/big"
 	"sync"
 	"testing"
@@ -195,7 +196,7 @@ func TestRejectedConditionalTx(t *testing.T) {
 	}
 
 	// request block
-	r := miner.generateWork(&generateParams{
+	r := miner.generateWork(context.Background(), &generateParams{
 		parentHash: miner.chain.CurrentBlock().Hash(),
 		timestamp:  timestamp,
 		random:     common.HexToHash("0xcafebabe"),
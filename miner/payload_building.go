@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
@@ -277,7 +278,7 @@ func (payload *Payload) stopBuilding() {
 }
 
 // buildPayload builds the payload according to the provided parameters.
-func (miner *Miner) buildPayload(args *BuildPayloadArgs, witness bool) (*Payload, error) {
+func (miner *Miner) buildPayload(ctx context.Context, args *BuildPayloadArgs, witness bool) (*Payload, error) {
 	if args.NoTxPool { // don't start the background payload updating job if there is no tx pool to pull from
 		// Build the initial version with no transaction included. It should be fast
 		// enough to run. The empty payload can at least make sure there is something
@@ -296,7 +297,7 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs, witness bool) (*Payload
 			gasLimit:      args.GasLimit,
 			eip1559Params: args.EIP1559Params,
 		}
-		empty := miner.generateWork(emptyParams, witness)
+		empty := miner.generateWork(ctx, emptyParams, witness)
 		if empty.err != nil {
 			return nil, empty.err
 		}
@@ -360,7 +361,7 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs, witness bool) (*Payload
 		updatePayload := func() time.Duration {
 			start := time.Now()
 			// getSealingBlock is interrupted by shared interrupt
-			r := miner.generateWork(fullParams, witness)
+			r := miner.generateWork(ctx, fullParams, witness)
 			dur := time.Since(start)
 			// update handles error case
 			payload.update(r, dur)
@@ -18,6 +18,7 @@ package miner
 
 import (
 	"bytes"
+	"context"
 	"math/big"
 	"reflect"
 	"testing"
@@ -223,7 +224,7 @@ func testBuildPayload(t *testing.T, noTxPool, interrupt bool, params1559 []byte)
 
 	// payload resolution now interrupts block building, so we have to
 	// wait for the payloading building process to build its first block
-	payload, err := w.buildPayload(args, false)
+	payload, err := w.buildPayload(context.Background(), args, false)
 	if err != nil {
 		t.Fatalf("Failed to build payload %v", err)
 	}
@@ -305,7 +306,7 @@ func testBuildPayloadWrongConfig(t *testing.T, params1559 []byte) {
 	w, b := newTestWorker(t, config, ethash.NewFaker(), db, 0)
 
 	args := newPayloadArgs(b.chain.CurrentBlock().Hash(), params1559)
-	payload, err := w.buildPayload(args, false)
+	payload, err := w.buildPayload(context.Background(), args, false)
 	if err == nil && (payload == nil || payload.err == nil) {
 		t.Fatalf("expected error, got none")
 	}
@@ -321,7 +322,7 @@ func TestBuildPayloadInvalidHoloceneParams(t *testing.T) {
 	badParams := eip1559.EncodeHolocene1559Params(0, 6)
 
 	args := newPayloadArgs(b.chain.CurrentBlock().Hash(), badParams)
-	payload, err := w.buildPayload(args, false)
+	payload, err := w.buildPayload(context.Background(), args, false)
 	if err == nil && (payload == nil || payload.err == nil) {
 		t.Fatalf("expected error, got none")
 	}
@@ -35,10 +35,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -115,7 +117,19 @@ type generateParams struct {
 }
 
 // generateWork generates a sealing block based on the given parameters.
-func (miner *Miner) generateWork(params *generateParams, witness bool) *newPayloadResult {
+func (miner *Miner) generateWork(ctx context.Context, params *generateParams, witness bool) (result *newPayloadResult) {
+	_, span := telemetry.StartSpan(ctx, "miner.generateWork",
+		attribute.String("parent", params.parentHash.Hex()),
+		attribute.Int64("timestamp", int64(params.timestamp)),
+		attribute.Bool("update", params.isUpdate),
+	)
+	defer func() {
+		if result.block != nil {
+			span.SetAttributes(attribute.Int("txs", len(result.block.Transactions())), attribute.Int64("gas", int64(result.block.GasUsed())))
+		}
+		telemetry.EndSpan(span, result.err)
+	}()
+
 	work, err := miner.prepareWork(params, witness)
 	if err != nil {
 		return &newPayloadResult{err: err}
@@ -181,6 +181,9 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			methodLimits:           api.node.config.RPCMethodLimits,
+			accessLog:              api.node.accessLog,
+			tracer:                 api.node.config.RPCTracer,
 		},
 	}
 	if cors != nil {
@@ -259,6 +262,9 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			methodLimits:           api.node.config.RPCMethodLimits,
+			accessLog:              api.node.accessLog,
+			tracer:                 api.node.config.RPCTracer,
 		},
 	}
 	if apis != nil {
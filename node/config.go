@@ -204,6 +204,28 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCMethodLimits configures per-method concurrency caps, execution timeouts
+	// and per-client call rates on the public HTTP and WebSocket endpoints. Client
+	// quotas are keyed by remote IP address.
+	RPCMethodLimits map[string]rpc.MethodLimit `toml:",omitempty"`
+
+	// AuthMethodLimits configures the per-method limits of the authenticated
+	// endpoint. Client quotas are keyed by the subject of the JWT token, falling
+	// back to the remote IP address for tokens without one.
+	AuthMethodLimits map[string]rpc.MethodLimit `toml:",omitempty"`
+
+	// RPCAccessLog is the destination of the structured JSON-RPC access logs:
+	// "stdout", or the path of a file rotated by size. Empty disables them.
+	RPCAccessLog string `toml:",omitempty"`
+
+	// RPCAccessLogSampleRate is the fraction of successful calls written to the
+	// access log. Failed calls are always logged. Zero logs every call.
+	RPCAccessLogSampleRate float64 `toml:",omitempty"`
+
+	// RPCTracer instruments the calls served by the HTTP and WebSocket endpoints,
+	// e.g. exporting them as trace spans. Nil disables tracing.
+	RPCTracer rpc.Tracer `toml:"-"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -211,6 +233,19 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// AncientTableDirs places tables of the ancient stores outside of the ancient
+	// directory, e.g. to keep the rarely read ones on cheaper disks. The keys are
+	// <freezer>/<table>, e.g. "chain/receipts" or "state/account.data", and relative
+	// directories are resolved like the ancient directory. Existing tables must be
+	// moved with 'geth db move-ancient' before changing their placement.
+	AncientTableDirs map[string]string `toml:",omitempty"`
+
+	// AncientTableCodecs sets the compression codec of the compressed tables of the
+	// ancient stores, either "snappy" (the default) or "zstd". The keys are like in
+	// AncientTableDirs. New tables are created with the configured codec, existing
+	// ones must be rewritten with 'geth db recompress-ancient' to change it.
+	AncientTableCodecs map[string]string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -40,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gofrs/flock"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Node is a container on which services can be registered.
@@ -66,6 +68,9 @@ type Node struct {
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
+	accessLog       *rpc.AccessLogger // Structured access logger of the RPC endpoints, nil if disabled
+	accessLogCloser io.Closer         // Output of the access logger, if it needs closing
+
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
 
@@ -390,6 +395,9 @@ func (n *Node) startRPC() error {
 	if err := n.startInProc(apis); err != nil {
 		return err
 	}
+	if err := n.openAccessLog(); err != nil {
+		return err
+	}
 
 	// Configure IPC.
 	if n.ipc.endpoint != "" {
@@ -405,6 +413,9 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		methodLimits:           n.config.RPCMethodLimits,
+		accessLog:              n.accessLog,
+		tracer:                 n.config.RPCTracer,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -452,6 +463,9 @@ func (n *Node) startRPC() error {
 			batchItemLimit:         engineAPIBatchItemLimit,
 			batchResponseSizeLimit: engineAPIBatchResponseSizeLimit,
 			httpBodyLimit:          engineAPIBodyLimit,
+			methodLimits:           n.config.AuthMethodLimits,
+			accessLog:              n.accessLog,
+			tracer:                 n.config.RPCTracer,
 		}
 		err := server.enableRPC(allAPIs, httpConfig{
 			CorsAllowedOrigins: DefaultAuthCors,
@@ -533,6 +547,41 @@ func (n *Node) stopRPC() {
 	n.wsAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
+	n.closeAccessLog()
+}
+
+// openAccessLog creates the structured RPC access logger, if configured.
+func (n *Node) openAccessLog() error {
+	var out io.Writer
+	switch n.config.RPCAccessLog {
+	case "":
+		return nil
+	case "stdout":
+		out = os.Stdout
+	default:
+		path := n.config.ResolvePath(n.config.RPCAccessLog)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		file := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    100, // megabytes
+			MaxBackups: 10,
+		}
+		out, n.accessLogCloser = file, file
+	}
+	n.accessLog = rpc.NewAccessLogger(out, n.config.RPCAccessLogSampleRate)
+	n.log.Info("Enabled RPC access log", "output", n.config.RPCAccessLog, "sample", n.config.RPCAccessLogSampleRate)
+	return nil
+}
+
+// closeAccessLog releases the output of the RPC access logger.
+func (n *Node) closeAccessLog() {
+	if n.accessLogCloser != nil {
+		n.accessLogCloser.Close()
+		n.accessLogCloser = nil
+	}
+	n.accessLog = nil
 }
 
 // startInProc registers all RPC APIs on the inproc server.
@@ -756,13 +805,15 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 		db, err = rawdb.NewDatabaseWithFreezer(memorydb.New(), "", namespace, readonly)
 	} else {
 		db, err = rawdb.Open(rawdb.OpenOptions{
-			Type:              n.config.DBEngine,
-			Directory:         n.ResolvePath(name),
-			AncientsDirectory: n.ResolveAncient(name, ancient),
-			Namespace:         namespace,
-			Cache:             cache,
-			Handles:           handles,
-			ReadOnly:          readonly,
+			Type:               n.config.DBEngine,
+			Directory:          n.ResolvePath(name),
+			AncientsDirectory:  n.ResolveAncient(name, ancient),
+			AncientTableDirs:   n.resolveAncientTableDirs(),
+			AncientTableCodecs: n.config.AncientTableCodecs,
+			Namespace:          namespace,
+			Cache:              cache,
+			Handles:            handles,
+			ReadOnly:           readonly,
 		})
 	}
 
@@ -788,6 +839,19 @@ func (n *Node) ResolveAncient(name string, ancient string) string {
 	return ancient
 }
 
+// resolveAncientTableDirs returns the configured placement of ancient tables with
+// absolute paths.
+func (n *Node) resolveAncientTableDirs() map[string]string {
+	if len(n.config.AncientTableDirs) == 0 {
+		return nil
+	}
+	dirs := make(map[string]string, len(n.config.AncientTableDirs))
+	for table, dir := range n.config.AncientTableDirs {
+		dirs[table] = n.ResolvePath(dir)
+	}
+	return dirs
+}
+
 // closeTrackingDB wraps the Close method of a database. When the database is closed by the
 // service, the wrapper removes it from the node's database map. This ensures that Node
 // won't auto-close the database if it is closed by the service that opened it.
@@ -235,3 +235,77 @@ func offsetTimeAuth(secret [32]byte, offset time.Duration) rpc.HTTPAuth {
 		return nil
 	}
 }
+
+// Tests that the method limits of the authenticated endpoint apply per JWT subject.
+func TestAuthMethodLimits(t *testing.T) {
+	var secret [32]byte
+	if _, err := crand.Read(secret[:]); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	jwtPath := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(jwtPath, []byte(hexutil.Encode(secret[:])), 0600); err != nil {
+		t.Fatalf("failed to prepare jwt secret file: %v", err)
+	}
+	conf := &Config{
+		HTTPHost:    "127.0.0.1",
+		HTTPPort:    0,
+		HTTPModules: []string{"eth"},
+		AuthAddr:    "127.0.0.1",
+		AuthPort:    0,
+		JWTSecret:   jwtPath,
+
+		AuthMethodLimits: map[string]rpc.MethodLimit{"*": {ClientRate: 0.001, ClientBurst: 1}},
+	}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "engine", Service: helloRPC("hello engine"), Authenticated: true},
+		{Namespace: "eth", Service: helloRPC("hello eth")},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	call := func(endpoint, method string, options ...rpc.ClientOption) error {
+		cl, err := rpc.DialOptions(context.Background(), endpoint, options...)
+		if err != nil {
+			t.Fatalf("failed to dial rpc endpoint: %v", err)
+		}
+		defer cl.Close()
+		var x string
+		return cl.Call(&x, method)
+	}
+	// The quota of each subject is exhausted after a single call
+	for _, subject := range []string{"op-node", "builder"} {
+		if err := call(node.HTTPAuthEndpoint(), "engine_helloWorld", rpc.WithHTTPAuth(subjectAuth(secret, subject))); err != nil {
+			t.Fatalf("subject %s: first call failed: %v", subject, err)
+		}
+		if err := call(node.HTTPAuthEndpoint(), "engine_helloWorld", rpc.WithHTTPAuth(subjectAuth(secret, subject))); err == nil {
+			t.Fatalf("subject %s: second call not throttled", subject)
+		}
+	}
+	// The limits of the authenticated endpoint don't apply to the public one
+	for i := 0; i < 2; i++ {
+		if err := call(node.HTTPEndpoint(), "eth_helloWorld"); err != nil {
+			t.Fatalf("public call %d failed: %v", i, err)
+		}
+	}
+}
+
+func subjectAuth(secret [32]byte, subject string) rpc.HTTPAuth {
+	return func(header http.Header) error {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iat": &jwt.NumericDate{Time: time.Now()},
+			"sub": subject,
+		})
+		s, err := token.SignedString(secret[:])
+		if err != nil {
+			return fmt.Errorf("failed to create JWT token: %w", err)
+		}
+		header.Set("Authorization", "Bearer "+s)
+		return nil
+	}
+}
@@ -58,6 +58,9 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	methodLimits           map[string]rpc.MethodLimit
+	accessLog              *rpc.AccessLogger // optional structured access logger
+	tracer                 rpc.Tracer        // optional call tracer
 }
 
 type rpcHandler struct {
@@ -306,6 +309,9 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetMethodLimits(config.methodLimits)
+	srv.SetAccessLogger(config.accessLog)
+	srv.SetTracer(config.tracer)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -341,6 +347,9 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetMethodLimits(config.methodLimits)
+	srv.SetAccessLogger(config.accessLog)
+	srv.SetTracer(config.tracer)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
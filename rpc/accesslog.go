@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// requestIDHeader is the HTTP header carrying the caller supplied request id. It
+// is read from the upgrade request of WebSocket connections.
+const requestIDHeader = "X-Request-Id"
+
+// AccessLogEntry is a single structured access log record of a served call.
+type AccessLogEntry struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	ParamsSize   int       `json:"paramsSize"`
+	DurationMs   float64   `json:"durationMs"`
+	ResponseSize int       `json:"responseSize"` // Size of the encoded response message
+	ErrorCode    int       `json:"errorCode,omitempty"`
+	Transport    string    `json:"transport,omitempty"`
+	RemoteAddr   string    `json:"remoteAddr,omitempty"`
+	JWTSubject   string    `json:"jwtSubject,omitempty"`
+	BatchID      string    `json:"batchId,omitempty"`
+	RequestID    string    `json:"requestId,omitempty"`
+}
+
+// AccessLogger writes structured JSON access logs of served RPC calls, one
+// object per line. Successful calls are sampled at the configured rate, failed
+// calls are always logged.
+type AccessLogger struct {
+	sampleRate float64
+
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+// NewAccessLogger creates an access logger writing into w, logging the given
+// fraction of successful calls. A sample rate of zero or above one logs all.
+func NewAccessLogger(w io.Writer, sampleRate float64) *AccessLogger {
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+	return &AccessLogger{
+		sampleRate: sampleRate,
+		enc:        json.NewEncoder(w),
+	}
+}
+
+// SetAccessLogger configures the logger to record every served call into.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetAccessLogger(logger *AccessLogger) {
+	s.accessLog = logger
+}
+
+// log records a served call. The response is nil for notifications.
+func (l *AccessLogger) log(ctx context.Context, batchID string, msg *jsonrpcMessage, resp *jsonrpcMessage, elapsed time.Duration) {
+	failed := resp != nil && resp.Error != nil
+	if !failed && l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+		return
+	}
+	info := PeerInfoFromContext(ctx)
+	entry := &AccessLogEntry{
+		Time:       time.Now(),
+		Method:     msg.Method,
+		ParamsSize: len(msg.Params),
+		DurationMs: float64(elapsed) / float64(time.Millisecond),
+		Transport:  info.Transport,
+		RemoteAddr: info.RemoteAddr,
+		JWTSubject: info.JWTSubject,
+		BatchID:    batchID,
+		RequestID:  info.HTTP.RequestID,
+	}
+	if resp != nil {
+		// Measure the response as encoded by the codecs, which is the same JSON
+		// except for the array around batched responses.
+		if blob, err := json.Marshal(resp); err == nil {
+			entry.ResponseSize = len(blob)
+		}
+		if resp.Error != nil {
+			entry.ErrorCode = resp.Error.Code
+		}
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.enc.Encode(entry)
+}
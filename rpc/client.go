@@ -89,6 +89,9 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	limiter              *callLimiter
+	accessLog            *AccessLogger
+	tracer               Tracer
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -120,7 +123,7 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
-	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize, c.limiter, c.accessLog, c.tracer)
 	return &clientConn{conn, handler}
 }
 
@@ -248,6 +251,9 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		limiter:              cfg.limiter,
+		accessLog:            cfg.accessLog,
+		tracer:               cfg.tracer,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
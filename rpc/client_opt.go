@@ -41,6 +41,9 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	limiter            *callLimiter  // server-side method limits, not configurable by clients
+	accessLog          *AccessLogger // server-side access logger, not configurable by clients
+	tracer             Tracer        // server-side call tracer, not configurable by clients
 }
 
 func (cfg *clientConfig) initHeaders() {
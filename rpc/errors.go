@@ -55,12 +55,14 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(throttledError)
 )
 
 const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeThrottled        = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -164,3 +166,16 @@ type internalServerError struct {
 func (e *internalServerError) ErrorCode() int { return e.code }
 
 func (e *internalServerError) Error() string { return e.message }
+
+// throttledError is returned if a call is rejected due to the method limits
+// configured on the server (EIP-1474 "limit exceeded").
+type throttledError struct {
+	method string
+	reason string
+}
+
+func (e *throttledError) ErrorCode() int { return errcodeThrottled }
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("%s throttled: %s", e.method, e.reason)
+}
@@ -65,6 +65,9 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	limiter              *callLimiter  // optional per-method limits, nil if disabled
+	accessLog            *AccessLogger // optional structured access logger, nil if disabled
+	tracer               Tracer        // optional call tracer, nil if disabled
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -73,9 +76,10 @@ type handler struct {
 type callProc struct {
 	ctx       context.Context
 	notifiers []*Notifier
+	batchID   string // identifier of the batch the call belongs to, for access logs
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, batchRequestLimit, batchResponseMaxSize int) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, batchRequestLimit, batchResponseMaxSize int, limiter *callLimiter, accessLog *AccessLogger, tracer Tracer) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	h := &handler{
 		reg:                  reg,
@@ -90,6 +94,9 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		log:                  log.Root(),
 		batchRequestLimit:    batchRequestLimit,
 		batchResponseMaxSize: batchResponseMaxSize,
+		limiter:              limiter,
+		accessLog:            accessLog,
+		tracer:               tracer,
 	}
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
@@ -206,6 +213,9 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		cp.ctx, cancel = context.WithCancel(cp.ctx)
 		defer cancel()
 
+		if h.accessLog != nil {
+			cp.batchID = string(h.idgen())
+		}
 		// Cancel the request context after timeout and send an error response. Since the
 		// currently-running method might not return immediately on timeout, we must wait
 		// for the timeout concurrently with processing the request.
@@ -467,10 +477,16 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	case msg.isNotification():
 		h.handleCall(ctx, msg)
 		h.log.Debug("Served "+msg.Method, "duration", time.Since(start))
+		if h.accessLog != nil {
+			h.accessLog.log(ctx.ctx, ctx.batchID, msg, nil, time.Since(start))
+		}
 		return nil
 
 	case msg.isCall():
 		resp := h.handleCall(ctx, msg)
+		if h.accessLog != nil {
+			h.accessLog.log(ctx.ctx, ctx.batchID, msg, resp, time.Since(start))
+		}
 		var logctx []any
 		logctx = append(logctx, "reqid", idForLog{msg.ID}, "duration", time.Since(start))
 		if resp.Error != nil {
@@ -512,7 +528,27 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, endTrace := cp.ctx, func(error) {}
+	if h.tracer != nil {
+		ctx, endTrace = h.tracer.StartCall(cp.ctx, msg.Method)
+	}
+	var answer *jsonrpcMessage
+	if h.limiter != nil && callb != h.unsubscribeCb {
+		release, timeout, err := h.limiter.acquire(ctx, msg.Method)
+		if err != nil {
+			answer = msg.errorResponse(err)
+			endTrace(answer.Error)
+			return answer
+		}
+		answer = h.runLimitedMethod(ctx, msg, callb, args, release, timeout)
+	} else {
+		answer = h.runMethod(ctx, msg, callb, args)
+	}
+	if answer.Error != nil {
+		endTrace(answer.Error)
+	} else {
+		endTrace(nil)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -572,6 +608,35 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	return msg.response(result)
 }
 
+// runLimitedMethod runs the Go callback for an RPC method within the execution
+// slot reserved by the call limiter. If a timeout is set, the call context is
+// cancelled and an error returned once it expires. The slot is only released
+// when the callback actually returns, so misbehaving methods keep counting
+// against the concurrency cap.
+func (h *handler) runLimitedMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, release func(), timeout time.Duration) *jsonrpcMessage {
+	if timeout <= 0 {
+		defer release()
+		return h.runMethod(ctx, msg, callb, args)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan *jsonrpcMessage, 1)
+	h.callWG.Add(1)
+	go func() {
+		defer h.callWG.Done()
+		defer release()
+		done <- h.runMethod(ctx, msg, callb, args)
+	}()
+	select {
+	case answer := <-done:
+		return answer
+	case <-ctx.Done():
+		updateTimeoutMeter(msg.Method)
+		return msg.errorResponse(&internalServerError{errcodeTimeout, errMsgTimeout})
+	}
+}
+
 // unsubscribe is the callback function for all *_unsubscribe calls.
 func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
@@ -324,9 +324,21 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	connInfo.HTTP.RequestID = r.Header.Get(requestIDHeader)
+	connInfo.JWTSubject = jwtSubjectFromContext(r.Context())
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
+	// Propagate the caller's request id to any outbound RPC calls made while
+	// serving the request.
+	if id := connInfo.HTTP.RequestID; id != "" {
+		ctx = NewContextWithHeaders(ctx, http.Header{requestIDHeader: {id}})
+	}
+	// Continue the caller's trace if it sent W3C trace context headers.
+	if s.tracer != nil {
+		ctx = s.tracer.ExtractHTTP(ctx, r.Header)
+	}
+
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
 	// single request.
@@ -17,11 +17,16 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -243,3 +248,123 @@ func TestNewContextWithHeaders(t *testing.T) {
 		t.Error("call failed:", err)
 	}
 }
+
+func TestHTTPAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	s := newTestServer()
+	s.SetAccessLogger(NewAccessLogger(&out, 1))
+	defer s.Stop()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetHeader(requestIDHeader, "req-1")
+
+	if err := c.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"y", 2}, Result: new(echoResult)},
+		{Method: "no_such_method"},
+	}
+	if err := c.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	var entries []AccessLogEntry
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry AccessLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid access log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("wrong number of access log entries: have %d, want 3", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.RequestID != "req-1" {
+			t.Errorf("entry %d: wrong request id %q", i, entry.RequestID)
+		}
+		if entry.Transport != "http" || entry.RemoteAddr == "" {
+			t.Errorf("entry %d: missing peer info: %+v", i, entry)
+		}
+	}
+	if entries[0].Method != "test_echo" || entries[0].BatchID != "" || entries[0].ParamsSize == 0 || entries[0].ResponseSize == 0 {
+		t.Errorf("wrong single call entry: %+v", entries[0])
+	}
+	if entries[1].BatchID == "" || entries[1].BatchID != entries[2].BatchID {
+		t.Errorf("batch entries not correlated: %q, %q", entries[1].BatchID, entries[2].BatchID)
+	}
+	if entries[2].Method != "no_such_method" || entries[2].ErrorCode != -32601 {
+		t.Errorf("wrong failed call entry: %+v", entries[2])
+	}
+	// The response size of a failed call is the size of the written response
+	out.Reset()
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"no_such_method"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry AccessLogEntry
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid access log entry %q: %v", out.String(), err)
+	}
+	if have, want := entry.ResponseSize, len(bytes.TrimSpace(body)); have != want {
+		t.Errorf("wrong response size of failed call: have %d, want %d", have, want)
+	}
+}
+
+type testTracer struct {
+	lock   sync.Mutex
+	header string
+	calls  []string
+}
+
+func (t *testTracer) ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.header = header.Get("traceparent")
+	return ctx
+}
+
+func (t *testTracer) StartCall(ctx context.Context, method string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		t.calls = append(t.calls, fmt.Sprintf("%s %v", method, err))
+	}
+}
+
+func TestHTTPTracer(t *testing.T) {
+	tracer := new(testTracer)
+	s := newTestServer()
+	s.SetTracer(tracer)
+	defer s.Stop()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetHeader("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if err := c.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	c.Call(nil, "test_returnError")
+
+	if tracer.header != "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" {
+		t.Errorf("trace headers not passed to tracer: %q", tracer.header)
+	}
+	want := []string{"test_echo <nil>", "test_returnError testError"}
+	if !reflect.DeepEqual(tracer.calls, want) {
+		t.Errorf("wrong traced calls: have %q, want %q", tracer.calls, want)
+	}
+}
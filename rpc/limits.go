@@ -0,0 +1,205 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"golang.org/x/time/rate"
+)
+
+// maxTrackedClients is the number of per-client token buckets retained by the
+// limiter. Least recently seen clients are forgotten first.
+const maxTrackedClients = 65536
+
+// MethodLimit configures the throttling applied to calls of an RPC method.
+// Zero values disable the respective limit.
+type MethodLimit struct {
+	MaxConcurrent int           // Maximum number of concurrent executions across all clients
+	Timeout       time.Duration // Maximum execution time of a single call
+	ClientRate    float64       // Sustained calls per second permitted for a single client
+	ClientBurst   int           // Maximum burst of calls permitted for a single client
+}
+
+// jwtSubjectKey is the context key of the authenticated JWT subject.
+type jwtSubjectKey struct{}
+
+// ContextWithJWTSubject returns a copy of ctx carrying the subject claim of the
+// JWT token the request was authenticated with. The RPC server uses it to key
+// per-client quotas and to populate PeerInfo.
+func ContextWithJWTSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, jwtSubjectKey{}, subject)
+}
+
+// jwtSubjectFromContext retrieves the authenticated JWT subject, if any.
+func jwtSubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(jwtSubjectKey{}).(string)
+	return subject
+}
+
+// callLimiter enforces the configured method limits on calls served by a Server.
+// It is shared across all connections of the server.
+type callLimiter struct {
+	limits map[string]MethodLimit   // Limits keyed by method, namespace wildcard or "*"
+	sems   map[string]chan struct{} // Concurrency semaphores keyed like limits
+
+	lock    sync.Mutex
+	buckets lru.BasicLRU[string, *rate.Limiter] // Per-client token buckets
+}
+
+// newCallLimiter creates a limiter for the given method limits.
+func newCallLimiter(limits map[string]MethodLimit) *callLimiter {
+	l := &callLimiter{
+		limits:  make(map[string]MethodLimit, len(limits)),
+		sems:    make(map[string]chan struct{}),
+		buckets: lru.NewBasicLRU[string, *rate.Limiter](maxTrackedClients),
+	}
+	for key, limit := range limits {
+		l.limits[key] = limit
+		if limit.MaxConcurrent > 0 {
+			l.sems[key] = make(chan struct{}, limit.MaxConcurrent)
+		}
+	}
+	return l
+}
+
+// lookup finds the limit applicable to a method. Exact method names take
+// precedence over namespace wildcards ("debug_*"), which take precedence over
+// the catch-all "*".
+func (l *callLimiter) lookup(method string) (string, MethodLimit, bool) {
+	if limit, ok := l.limits[method]; ok {
+		return method, limit, true
+	}
+	if namespace, _, ok := strings.Cut(method, serviceMethodSeparator); ok {
+		key := namespace + serviceMethodSeparator + "*"
+		if limit, ok := l.limits[key]; ok {
+			return key, limit, true
+		}
+	}
+	if limit, ok := l.limits["*"]; ok {
+		return "*", limit, true
+	}
+	return "", MethodLimit{}, false
+}
+
+// acquire checks the quotas of the calling client and reserves an execution slot
+// for the method. On success, the returned function must be called once the call
+// finishes, along with the timeout to apply to it (if any).
+func (l *callLimiter) acquire(ctx context.Context, method string) (func(), time.Duration, error) {
+	key, limit, ok := l.lookup(method)
+	if !ok {
+		return func() {}, 0, nil
+	}
+	if limit.ClientRate > 0 {
+		client := clientIdentity(ctx)
+		burst := limit.ClientBurst
+		if burst < 1 {
+			burst = 1
+		}
+		l.lock.Lock()
+		bucket, ok := l.buckets.Get(key + "/" + client)
+		if !ok {
+			bucket = rate.NewLimiter(rate.Limit(limit.ClientRate), burst)
+			l.buckets.Add(key+"/"+client, bucket)
+		}
+		l.lock.Unlock()
+
+		if !bucket.Allow() {
+			updateThrottledMeter(method, "rate")
+			return nil, 0, &throttledError{method: method, reason: "client rate limit exceeded"}
+		}
+	}
+	if sem := l.sems[key]; sem != nil {
+		select {
+		case sem <- struct{}{}:
+			updateInflightGauge(method, 1)
+			return func() {
+				<-sem
+				updateInflightGauge(method, -1)
+			}, limit.Timeout, nil
+		default:
+			updateThrottledMeter(method, "concurrency")
+			return nil, 0, &throttledError{method: method, reason: "too many concurrent calls"}
+		}
+	}
+	return func() {}, limit.Timeout, nil
+}
+
+// clientIdentity returns the key identifying the calling client for quota
+// purposes: the authenticated JWT subject if available, the remote IP otherwise.
+func clientIdentity(ctx context.Context) string {
+	info := PeerInfoFromContext(ctx)
+	if info.JWTSubject != "" {
+		return "jwt:" + info.JWTSubject
+	}
+	if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+		return host
+	}
+	return info.RemoteAddr
+}
+
+// ParseMethodLimits parses method limits from their command line representation,
+// a semicolon separated list of "method:key=value,..." entries, where method may
+// be an exact method name, a namespace wildcard (e.g. "debug_*") or "*", and the
+// keys are "concurrency", "timeout", "rate" and "burst". For example:
+//
+//	debug_*:concurrency=4,timeout=30s;eth_getLogs:rate=5,burst=10
+func ParseMethodLimits(spec string) (map[string]MethodLimit, error) {
+	limits := make(map[string]MethodLimit)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		method, params, ok := strings.Cut(entry, ":")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid method limit %q", entry)
+		}
+		var limit MethodLimit
+		for _, param := range strings.Split(params, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid method limit parameter %q for %s", param, method)
+			}
+			var err error
+			switch key {
+			case "concurrency":
+				limit.MaxConcurrent, err = strconv.Atoi(value)
+			case "timeout":
+				limit.Timeout, err = time.ParseDuration(value)
+			case "rate":
+				limit.ClientRate, err = strconv.ParseFloat(value, 64)
+			case "burst":
+				limit.ClientBurst, err = strconv.Atoi(value)
+			default:
+				err = fmt.Errorf("unknown key %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid method limit parameter %q for %s: %v", param, method, err)
+			}
+		}
+		limits[method] = limit
+	}
+	return limits, nil
+}
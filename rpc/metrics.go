@@ -32,6 +32,21 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// rpcDurationFamily tracks the serving times in seconds labeled by method and
+	// outcome, alongside the flat per-method histograms kept for compatibility.
+	rpcDurationFamily = metrics.NewRegisteredFamily("rpc/duration_seconds", nil, []string{"method", "status"}, func() metrics.NativeHistogram {
+		return metrics.NewNativeHistogram(metrics.DefaultNativeHistogramSchema)
+	})
+
+	// throttledMeterName is the prefix of the per-method throttled call meters.
+	throttledMeterName = "rpc/throttled"
+
+	// timeoutMeterName is the prefix of the per-method timed out call meters.
+	timeoutMeterName = "rpc/timeout"
+
+	// inflightGaugeName is the prefix of the per-method concurrent call gauges.
+	inflightGaugeName = "rpc/inflight"
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -47,4 +62,30 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 		)
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
+	rpcDurationFamily.With(method, note).Observe(elapsed.Seconds())
+}
+
+// updateThrottledMeter tracks a call rejected by the method limits.
+func updateThrottledMeter(method string, reason string) {
+	if !metrics.Enabled {
+		return
+	}
+	metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s/%s", throttledMeterName, method, reason), nil).Mark(1)
+}
+
+// updateTimeoutMeter tracks a call aborted due to exceeding its execution timeout.
+func updateTimeoutMeter(method string) {
+	if !metrics.Enabled {
+		return
+	}
+	metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s", timeoutMeterName, method), nil).Mark(1)
+}
+
+// updateInflightGauge tracks the number of concurrently executing calls of a
+// concurrency limited method.
+func updateInflightGauge(method string, delta int64) {
+	if !metrics.Enabled {
+		return
+	}
+	metrics.GetOrRegisterGauge(fmt.Sprintf("%s/%s", inflightGaugeName, method), nil).Inc(delta)
 }
@@ -54,6 +54,9 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	limiter            *callLimiter
+	accessLog          *AccessLogger
+	tracer             Tracer
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -89,6 +92,21 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetMethodLimits configures per-method concurrency caps, execution timeouts and
+// per-client call rates. Limits are keyed by exact method name, namespace wildcard
+// (e.g. "debug_*") or "*" for all methods; the most specific match applies.
+// Throttled calls fail with error code -32005.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetMethodLimits(limits map[string]MethodLimit) {
+	if len(limits) == 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = newCallLimiter(limits)
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -114,6 +132,9 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		limiter:            s.limiter,
+		accessLog:          s.accessLog,
+		tracer:             s.tracer,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -147,7 +168,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
+	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit, s.limiter, s.accessLog, s.tracer)
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -234,7 +255,13 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		// Request id supplied in the X-Request-Id header. For WebSocket, this is
+		// the one of the upgrade request, shared by all calls of the connection.
+		RequestID string
 	}
+
+	// Subject claim of the JWT token the client authenticated with, if any.
+	JWTSubject string
 }
 
 type peerInfoContextKey struct{}
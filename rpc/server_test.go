@@ -19,10 +19,12 @@ package rpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -192,3 +194,68 @@ func TestServerBatchResponseSizeLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestServerMethodLimits(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetMethodLimits(map[string]MethodLimit{
+		"test_block": {MaxConcurrent: 1, Timeout: 100 * time.Millisecond},
+		"test_*":     {ClientRate: 0.001, ClientBurst: 2},
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	errorCode := func(err error) int {
+		if re, ok := err.(Error); ok {
+			return re.ErrorCode()
+		}
+		return 0
+	}
+	// The namespace wildcard applies a per-client rate limit to other methods.
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_echo", "x", 1); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if err := client.Call(nil, "test_echo", "x", 1); errorCode(err) != errcodeThrottled {
+		t.Fatalf("wrong error for rate limited call: %v", err)
+	}
+	// The exact method limit times out the blocking call and caps concurrency.
+	if err := client.Call(nil, "test_block"); errorCode(err) != errcodeTimeout {
+		t.Fatalf("wrong error for timed out call: %v", err)
+	}
+	var (
+		release func()
+		err     error
+	)
+	for i := 0; i < 100; i++ { // the timed out call releases its slot asynchronously
+		if release, _, err = server.limiter.acquire(context.Background(), "test_block"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to acquire slot: %v", err)
+	}
+	if err := client.Call(nil, "test_block"); errorCode(err) != errcodeThrottled {
+		t.Fatalf("wrong error for concurrency limited call: %v", err)
+	}
+	release()
+}
+
+func TestParseMethodLimits(t *testing.T) {
+	limits, err := ParseMethodLimits("debug_*:concurrency=4,timeout=30s; eth_getLogs:rate=5,burst=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]MethodLimit{
+		"debug_*":     {MaxConcurrent: 4, Timeout: 30 * time.Second},
+		"eth_getLogs": {ClientRate: 5, ClientBurst: 10},
+	}
+	if !reflect.DeepEqual(limits, want) {
+		t.Fatalf("wrong limits: have %+v, want %+v", limits, want)
+	}
+	if _, err := ParseMethodLimits("eth_call:speed=1"); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer instruments the method calls served by a server, e.g. exporting them as
+// trace spans. Implementations must be safe for concurrent use.
+type Tracer interface {
+	// ExtractHTTP returns the context to serve an HTTP request in, continuing the
+	// trace of the caller if its headers carry one.
+	ExtractHTTP(ctx context.Context, header http.Header) context.Context
+
+	// StartCall is invoked before a method call is executed. The call runs in the
+	// returned context and end is invoked with its error once it completed.
+	StartCall(ctx context.Context, method string) (_ context.Context, end func(err error))
+}
+
+// SetTracer configures the tracer to instrument every served call with.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetTracer(tracer Tracer) {
+	s.tracer = tracer
+}
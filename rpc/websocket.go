@@ -60,7 +60,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
+		codec := newWebsocketCodec(conn, r.Host, r.Header, jwtSubjectFromContext(r.Context()), wsDefaultReadLimit)
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -259,7 +259,7 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 		if cfg.wsMessageSizeLimit != nil && *cfg.wsMessageSizeLimit >= 0 {
 			messageSizeLimit = *cfg.wsMessageSizeLimit
 		}
-		return newWebsocketCodec(conn, dialURL, header, messageSizeLimit), nil
+		return newWebsocketCodec(conn, dialURL, header, "", messageSizeLimit), nil
 	}
 	return connect, nil
 }
@@ -291,7 +291,7 @@ type websocketCodec struct {
 	pongReceived chan struct{}
 }
 
-func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readLimit int64) ServerCodec {
+func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, jwtSubject string, readLimit int64) ServerCodec {
 	conn.SetReadLimit(readLimit)
 	encode := func(v interface{}, isErrorResponse bool) error {
 		return conn.WriteJSON(v)
@@ -304,12 +304,14 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 		info: PeerInfo{
 			Transport:  "ws",
 			RemoteAddr: conn.RemoteAddr().String(),
+			JWTSubject: jwtSubject,
 		},
 	}
 	// Fill in connection details.
 	wc.info.HTTP.Host = host
 	wc.info.HTTP.Origin = req.Get("Origin")
 	wc.info.HTTP.UserAgent = req.Get("User-Agent")
+	wc.info.HTTP.RequestID = req.Get(requestIDHeader)
 	// Start pinger.
 	conn.SetPongHandler(func(appData string) error {
 		select {
@@ -17,7 +17,9 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"net"
 	"net/http"
@@ -209,6 +211,46 @@ func TestWebsocketPeerInfo(t *testing.T) {
 	}
 }
 
+// This test checks that the request id of the upgrade request is recorded in the
+// access log entries of all calls made over the connection.
+func TestWebsocketAccessLog(t *testing.T) {
+	var (
+		out   bytes.Buffer
+		s     = newTestServer()
+		ts    = httptest.NewServer(s.WebsocketHandler([]string{"*"}))
+		tsurl = "ws:" + strings.TrimPrefix(ts.URL, "http:")
+	)
+	s.SetAccessLogger(NewAccessLogger(&out, 1))
+	defer s.Stop()
+	defer ts.Close()
+
+	c, err := DialOptions(context.Background(), tsurl, WithHeader(requestIDHeader, "ws-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call(nil, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Call(nil, "no_such_method"); err == nil {
+		t.Fatal("expected error for unknown method")
+	}
+	c.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrong number of access log entries: have %d, want 2", len(lines))
+	}
+	for i, line := range lines {
+		var entry AccessLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid access log line %q: %v", line, err)
+		}
+		if entry.Transport != "ws" || entry.RequestID != "ws-1" {
+			t.Errorf("entry %d: wrong transport %q or request id %q", i, entry.Transport, entry.RequestID)
+		}
+	}
+}
+
 // This test checks that client handles WebSocket ping frames correctly.
 func TestClientWebsocketPing(t *testing.T) {
 	t.Parallel()
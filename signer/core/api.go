@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -44,9 +45,9 @@ const (
 	// numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
 	numberOfAccountsToDerive = 10
 	// ExternalAPIVersion -- see extapi_changelog.md
-	ExternalAPIVersion = "6.1.0"
+	ExternalAPIVersion = "6.2.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.3.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -111,6 +112,15 @@ type Validator interface {
 	ValidateTransaction(selector *string, tx *apitypes.SendTxArgs) (*apitypes.ValidationMessages, error)
 }
 
+// Simulator simulates transactions before they are submitted for approval, so
+// that the UI and rules can base their decision on the effects of a transaction
+// rather than its fields alone.
+type Simulator interface {
+	// SimulateTransaction executes the transaction on top of the current chain
+	// state and reports the outcome.
+	SimulateTransaction(ctx context.Context, tx *apitypes.SendTxArgs) (*apitypes.SimulationResult, error)
+}
+
 // SignerAPI defines the actual implementation of ExternalAPI
 type SignerAPI struct {
 	chainID     *big.Int
@@ -119,6 +129,7 @@ type SignerAPI struct {
 	validator   Validator
 	rejectMode  bool
 	credentials storage.Storage
+	simulator   Simulator
 }
 
 // Metadata about a request
@@ -225,9 +236,10 @@ func (m Metadata) String() string {
 type (
 	// SignTxRequest contains info about a Transaction to sign
 	SignTxRequest struct {
-		Transaction apitypes.SendTxArgs       `json:"transaction"`
-		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
-		Meta        Metadata                  `json:"meta"`
+		Transaction apitypes.SendTxArgs        `json:"transaction"`
+		Callinfo    []apitypes.ValidationInfo  `json:"call_info"`
+		Simulation  *apitypes.SimulationResult `json:"simulation,omitempty"`
+		Meta        Metadata                   `json:"meta"`
 	}
 	// SignTxResponse result from SignTxRequest
 	SignTxResponse struct {
@@ -242,6 +254,7 @@ type (
 		Messages    []*apitypes.NameValueType `json:"messages"`
 		Callinfo    []apitypes.ValidationInfo `json:"call_info"`
 		Hash        hexutil.Bytes             `json:"hash"`
+		TypedData   *apitypes.TypedData       `json:"typed_data,omitempty"`
 		Meta        Metadata                  `json:"meta"`
 	}
 	SignDataResponse struct {
@@ -287,12 +300,19 @@ func NewSignerAPI(am *accounts.Manager, chainID int64, noUSB bool, ui UIClientAP
 	if advancedMode {
 		log.Info("Clef is in advanced mode: will warn instead of reject")
 	}
-	signer := &SignerAPI{big.NewInt(chainID), am, ui, validator, !advancedMode, credentials}
+	signer := &SignerAPI{big.NewInt(chainID), am, ui, validator, !advancedMode, credentials, nil}
 	if !noUSB {
 		signer.startUSBListener()
 	}
 	return signer
 }
+
+// SetSimulator configures the simulator to run transactions through before they
+// are submitted for approval. Simulation is disabled if nil.
+func (api *SignerAPI) SetSimulator(simulator Simulator) {
+	api.simulator = simulator
+}
+
 func (api *SignerAPI) openTrezor(url accounts.URL) {
 	resp, err := api.UI.OnInputRequired(UserInputRequest{
 		Prompt: "Pin required to open Trezor wallet\n" +
@@ -517,6 +537,10 @@ func logDiff(original *SignTxRequest, new *SignTxResponse) bool {
 		modified = true
 		log.Info("Nonce changed by UI", "was", n0, "is", n1)
 	}
+	if c0, c1 := original.Transaction.Conditional, new.Transaction.Conditional; !reflect.DeepEqual(c0, c1) {
+		modified = true
+		log.Info("Conditional changed by UI", "was", c0, "is", c1)
+	}
 	return modified
 }
 
@@ -546,6 +570,12 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 		err    error
 		result SignTxResponse
 	)
+	if args.Type != nil && *args.Type == types.DepositTxType {
+		return nil, apitypes.ErrDepositTx
+	}
+	if err := args.ValidateConditional(); err != nil {
+		return nil, err
+	}
 	msgs, err := api.validator.ValidateTransaction(methodSelector, &args)
 	if err != nil {
 		return nil, err
@@ -570,6 +600,14 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 		Meta:        MetadataFromContext(ctx),
 		Callinfo:    msgs.Messages,
 	}
+	if api.simulator != nil {
+		sim, err := api.simulator.SimulateTransaction(ctx, &args)
+		if err != nil {
+			log.Warn("Transaction simulation failed", "err", err)
+			sim = &apitypes.SimulationResult{Error: err.Error()}
+		}
+		req.Simulation = sim
+	}
 	// Process approval
 	result, err = api.UI.ApproveTx(&req)
 	if err != nil {
@@ -580,6 +618,9 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 	}
 	// Log changes made by the UI to the signing-request
 	logDiff(&req, &result)
+	if err := result.Transaction.ValidateConditional(); err != nil {
+		return nil, err
+	}
 	var (
 		acc    accounts.Account
 		wallet accounts.Wallet
@@ -611,7 +652,7 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 	if err != nil {
 		return nil, err
 	}
-	response := ethapi.SignTransactionResult{Raw: data, Tx: signedTx}
+	response := ethapi.SignTransactionResult{Raw: data, Tx: signedTx, Conditional: result.Transaction.Conditional}
 
 	// Finally, send the signed tx to the UI
 	api.UI.OnApprovedTx(response)
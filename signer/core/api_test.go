@@ -19,6 +19,7 @@ package core_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -320,3 +321,44 @@ func TestSignTx(t *testing.T) {
 		t.Error("Expected tx to be modified by UI")
 	}
 }
+
+// Tests that conditionals are validated and returned along with the signed
+// transaction, and that deposit transactions are refused.
+func TestSignConditionalTx(t *testing.T) {
+	t.Parallel()
+
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	// Deposits are refused before reaching the UI
+	deposit := mkTestTx(a)
+	depositType := hexutil.Uint64(types.DepositTxType)
+	deposit.Type = &depositType
+	if _, err := api.SignTransaction(context.Background(), deposit, nil); !errors.Is(err, apitypes.ErrDepositTx) {
+		t.Errorf("Expected ErrDepositTx, got %v", err)
+	}
+	// Invalid conditionals are refused
+	invalid := mkTestTx(a)
+	invalid.Conditional = &types.TransactionConditional{BlockNumberMin: big.NewInt(2), BlockNumberMax: big.NewInt(1)}
+	if _, err := api.SignTransaction(context.Background(), invalid, nil); err == nil {
+		t.Error("Expected invalid conditional to be refused")
+	}
+	// Valid conditionals are returned with the signed transaction
+	tx := mkTestTx(a)
+	tx.Conditional = &types.TransactionConditional{BlockNumberMin: big.NewInt(1), BlockNumberMax: big.NewInt(2)}
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	res, err := api.SignTransaction(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Conditional == nil || res.Conditional.BlockNumberMax.Int64() != 2 {
+		t.Errorf("Expected conditional in result, got %v", res.Conditional)
+	}
+}
@@ -37,11 +37,17 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 )
 
 var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\d*\])*$`)
 
+// ErrDepositTx is returned when asked to sign an OP-stack deposit transaction.
+// Deposits are derived from L1 by the rollup node and carry no signature, so a
+// signed deposit would be meaningless.
+var ErrDepositTx = errors.New("deposit transactions (type 0x7e) can not be signed")
+
 type ValidationInfo struct {
 	Typ     string `json:"type"`
 	Message string `json:"message"`
@@ -92,6 +98,7 @@ type SendTxArgs struct {
 	MaxPriorityFeePerGas *hexutil.Big             `json:"maxPriorityFeePerGas"`
 	Value                hexutil.Big              `json:"value"`
 	Nonce                hexutil.Uint64           `json:"nonce"`
+	Type                 *hexutil.Uint64          `json:"type,omitempty"` // Only checked to refuse deposits, the signed type is inferred from the fields
 
 	// We accept "data" and "input" for backwards-compatibility reasons.
 	// "input" is the newer name and should be preferred by clients.
@@ -111,6 +118,9 @@ type SendTxArgs struct {
 	Blobs       []kzg4844.Blob       `json:"blobs,omitempty"`
 	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
 	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
+
+	// For transactions submitted through eth_sendRawTransactionConditional
+	Conditional *types.TransactionConditional `json:"conditional,omitempty"`
 }
 
 func (args SendTxArgs) String() string {
@@ -121,6 +131,28 @@ func (args SendTxArgs) String() string {
 	return err.Error()
 }
 
+// TokenTransfer is a token or ether transfer observed while simulating a
+// transaction.
+type TokenTransfer struct {
+	Token    common.Address `json:"token"`    // Token contract, the zero address for ether
+	Standard string         `json:"standard"` // Token standard: "ETH", "ERC20" or "ERC721"
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Value    *hexutil.Big   `json:"value"` // Amount transferred, or the token id for ERC721
+}
+
+// SimulationResult summarizes the outcome of simulating a transaction before it
+// is submitted for approval.
+type SimulationResult struct {
+	Reverted       bool                            `json:"reverted"`
+	RevertReason   string                          `json:"revertReason,omitempty"`
+	GasUsed        hexutil.Uint64                  `json:"gasUsed"`
+	BalanceChanges map[common.Address]*hexutil.Big `json:"balanceChanges"` // Net ether moved by value transfers, excluding fees
+	Transfers      []TokenTransfer                 `json:"transfers"`
+	Warnings       []string                        `json:"warnings,omitempty"` // Transfer logs which could not be decoded
+	Error          string                          `json:"error,omitempty"`    // Set if the simulation itself failed
+}
+
 // data retrieves the transaction calldata. Input field is preferred.
 func (args *SendTxArgs) data() []byte {
 	if args.Input != nil {
@@ -134,6 +166,9 @@ func (args *SendTxArgs) data() []byte {
 
 // ToTransaction converts the arguments to a transaction.
 func (args *SendTxArgs) ToTransaction() (*types.Transaction, error) {
+	if args.Type != nil && *args.Type == types.DepositTxType {
+		return nil, ErrDepositTx
+	}
 	// Add the To-field, if specified
 	var to *common.Address
 	if args.To != nil {
@@ -208,10 +243,24 @@ func (args *SendTxArgs) ToTransaction() (*types.Transaction, error) {
 			Data:     args.data(),
 		}
 	}
-
 	return types.NewTx(data), nil
 }
 
+// ValidateConditional checks the sanity and cost of the conditional attached to
+// the transaction, if any.
+func (args *SendTxArgs) ValidateConditional() error {
+	if args.Conditional == nil {
+		return nil
+	}
+	if err := args.Conditional.Validate(); err != nil {
+		return fmt.Errorf("invalid conditional: %w", err)
+	}
+	if cost := args.Conditional.Cost(); cost > params.TransactionConditionalMaxCost {
+		return fmt.Errorf("conditional cost, %d, exceeded max: %d", cost, params.TransactionConditionalMaxCost)
+	}
+	return nil
+}
+
 // validateTxSidecar validates blob data, if present
 func (args *SendTxArgs) validateTxSidecar() error {
 	// No blobs, we're done.
@@ -0,0 +1,88 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package fourbyte
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// transferABIs contains the Transfer events of the supported token standards,
+// keyed by the number of topics they are emitted with. Both events share the
+// same signature, only the value (or token id) being indexed for ERC-721.
+var transferABIs = map[int]struct {
+	standard string
+	abi      abi.ABI
+}{
+	3: {"ERC20", mustParseABI(`[{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}]`)},
+	4: {"ERC721", mustParseABI(`[{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":true}]}]`)},
+}
+
+// mustParseABI parses a hard coded ABI JSON spec, panicking on failure.
+func mustParseABI(spec string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(spec))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// DecodeTransfer decodes a log emitted by a token contract as an ERC-20 or
+// ERC-721 Transfer event. Nil is returned if the log is not a Transfer event of
+// either standard, or an error if it claims to be one but cannot be decoded.
+func DecodeTransfer(token common.Address, topics []common.Hash, data []byte) (*apitypes.TokenTransfer, error) {
+	spec, ok := transferABIs[len(topics)]
+	if !ok {
+		return nil, nil
+	}
+	event := spec.abi.Events["Transfer"]
+	if topics[0] != event.ID {
+		return nil, nil
+	}
+	fields := make(map[string]interface{})
+	if err := spec.abi.UnpackIntoMap(fields, event.Name, data); err != nil {
+		return nil, fmt.Errorf("malformed %s transfer: %v", spec.standard, err)
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(fields, indexed, topics[1:]); err != nil {
+		return nil, fmt.Errorf("malformed %s transfer: %v", spec.standard, err)
+	}
+	return &apitypes.TokenTransfer{
+		Token:    token,
+		Standard: spec.standard,
+		From:     fields["from"].(common.Address),
+		To:       fields["to"].(common.Address),
+		Value:    (*hexutil.Big)(fields["value"].(*big.Int)),
+	}, nil
+}
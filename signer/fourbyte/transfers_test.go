@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package fourbyte
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that ERC-20 and ERC-721 Transfer events are decoded by their layout, and
+// that other logs are skipped.
+func TestDecodeTransfer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		token = common.Address{0xaa}
+		topic = common.HexToHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+		from  = common.BytesToHash([]byte{1})
+		to    = common.BytesToHash([]byte{2})
+		value = common.BytesToHash([]byte{7})
+	)
+	tests := []struct {
+		topics   []common.Hash
+		data     []byte
+		standard string
+		fail     bool
+	}{
+		{topics: []common.Hash{topic, from, to}, data: value.Bytes(), standard: "ERC20"},
+		{topics: []common.Hash{topic, from, to, value}, standard: "ERC721"},
+		{topics: []common.Hash{topic, from, to}, fail: true},                  // missing value
+		{topics: []common.Hash{topic, from, to}, data: []byte{7}, fail: true}, // short value
+		{topics: []common.Hash{topic, from}},                                  // unknown layout
+		{topics: []common.Hash{{0x01}, from, to}, data: value.Bytes()},        // other event
+		{},
+	}
+	for i, tt := range tests {
+		transfer, err := DecodeTransfer(token, tt.topics, tt.data)
+		switch {
+		case tt.fail:
+			if err == nil {
+				t.Errorf("test %d: decoded malformed transfer: %+v", i, transfer)
+			}
+		case err != nil:
+			t.Errorf("test %d: failed to decode transfer: %v", i, err)
+		case tt.standard == "":
+			if transfer != nil {
+				t.Errorf("test %d: decoded unrelated log: %+v", i, transfer)
+			}
+		case transfer == nil:
+			t.Errorf("test %d: transfer not decoded", i)
+		default:
+			if transfer.Token != token || transfer.Standard != tt.standard || transfer.From != common.BytesToAddress(from.Bytes()) || transfer.To != common.BytesToAddress(to.Bytes()) || transfer.Value.ToInt().Int64() != 7 {
+				t.Errorf("test %d: transfer mismatch: %+v", i, transfer)
+			}
+		}
+	}
+}
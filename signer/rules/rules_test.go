@@ -196,6 +196,68 @@ func TestSignTxRequest(t *testing.T) {
 	}
 }
 
+// Tests that rules can make decisions based on the simulated effects of a
+// transaction and on the structure of typed data.
+func TestSimulationAndTypedDataRequest(t *testing.T) {
+	t.Parallel()
+	js := `
+	function ApproveTx(r){
+		if(r.simulation.reverted){ return "Reject" }
+		var transfers = r.simulation.transfers || [];
+		for(var i = 0; i < transfers.length; i++){
+			var tx = transfers[i];
+			if(tx.standard == "ERC20" && new BigNumber(tx.value.slice(2), 16).gt(1000)){ return "Reject" }
+		}
+		return "Approve"
+	}
+	function ApproveSignData(r){
+		if(r.typed_data && r.typed_data.primaryType == "Permit"){ return "Reject" }
+		return "Approve"
+	}`
+
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	from, _ := mixAddr("0000000000000000000000000000000000001337")
+	for i, tt := range []struct {
+		simulation *apitypes.SimulationResult
+		approved   bool
+	}{
+		{&apitypes.SimulationResult{}, true},
+		{&apitypes.SimulationResult{Reverted: true}, false},
+		{&apitypes.SimulationResult{Transfers: []apitypes.TokenTransfer{{Standard: "ERC20", Value: (*hexutil.Big)(big.NewInt(10))}}}, true},
+		{&apitypes.SimulationResult{Transfers: []apitypes.TokenTransfer{{Standard: "ERC20", Value: (*hexutil.Big)(big.NewInt(5000))}}}, false},
+	} {
+		resp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{From: *from},
+			Simulation:  tt.simulation,
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+	for i, tt := range []struct {
+		typedData *apitypes.TypedData
+		approved  bool
+	}{
+		{nil, true},
+		{&apitypes.TypedData{PrimaryType: "Mail"}, true},
+		{&apitypes.TypedData{PrimaryType: "Permit"}, false},
+	} {
+		resp, err := r.ApproveSignData(&core.SignDataRequest{TypedData: tt.typedData})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+}
+
 type dummyUI struct {
 	calls []string
 }
@@ -0,0 +1,192 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulate implements transaction simulation for the signer, running
+// transactions through eth_simulateV1 on a node to report the ether and token
+// transfers they would make. Token transfers are decoded by signer/fourbyte.
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/signer/fourbyte"
+)
+
+var (
+	// transferTopic is the topic of the Transfer event shared by ERC-20 and
+	// ERC-721 tokens, and of the ether transfer logs traced by eth_simulateV1.
+	transferTopic = common.HexToHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+	// etherAddress is the pseudo-address ether transfer logs are emitted from
+	// (ERC-7528).
+	etherAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+)
+
+// Backend is the connection to the node transactions are simulated on. It is
+// satisfied by *rpc.Client.
+type Backend interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// Simulator runs transactions through eth_simulateV1 on top of the latest state
+// of a node. It implements core.Simulator.
+type Simulator struct {
+	backend Backend
+}
+
+// New creates a simulator running transactions on the given node.
+func New(backend Backend) *Simulator {
+	return &Simulator{backend: backend}
+}
+
+// simLog is the subset of a simulated log needed to decode transfers.
+type simLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// simCall is the result of a simulated transaction.
+type simCall struct {
+	Logs    []simLog       `json:"logs"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Status  hexutil.Uint64 `json:"status"`
+	Error   *struct {
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// simBlock is a simulated block, of which only the calls are of interest.
+type simBlock struct {
+	Calls []simCall `json:"calls"`
+}
+
+// SimulateTransaction executes the transaction on top of the latest block and
+// reports whether it reverted, along with the ether and token transfers made.
+// Fee and nonce checks are skipped, as the transaction has not been finalized
+// by the time it is submitted for approval.
+func (s *Simulator) SimulateTransaction(ctx context.Context, tx *apitypes.SendTxArgs) (*apitypes.SimulationResult, error) {
+	call := map[string]interface{}{
+		"from":  tx.From.Address(),
+		"value": &tx.Value,
+	}
+	if tx.To != nil {
+		call["to"] = tx.To.Address()
+	}
+	if tx.Gas != 0 {
+		call["gas"] = tx.Gas
+	}
+	if tx.Input != nil {
+		call["input"] = tx.Input
+	} else if tx.Data != nil {
+		call["input"] = tx.Data
+	}
+	if tx.AccessList != nil {
+		call["accessList"] = tx.AccessList
+	}
+	opts := map[string]interface{}{
+		"blockStateCalls": []interface{}{
+			map[string]interface{}{"calls": []interface{}{call}},
+		},
+		"traceTransfers": true,
+		"validation":     false,
+	}
+	var blocks []simBlock
+	if err := s.backend.CallContext(ctx, &blocks, "eth_simulateV1", opts, "latest"); err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != 1 {
+		return nil, errors.New("unexpected simulation result")
+	}
+	return summarize(&blocks[0].Calls[0]), nil
+}
+
+// summarize converts the outcome of a simulated transaction into the result
+// reported to the UI and rules. Transfer logs which cannot be decoded are
+// reported as warnings.
+func summarize(call *simCall) *apitypes.SimulationResult {
+	result := &apitypes.SimulationResult{
+		Reverted:       call.Status == 0,
+		GasUsed:        call.GasUsed,
+		BalanceChanges: make(map[common.Address]*hexutil.Big),
+		Transfers:      []apitypes.TokenTransfer{},
+	}
+	if call.Error != nil {
+		result.RevertReason = call.Error.Message
+		if data, err := hexutil.Decode(call.Error.Data); err == nil {
+			if reason, err := abi.UnpackRevert(data); err == nil {
+				result.RevertReason = reason
+			}
+		}
+	}
+	for i, log := range call.Logs {
+		// A malformed log must not hide the transfers of the others, skip it
+		// and leave it to the rules to decide how to treat it
+		transfer, err := decodeTransfer(&log)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("log %d of %v: %v", i, log.Address, err))
+			continue
+		}
+		if transfer == nil {
+			continue
+		}
+		result.Transfers = append(result.Transfers, *transfer)
+
+		if transfer.Standard == "ETH" {
+			value := transfer.Value.ToInt()
+			addBalance(result.BalanceChanges, transfer.From, new(big.Int).Neg(value))
+			addBalance(result.BalanceChanges, transfer.To, value)
+		}
+	}
+	return result
+}
+
+// decodeTransfer decodes an ERC-20 or ERC-721 Transfer event, or an ether
+// transfer traced by the simulation. Nil is returned for any other log.
+func decodeTransfer(log *simLog) (*apitypes.TokenTransfer, error) {
+	transfer, err := fourbyte.DecodeTransfer(log.Address, log.Topics, log.Data)
+	if err != nil {
+		return nil, err
+	}
+	if log.Address != etherAddress {
+		return transfer, nil
+	}
+	// Ether transfers are traced with the ERC-20 Transfer event layout
+	if transfer == nil || transfer.Standard != "ERC20" {
+		if len(log.Topics) > 0 && log.Topics[0] == transferTopic {
+			return nil, fmt.Errorf("malformed ether transfer log: %d topics, %d bytes of data", len(log.Topics), len(log.Data))
+		}
+		return nil, nil
+	}
+	transfer.Token, transfer.Standard = common.Address{}, "ETH"
+	return transfer, nil
+}
+
+// addBalance accumulates a balance change of an account.
+func addBalance(changes map[common.Address]*hexutil.Big, addr common.Address, delta *big.Int) {
+	if prev, ok := changes[addr]; ok {
+		delta = new(big.Int).Add(prev.ToInt(), delta)
+	}
+	changes[addr] = (*hexutil.Big)(delta)
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulate
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Tests that simulating a transaction against a node reports both the ether
+// transferred and the token transfers emitted by the called contract.
+func TestSimulateTransaction(t *testing.T) {
+	var (
+		sender = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		token  = common.HexToAddress("0x2000000000000000000000000000000000000000")
+	)
+	// The token emits Transfer(0x01, 0x02, 42) when called
+	code := common.FromHex("602a60005260026001")
+	code = append(code, 0x7f)
+	code = append(code, transferTopic.Bytes()...)
+	code = append(code, common.FromHex("60206000a300")...)
+
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	config := ethconfig.Defaults
+	config.Genesis = &core.Genesis{
+		Config:   params.AllDevChainProtocolChanges,
+		GasLimit: ethconfig.Defaults.Miner.GasCeil,
+		Alloc: types.GenesisAlloc{
+			sender: {Balance: big.NewInt(params.Ether)},
+			token:  {Code: code},
+		},
+	}
+	if _, err := eth.New(stack, &config); err != nil {
+		t.Fatalf("failed to register ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	sim := New(stack.Attach())
+
+	to := common.NewMixedcaseAddress(token)
+	result, err := sim.SimulateTransaction(context.Background(), &apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(sender),
+		To:    &to,
+		Value: hexutil.Big(*big.NewInt(1000)),
+	})
+	if err != nil {
+		t.Fatalf("failed to simulate transaction: %v", err)
+	}
+	if result.Reverted {
+		t.Fatalf("transaction reverted: %s", result.RevertReason)
+	}
+	want := []apitypes.TokenTransfer{
+		{Standard: "ETH", From: sender, To: token, Value: (*hexutil.Big)(big.NewInt(1000))},
+		{Token: token, Standard: "ERC20", From: common.BytesToAddress([]byte{1}), To: common.BytesToAddress([]byte{2}), Value: (*hexutil.Big)(big.NewInt(42))},
+	}
+	if len(result.Transfers) != len(want) {
+		t.Fatalf("transfer count mismatch: have %d, want %d", len(result.Transfers), len(want))
+	}
+	for i, have := range result.Transfers {
+		if have.Token != want[i].Token || have.Standard != want[i].Standard || have.From != want[i].From || have.To != want[i].To || have.Value.ToInt().Cmp(want[i].Value.ToInt()) != 0 {
+			t.Errorf("transfer %d mismatch: have %+v, want %+v", i, have, want[i])
+		}
+	}
+	if change := result.BalanceChanges[sender].ToInt(); change.Int64() != -1000 {
+		t.Errorf("sender balance change mismatch: have %v, want -1000", change)
+	}
+	if change := result.BalanceChanges[token].ToInt(); change.Int64() != 1000 {
+		t.Errorf("token balance change mismatch: have %v, want 1000", change)
+	}
+}
+
+// Tests that ether transfers traced by the simulation are told apart from token
+// transfers, which are decoded by the 4byte package.
+func TestDecodeTransfer(t *testing.T) {
+	var (
+		from  = common.BytesToHash([]byte{1})
+		to    = common.BytesToHash([]byte{2})
+		value = common.BytesToHash([]byte{7})
+	)
+	ether, err := decodeTransfer(&simLog{Address: etherAddress, Topics: []common.Hash{transferTopic, from, to}, Data: value.Bytes()})
+	if err != nil || ether == nil {
+		t.Fatalf("failed to decode ether transfer: %v", err)
+	}
+	if ether.Standard != "ETH" || ether.Token != (common.Address{}) || ether.Value.ToInt().Int64() != 7 {
+		t.Errorf("ether transfer mismatch: %+v", ether)
+	}
+	token, err := decodeTransfer(&simLog{Address: common.Address{0xaa}, Topics: []common.Hash{transferTopic, from, to}, Data: value.Bytes()})
+	if err != nil || token == nil {
+		t.Fatalf("failed to decode ERC20 transfer: %v", err)
+	}
+	if token.Standard != "ERC20" || token.Token != (common.Address{0xaa}) {
+		t.Errorf("ERC20 transfer mismatch: %+v", token)
+	}
+	if other, err := decodeTransfer(&simLog{Topics: []common.Hash{{0x01}}}); other != nil || err != nil {
+		t.Errorf("decoded unrelated log: %+v, %v", other, err)
+	}
+	if _, err := decodeTransfer(&simLog{Address: etherAddress, Topics: []common.Hash{transferTopic}}); err == nil {
+		t.Errorf("decoded malformed ether transfer")
+	}
+	if _, err := decodeTransfer(&simLog{Address: etherAddress, Topics: []common.Hash{transferTopic, from, to, value}}); err == nil {
+		t.Errorf("decoded ether transfer with ERC721 layout")
+	}
+}
+
+// Tests that a malformed Transfer log is skipped with a warning, without hiding
+// the transfers reported by the other logs of the transaction.
+func TestSummarizeMalformedTransfer(t *testing.T) {
+	var (
+		token = common.Address{0xaa}
+		from  = common.BytesToHash([]byte{1})
+		to    = common.BytesToHash([]byte{2})
+		value = common.BytesToHash([]byte{7})
+	)
+	result := summarize(&simCall{
+		Status: 1,
+		Logs: []simLog{
+			{Address: token, Topics: []common.Hash{transferTopic, from, to}},                             // missing value
+			{Address: token, Topics: []common.Hash{transferTopic, from, to}, Data: value.Bytes()},        // valid
+			{Address: etherAddress, Topics: []common.Hash{transferTopic, from, to, value}},               // ERC721 layout
+			{Address: etherAddress, Topics: []common.Hash{transferTopic, from, to}, Data: value.Bytes()}, // valid
+		},
+	})
+	if len(result.Transfers) != 2 {
+		t.Fatalf("transfer count mismatch: have %d, want 2", len(result.Transfers))
+	}
+	if have := result.Transfers[0]; have.Standard != "ERC20" || have.Token != token || have.Value.ToInt().Int64() != 7 {
+		t.Errorf("token transfer mismatch: %+v", have)
+	}
+	if have := result.Transfers[1]; have.Standard != "ETH" || have.Value.ToInt().Int64() != 7 {
+		t.Errorf("ether transfer mismatch: %+v", have)
+	}
+	if len(result.Warnings) != 2 {
+		t.Fatalf("warning count mismatch: have %v, want 2", result.Warnings)
+	}
+	if !strings.HasPrefix(result.Warnings[0], "log 0 ") || !strings.HasPrefix(result.Warnings[1], "log 2 ") {
+		t.Errorf("warnings mismatch: %v", result.Warnings)
+	}
+}